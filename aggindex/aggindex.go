@@ -0,0 +1,309 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package aggindex maintains numeric aggregates per bucket over an object store's records, so summaries like daily totals are read without scanning every record.
+//
+// A bucket function maps each record to a bucket key and a number. The aggregate store holds a summary for every bucket, keyed by [bucket], and a count of each distinct number in the bucket, keyed by [bucket, number], for finding its minimum and maximum.
+// Write records through Index.Put and Index.Delete, so the aggregates change in the same transaction as the records.
+package aggindex
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+var jsArray safejs.Value
+
+func init() {
+	var err error
+	jsArray, err = safejs.Global().Get("Array")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// StoreName returns the name of the object store holding the aggregates of storeName's records.
+func StoreName(storeName string) string {
+	return storeName + ".aggindex"
+}
+
+// CreateStore creates the object store holding the aggregates of storeName's records. Call it during an upgrade, alongside creating storeName.
+func CreateStore(db *idb.Database, storeName string) (*idb.ObjectStore, error) {
+	return db.CreateObjectStore(StoreName(storeName), idb.ObjectStoreOptions{})
+}
+
+// BucketFunc returns the bucket of the record at key and the number it adds to that bucket. Returns ok false if the record isn't aggregated.
+type BucketFunc func(key, value safejs.Value) (bucket safejs.Value, n float64, ok bool, err error)
+
+// Aggregate summarizes the numbers in a bucket. Min and Max are 0 if Count is 0.
+type Aggregate struct {
+	Count    uint
+	Sum      float64
+	Min, Max float64
+}
+
+// Mean returns the average of the numbers in the bucket, or 0 if it's empty.
+func (a Aggregate) Mean() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.Count)
+}
+
+// Index maintains the aggregates of an object store's records.
+type Index struct {
+	db        *idb.Database
+	storeName string
+	bucket    BucketFunc
+}
+
+// New returns the aggregate index of the object store named storeName, bucketing records with bucket.
+// The store must use out-of-line keys, and its aggregate store must exist. See CreateStore.
+func New(db *idb.Database, storeName string, bucket BucketFunc) *Index {
+	return &Index{db: db, storeName: storeName, bucket: bucket}
+}
+
+// Put stores value at key in the object store and updates the aggregates. txn must be read-write and include the object store and its aggregate store.
+func (x *Index) Put(ctx context.Context, txn *idb.Transaction, key, value safejs.Value) error {
+	store, aggregates, err := x.objectStores(txn)
+	if err != nil {
+		return err
+	}
+	if err := x.remove(ctx, store, aggregates, key); err != nil {
+		return err
+	}
+	req, err := store.PutKey(key, value)
+	if err != nil {
+		return err
+	}
+	if _, err := req.Await(ctx); err != nil {
+		return err
+	}
+	return x.apply(ctx, aggregates, key, value, 1)
+}
+
+// Delete deletes the record at key from the object store and updates the aggregates. txn must be read-write and include the object store and its aggregate store.
+func (x *Index) Delete(ctx context.Context, txn *idb.Transaction, key safejs.Value) error {
+	store, aggregates, err := x.objectStores(txn)
+	if err != nil {
+		return err
+	}
+	if err := x.remove(ctx, store, aggregates, key); err != nil {
+		return err
+	}
+	req, err := store.Delete(key)
+	if err != nil {
+		return err
+	}
+	return req.Await(ctx)
+}
+
+func (x *Index) objectStores(txn *idb.Transaction) (store, aggregates *idb.ObjectStore, err error) {
+	store, err = txn.ObjectStore(x.storeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	aggregates, err = txn.ObjectStore(StoreName(x.storeName))
+	return store, aggregates, err
+}
+
+// remove takes the record currently stored at key out of the aggregates.
+func (x *Index) remove(ctx context.Context, store, aggregates *idb.ObjectStore, key safejs.Value) error {
+	req, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+	existing, err := req.Await(ctx)
+	if err != nil || existing.IsUndefined() {
+		return err
+	}
+	return x.apply(ctx, aggregates, key, existing, -1)
+}
+
+// apply adds the record's number to its bucket if delta is 1, or removes it if delta is -1.
+func (x *Index) apply(ctx context.Context, aggregates *idb.ObjectStore, key, value safejs.Value, delta int) error {
+	bucket, n, ok, err := x.bucket(key, value)
+	if err != nil || !ok {
+		return err
+	}
+	if math.IsNaN(n) {
+		return errors.New("aggregated number must not be NaN")
+	}
+	summaryKey, err := jsArray.Call("of", bucket)
+	if err != nil {
+		return err
+	}
+	err = updateRecord(ctx, aggregates, summaryKey, func(existing safejs.Value) (interface{}, error) {
+		count, sum := 0, 0.0
+		if !existing.IsUndefined() {
+			properties, err := getProperties(existing, "count", "sum")
+			if err != nil {
+				return nil, err
+			}
+			count, sum = int(properties[0]), properties[1]
+		}
+		count += delta
+		if count <= 0 {
+			return nil, nil
+		}
+		return map[string]interface{}{"count": count, "sum": sum + float64(delta)*n}, nil
+	})
+	if err != nil {
+		return err
+	}
+	numberKey, err := jsArray.Call("of", bucket, n)
+	if err != nil {
+		return err
+	}
+	return updateRecord(ctx, aggregates, numberKey, func(existing safejs.Value) (interface{}, error) {
+		count := 0
+		if !existing.IsUndefined() {
+			var err error
+			count, err = existing.Int()
+			if err != nil {
+				return nil, err
+			}
+		}
+		count += delta
+		if count <= 0 {
+			return nil, nil
+		}
+		return count, nil
+	})
+}
+
+// updateRecord replaces the record at key with the result of update, or deletes it if the result is nil.
+func updateRecord(ctx context.Context, store *idb.ObjectStore, key safejs.Value, update func(existing safejs.Value) (interface{}, error)) error {
+	req, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+	existing, err := req.Await(ctx)
+	if err != nil {
+		return err
+	}
+	updated, err := update(existing)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		deleteReq, err := store.Delete(key)
+		if err != nil {
+			return err
+		}
+		return deleteReq.Await(ctx)
+	}
+	value, err := idb.ValueOf(updated)
+	if err != nil {
+		return err
+	}
+	putReq, err := store.PutKey(key, value)
+	if err != nil {
+		return err
+	}
+	_, err = putReq.Await(ctx)
+	return err
+}
+
+func getProperties(value safejs.Value, names ...string) ([]float64, error) {
+	properties := make([]float64, 0, len(names))
+	for _, name := range names {
+		property, err := value.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		n, err := property.Float()
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, n)
+	}
+	return properties, nil
+}
+
+// ReadAggregate returns the aggregate of the records in bucket. An empty bucket has a zero Aggregate.
+func (x *Index) ReadAggregate(ctx context.Context, bucket safejs.Value) (Aggregate, error) {
+	var aggregate Aggregate
+	err := idb.RetryTxn(ctx, x.db, idb.TransactionReadOnly, func(txn *idb.Transaction) error {
+		aggregates, err := txn.ObjectStore(StoreName(x.storeName))
+		if err != nil {
+			return err
+		}
+		aggregate, err = readAggregate(ctx, aggregates, bucket)
+		return err
+	}, StoreName(x.storeName))
+	return aggregate, err
+}
+
+func readAggregate(ctx context.Context, aggregates *idb.ObjectStore, bucket safejs.Value) (Aggregate, error) {
+	summaryKey, err := jsArray.Call("of", bucket)
+	if err != nil {
+		return Aggregate{}, err
+	}
+	summaryReq, err := aggregates.Get(summaryKey)
+	if err != nil {
+		return Aggregate{}, err
+	}
+	lower, err := jsArray.Call("of", bucket, math.Inf(-1))
+	if err != nil {
+		return Aggregate{}, err
+	}
+	upper, err := jsArray.Call("of", bucket, math.Inf(1))
+	if err != nil {
+		return Aggregate{}, err
+	}
+	numbers, err := idb.NewKeyRangeBound(lower, upper, false, false)
+	if err != nil {
+		return Aggregate{}, err
+	}
+	minReq, err := aggregates.OpenKeyCursorRange(numbers, idb.CursorNext)
+	if err != nil {
+		return Aggregate{}, err
+	}
+	maxReq, err := aggregates.OpenKeyCursorRange(numbers, idb.CursorPrevious)
+	if err != nil {
+		return Aggregate{}, err
+	}
+
+	summary, err := summaryReq.Await(ctx)
+	if err != nil || summary.IsUndefined() {
+		return Aggregate{}, err
+	}
+	properties, err := getProperties(summary, "count", "sum")
+	if err != nil {
+		return Aggregate{}, err
+	}
+	aggregate := Aggregate{Count: uint(properties[0]), Sum: properties[1]}
+	for _, bound := range []struct {
+		req *idb.CursorRequest
+		n   *float64
+	}{
+		{minReq, &aggregate.Min},
+		{maxReq, &aggregate.Max},
+	} {
+		cursor, err := bound.req.Await(ctx)
+		if err != nil {
+			return Aggregate{}, err
+		}
+		if cursor == nil {
+			return Aggregate{}, errors.New("aggregate numbers are missing for bucket")
+		}
+		key, err := cursor.Key()
+		if err != nil {
+			return Aggregate{}, err
+		}
+		n, err := key.Index(1)
+		if err != nil {
+			return Aggregate{}, err
+		}
+		*bound.n, err = n.Float()
+		if err != nil {
+			return Aggregate{}, err
+		}
+	}
+	return aggregate, nil
+}
@@ -0,0 +1,123 @@
+//go:build js && wasm
+// +build js,wasm
+
+package aggindex
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+func TestIndexAggregate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	name := fmt.Sprintf("aggindex-test-%s-%d", t.Name(), time.Now().UnixNano())
+	dbReq, err := idb.Global().Open(ctx, name, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		if _, err := db.CreateObjectStore("sales", idb.ObjectStoreOptions{}); err != nil {
+			return err
+		}
+		_, err := CreateStore(db, "sales")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := dbReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+
+	index := New(db, "sales", func(key, value safejs.Value) (safejs.Value, float64, bool, error) {
+		day, err := value.Get("day")
+		if err != nil {
+			return safejs.Undefined(), 0, false, err
+		}
+		amount, err := value.Get("amount")
+		if err != nil {
+			return safejs.Undefined(), 0, false, err
+		}
+		n, err := amount.Float()
+		return day, n, true, err
+	})
+	write := func(fn func(txn *idb.Transaction) error) {
+		t.Helper()
+		txn, err := db.Transaction(idb.TransactionReadWrite, "sales", StoreName("sales"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := fn(txn); err != nil {
+			t.Fatal(err)
+		}
+		if err := txn.Await(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jsValue := func(value interface{}) safejs.Value {
+		return safejs.Safe(js.ValueOf(value))
+	}
+	put := func(txn *idb.Transaction, id int, day string, amount float64) error {
+		return index.Put(ctx, txn, jsValue(id), jsValue(map[string]interface{}{"day": day, "amount": amount}))
+	}
+	read := func(day string) Aggregate {
+		t.Helper()
+		aggregate, err := index.ReadAggregate(ctx, jsValue(day))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return aggregate
+	}
+
+	write(func(txn *idb.Transaction) error {
+		for i, amount := range []float64{5, 2, 9, 2} {
+			if err := put(txn, i, "2024-01-01", amount); err != nil {
+				return err
+			}
+		}
+		return put(txn, 10, "2024-01-02", 7)
+	})
+	if aggregate, expect := read("2024-01-01"), (Aggregate{Count: 4, Sum: 18, Min: 2, Max: 9}); aggregate != expect {
+		t.Errorf("expected %+v, got %+v", expect, aggregate)
+	}
+	if mean := read("2024-01-01").Mean(); mean != 4.5 {
+		t.Errorf("expected mean 4.5, got %v", mean)
+	}
+
+	// overwriting moves the record between buckets, and deleting the maximum reveals the next one
+	write(func(txn *idb.Transaction) error {
+		if err := put(txn, 0, "2024-01-02", 1); err != nil {
+			return err
+		}
+		return index.Delete(ctx, txn, jsValue(2))
+	})
+	if aggregate, expect := read("2024-01-01"), (Aggregate{Count: 2, Sum: 4, Min: 2, Max: 2}); aggregate != expect {
+		t.Errorf("expected %+v, got %+v", expect, aggregate)
+	}
+	if aggregate, expect := read("2024-01-02"), (Aggregate{Count: 2, Sum: 8, Min: 1, Max: 7}); aggregate != expect {
+		t.Errorf("expected %+v, got %+v", expect, aggregate)
+	}
+
+	write(func(txn *idb.Transaction) error {
+		for _, id := range []int{1, 3} {
+			if err := index.Delete(ctx, txn, jsValue(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if aggregate := read("2024-01-01"); aggregate != (Aggregate{}) {
+		t.Errorf("expected an empty aggregate, got %+v", aggregate)
+	}
+}
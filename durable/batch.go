@@ -0,0 +1,130 @@
+//go:build js && wasm
+// +build js,wasm
+
+package durable
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+// DurableWriteBatch records writes in Go memory and applies them all in a single readwrite transaction on Flush.
+//
+// Flush issues every write without yielding, then only awaits the transaction's complete event,
+// so the transaction can't commit early like one kept open across Go calls.
+// See: ../../README.md#Transactions-Expiring
+type DurableWriteBatch struct {
+	db  *idb.Database
+	ops []batchOp
+}
+
+// batchOp is a write recorded in a DurableWriteBatch.
+type batchOp struct {
+	method    string
+	storeName string
+	key       safejs.Value // undefined for writes to stores with in-line keys
+	value     safejs.Value // undefined for deletes
+}
+
+// NewDurableWriteBatch creates an empty DurableWriteBatch for db.
+func NewDurableWriteBatch(db *idb.Database) *DurableWriteBatch {
+	return &DurableWriteBatch{db: db}
+}
+
+// Len returns the number of writes waiting to be flushed.
+func (b *DurableWriteBatch) Len() int {
+	return len(b.ops)
+}
+
+// Put records a put of value into the store named storeName, which uses in-line keys.
+func (b *DurableWriteBatch) Put(storeName string, value safejs.Value) {
+	b.ops = append(b.ops, batchOp{method: "put", storeName: storeName, key: safejs.Undefined(), value: value})
+}
+
+// PutKey records a put of value at key into the store named storeName.
+func (b *DurableWriteBatch) PutKey(storeName string, key, value safejs.Value) {
+	b.ops = append(b.ops, batchOp{method: "put", storeName: storeName, key: key, value: value})
+}
+
+// Add records an add of value into the store named storeName, which uses in-line keys. Flush fails if a record with the same key exists.
+func (b *DurableWriteBatch) Add(storeName string, value safejs.Value) {
+	b.ops = append(b.ops, batchOp{method: "add", storeName: storeName, key: safejs.Undefined(), value: value})
+}
+
+// AddKey records an add of value at key into the store named storeName. Flush fails if a record exists at key.
+func (b *DurableWriteBatch) AddKey(storeName string, key, value safejs.Value) {
+	b.ops = append(b.ops, batchOp{method: "add", storeName: storeName, key: key, value: value})
+}
+
+// Delete records a delete of the record at key from the store named storeName.
+func (b *DurableWriteBatch) Delete(storeName string, key safejs.Value) {
+	b.ops = append(b.ops, batchOp{method: "delete", storeName: storeName, key: key, value: safejs.Undefined()})
+}
+
+// Flush applies the recorded writes in order, in one readwrite transaction over the stores they touch, and waits for it to complete.
+// If any write fails, the transaction is aborted so none are applied, and the batch keeps its writes so Flush may be called again.
+// Otherwise the batch is emptied.
+func (b *DurableWriteBatch) Flush(ctx context.Context) error {
+	if len(b.ops) == 0 {
+		return nil
+	}
+	storeNames := b.storeNames()
+	txn, err := b.db.Transaction(idb.TransactionReadWrite, storeNames[0], storeNames[1:]...)
+	if err != nil {
+		return err
+	}
+	stores := make(map[string]*idb.ObjectStore, len(storeNames))
+	for _, name := range storeNames {
+		stores[name], err = txn.ObjectStore(name)
+		if err != nil {
+			_ = txn.Abort()
+			return err
+		}
+	}
+	for _, op := range b.ops {
+		if err := op.apply(stores[op.storeName]); err != nil {
+			_ = txn.Abort()
+			return err
+		}
+	}
+	if err := txn.Await(ctx); err != nil {
+		return err
+	}
+	b.ops = nil
+	return nil
+}
+
+// storeNames returns the sorted names of the stores written by the batch.
+func (b *DurableWriteBatch) storeNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, op := range b.ops {
+		if !seen[op.storeName] {
+			seen[op.storeName] = true
+			names = append(names, op.storeName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply issues the write on store without waiting for it.
+func (op batchOp) apply(store *idb.ObjectStore) error {
+	var err error
+	switch {
+	case op.method == "delete":
+		_, err = store.Delete(op.key)
+	case op.method == "add" && op.key.IsUndefined():
+		_, err = store.Add(op.value)
+	case op.method == "add":
+		_, err = store.AddKey(op.key, op.value)
+	case op.key.IsUndefined():
+		_, err = store.Put(op.value)
+	default:
+		_, err = store.PutKey(op.key, op.value)
+	}
+	return err
+}
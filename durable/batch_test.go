@@ -0,0 +1,95 @@
+//go:build js && wasm
+// +build js,wasm
+
+package durable
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+func TestDurableWriteBatch(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		if _, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{}); err != nil {
+			return err
+		}
+		_, err := db.CreateObjectStore("inline", idb.ObjectStoreOptions{KeyPath: idb.NewKeyPath("id")})
+		return err
+	})
+	record := func(id int) safejs.Value {
+		return safejs.Safe(js.ValueOf(map[string]interface{}{"id": id}))
+	}
+
+	batch := NewDurableWriteBatch(db)
+	if err := batch.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		batch.PutKey("test_store", safejs.Safe(js.ValueOf(i)), safejs.Safe(js.ValueOf(i)))
+	}
+	batch.Delete("test_store", safejs.Safe(js.ValueOf(1)))
+	batch.Put("inline", record(1))
+	batch.Add("inline", record(2))
+	// yield to the event loop, which doesn't matter until Flush starts a transaction
+	time.Sleep(50 * time.Millisecond)
+	if batch.Len() != 6 {
+		t.Errorf("expected 6 writes, got %d", batch.Len())
+	}
+	if err := batch.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if batch.Len() != 0 {
+		t.Errorf("expected flush to empty the batch, got %d writes", batch.Len())
+	}
+
+	dt, err := NewDurableTransaction(db, idb.TransactionReadOnly, "test_store", "inline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := store.GetAllKeys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || !keys[0].Equal(safejs.Safe(js.ValueOf(0))) || !keys[1].Equal(safejs.Safe(js.ValueOf(2))) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+	inline, err := dt.GetObjectStore("inline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := inline.Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 in-line records, got %d", count)
+	}
+
+	// a failed write applies none of the batch
+	batch.PutKey("test_store", safejs.Safe(js.ValueOf(5)), safejs.Safe(js.ValueOf(5)))
+	batch.Add("inline", record(1))
+	if err := batch.Flush(ctx); !errors.Is(err, idb.ErrConstraint) {
+		t.Errorf("expected a constraint error, got %v", err)
+	}
+	if batch.Len() != 2 {
+		t.Errorf("expected the failed batch to keep its writes, got %d", batch.Len())
+	}
+	count, err = store.CountKey(ctx, safejs.Safe(js.ValueOf(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Error("expected the failed batch not to be applied")
+	}
+}
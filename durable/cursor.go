@@ -0,0 +1,194 @@
+//go:build js && wasm
+// +build js,wasm
+
+package durable
+
+import (
+	"context"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+// IterMutate iterates over the records in keyRange with a cursor, calling fn for each record.
+// fn may call Update or Delete on the cursor to mutate the current record.
+// If keyRange is nil, iterates over all records in the store.
+//
+// If the transaction finishes mid-iteration, a new transaction is created and
+// the cursor is reopened just past the last record fn completed successfully.
+// fn is not called again for records it already processed, so each mutation
+// is applied exactly once as long as fn issues at most one mutation per record.
+// In strict mode, returns an error wrapping ErrPartialCommit instead, if fn already processed records in the finished transaction.
+//
+// Return idb.ErrCursorStopIter from fn to stop iterating early.
+func (d *DurableObjectStore) IterMutate(
+	ctx context.Context,
+	keyRange *idb.KeyRange,
+	direction idb.CursorDirection,
+	fn func(cursor *idb.CursorWithValue) error,
+) error {
+	var lastKey safejs.Value
+	var hasLastKey bool
+	return d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		iterRange := keyRange
+		if hasLastKey {
+			var more bool
+			var err error
+			iterRange, more, err = idb.ResumeKeyRange(keyRange, lastKey, true, direction)
+			if err != nil || !more {
+				return err
+			}
+		}
+
+		req, err := openCursor(store, iterRange, direction)
+		if err != nil {
+			return err
+		}
+		return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+			key, err := cursor.Key()
+			if err != nil {
+				return err
+			}
+			if err := fn(cursor); err != nil {
+				return err
+			}
+			lastKey, hasLastKey = key, true
+			d.dt.markProgress()
+			return nil
+		})
+	})
+}
+
+// DurableCursor iterates over records of a DurableObjectStore, reopening its cursor in a new transaction if the transaction finishes between records.
+// It tracks the key of the current record, and reopens the cursor just past it, so records aren't returned twice.
+//
+// DurableCursor only reads records. Use IterMutate to update or delete them while iterating.
+type DurableCursor struct {
+	store     *DurableObjectStore
+	keyRange  *idb.KeyRange
+	direction idb.CursorDirection
+
+	// txn is the transaction req was opened in
+	txn    *idb.Transaction
+	req    *idb.CursorWithValueRequest
+	cursor *idb.CursorWithValue
+
+	key, value safejs.Value
+	hasKey     bool
+	done       bool
+}
+
+// OpenDurableCursor returns a DurableCursor over the records in keyRange, in direction. If keyRange is nil, iterates over all records in the store.
+// The cursor is opened by the first call to Next.
+func (d *DurableObjectStore) OpenDurableCursor(keyRange *idb.KeyRange, direction idb.CursorDirection) *DurableCursor {
+	return &DurableCursor{
+		store:     d,
+		keyRange:  keyRange,
+		direction: direction,
+		key:       safejs.Undefined(),
+		value:     safejs.Undefined(),
+	}
+}
+
+// Next advances to the next record, returning false once there are no more records.
+func (c *DurableCursor) Next(ctx context.Context) (bool, error) {
+	if c.done {
+		return false, nil
+	}
+	err := c.store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		if c.cursor != nil && c.txn == txn {
+			if err := c.cursor.Continue(); err != nil {
+				return err
+			}
+		} else {
+			iterRange := c.keyRange
+			if c.hasKey {
+				var more bool
+				var err error
+				iterRange, more, err = idb.ResumeKeyRange(c.keyRange, c.key, true, c.direction)
+				if err != nil {
+					return err
+				}
+				if !more {
+					c.done = true
+					return nil
+				}
+			}
+			req, err := openCursor(store, iterRange, c.direction)
+			if err != nil {
+				return err
+			}
+			c.txn, c.req = txn, req
+		}
+
+		cursor, err := c.req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		if cursor.Unwrap().IsNull() {
+			c.done = true
+			return nil
+		}
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		c.cursor = cursor
+		c.key, c.value, c.hasKey = key, value, true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if c.done {
+		c.cursor, c.req, c.txn = nil, nil, nil
+		c.key, c.value = safejs.Undefined(), safejs.Undefined()
+		return false, nil
+	}
+	return true, nil
+}
+
+// Key returns the key of the current record, or undefined before the first call to Next and after the last.
+func (c *DurableCursor) Key() safejs.Value {
+	return c.key
+}
+
+// Value returns the value of the current record, or undefined before the first call to Next and after the last.
+func (c *DurableCursor) Value() safejs.Value {
+	return c.value
+}
+
+// Iter calls fn with the key and value of each record in keyRange, in direction, reopening the cursor in a new transaction if the transaction finishes mid-iteration.
+// If keyRange is nil, iterates over all records in the store. Return idb.ErrCursorStopIter from fn to stop iterating early.
+func (d *DurableObjectStore) Iter(
+	ctx context.Context,
+	keyRange *idb.KeyRange,
+	direction idb.CursorDirection,
+	fn func(key, value safejs.Value) error,
+) error {
+	cursor := d.OpenDurableCursor(keyRange, direction)
+	for {
+		more, err := cursor.Next(ctx)
+		if err != nil || !more {
+			return err
+		}
+		if err := fn(cursor.Key(), cursor.Value()); err != nil {
+			if err == idb.ErrCursorStopIter {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// openCursor opens a cursor over keyRange, or over the entire store if keyRange is nil.
+func openCursor(store *idb.ObjectStore, keyRange *idb.KeyRange, direction idb.CursorDirection) (*idb.CursorWithValueRequest, error) {
+	if keyRange == nil {
+		return store.OpenCursor(direction)
+	}
+	return store.OpenCursorRange(keyRange, direction)
+}
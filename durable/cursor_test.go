@@ -0,0 +1,241 @@
+//go:build js && wasm
+// +build js,wasm
+
+package durable
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+// testDB opens a uniquely named database for the test, deleting it on cleanup.
+func testDB(t *testing.T, upgrader idb.Upgrader) *idb.Database {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("durable-test-%s-%d", t.Name(), time.Now().UnixNano())
+	dbReq, err := idb.Global().Open(ctx, name, 1, upgrader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := dbReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+	return db
+}
+
+// testStore creates a DurableObjectStore named "test_store" with the keys 0 through count-1, each storing its own key as the value.
+func testStore(t *testing.T, count int) *DurableObjectStore {
+	t.Helper()
+	ctx := context.Background()
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		return err
+	})
+	dt, err := NewDurableTransaction(db, idb.TransactionReadWrite, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < count; i++ {
+		if err := store.PutKey(ctx, safejs.Safe(js.ValueOf(i)), safejs.Safe(js.ValueOf(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestDurableIterMutate(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 6)
+
+	calls := make(map[int]int)
+	expired := false
+	err := store.IterMutate(ctx, nil, idb.CursorNext, func(cursor *idb.CursorWithValue) error {
+		keyValue, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		key, err := keyValue.Int()
+		if err != nil {
+			return err
+		}
+		if key == 3 && !expired {
+			expired = true
+			expireTxn(t, store.dt)
+		}
+		if key%2 == 0 {
+			_, err = cursor.Delete()
+		} else {
+			_, err = cursor.Update(safejs.Safe(js.ValueOf(key * 10)))
+		}
+		if err != nil {
+			return err
+		}
+		calls[key]++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expired {
+		t.Fatal("expected the transaction to expire during iteration")
+	}
+	for key := 0; key < 6; key++ {
+		if calls[key] != 1 {
+			t.Errorf("expected key %d to be mutated once, got %d", key, calls[key])
+		}
+	}
+
+	keys, err := store.GetAllKeys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys to remain, got %d", len(keys))
+	}
+	for _, key := range []int{1, 3, 5} {
+		value, err := store.Get(ctx, safejs.Safe(js.ValueOf(key)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := value.Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != key*10 {
+			t.Errorf("expected key %d to be updated to %d, got %d", key, key*10, got)
+		}
+	}
+}
+
+func TestDurableIterMutateRange(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 6)
+
+	keyRange, err := idb.NewKeyRangeBound(safejs.Safe(js.ValueOf(1)), safejs.Safe(js.ValueOf(4)), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen []int
+	expired := false
+	err = store.IterMutate(ctx, keyRange, idb.CursorPrevious, func(cursor *idb.CursorWithValue) error {
+		keyValue, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		key, err := keyValue.Int()
+		if err != nil {
+			return err
+		}
+		if len(seen) == 1 && !expired {
+			expired = true
+			expireTxn(t, store.dt)
+		}
+		if _, err := cursor.Delete(); err != nil {
+			return err
+		}
+		seen = append(seen, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(seen) != "[4 3 2 1]" {
+		t.Errorf("unexpected iteration order: %v", seen)
+	}
+	count, err := store.Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 records to remain, got %d", count)
+	}
+}
+
+func TestDurableCursor(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 5)
+
+	cursor := store.OpenDurableCursor(nil, idb.CursorNext)
+	if !cursor.Key().IsUndefined() {
+		t.Error("expected an undefined key before Next")
+	}
+	var seen []int
+	for {
+		more, err := cursor.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !more {
+			break
+		}
+		key, err := cursor.Key().Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := cursor.Value().Int()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != value {
+			t.Errorf("expected value %d, got %d", key, value)
+		}
+		seen = append(seen, key)
+		if key%2 == 0 {
+			expireTxn(t, store.dt)
+		}
+	}
+	if fmt.Sprint(seen) != "[0 1 2 3 4]" {
+		t.Errorf("unexpected iteration order: %v", seen)
+	}
+	more, err := cursor.Next(ctx)
+	if err != nil || more {
+		t.Errorf("expected the cursor to stay done, got %v, %v", more, err)
+	}
+}
+
+func TestDurableIter(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 6)
+
+	keyRange, err := idb.NewKeyRangeBound(safejs.Safe(js.ValueOf(1)), safejs.Safe(js.ValueOf(4)), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen []int
+	err = store.Iter(ctx, keyRange, idb.CursorPrevious, func(keyValue, _ safejs.Value) error {
+		key, err := keyValue.Int()
+		if err != nil {
+			return err
+		}
+		seen = append(seen, key)
+		if key == 2 {
+			return idb.ErrCursorStopIter
+		}
+		expireTxn(t, store.dt)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(seen) != "[4 3 2]" {
+		t.Errorf("unexpected iteration order: %v", seen)
+	}
+}
@@ -5,8 +5,10 @@ package durable
 
 import (
 	"context"
+	"errors"
 	"syscall/js"
 	"testing"
+	"time"
 
 	"github.com/aperturerobotics/go-indexeddb/idb"
 	"github.com/hack-pad/safejs"
@@ -20,7 +22,7 @@ func TestDurableTransaction(t *testing.T) {
 		if err != nil {
 			return err
 		}
-		_, err = store.CreateIndex("test_index", safejs.Safe(js.ValueOf("key")), idb.IndexOptions{})
+		_, err = store.CreateIndex("test_index", idb.NewKeyPath("key"), idb.IndexOptions{})
 		return err
 	})
 	if err != nil {
@@ -91,3 +93,305 @@ func TestDurableTransaction(t *testing.T) {
 		t.Errorf("expected undefined, got %v", delVal.Type().String())
 	}
 }
+
+func TestDurableTransactionRetryable(t *testing.T) {
+	errTransient := errors.New("transient")
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		return err
+	})
+	dt, err := NewDurableTransactionWithOptions(db, idb.TransactionReadWrite, Options{
+		Retryable: func(err error) bool {
+			return errors.Is(err, errTransient) || idb.IsTxnFinishedErr(err)
+		},
+	}, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts int
+	err = store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		attempts++
+		if attempts == 1 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+
+	err = store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		return errors.New("permanent")
+	})
+	if err == nil || err.Error() != "permanent" {
+		t.Errorf("expected the permanent error, got %v", err)
+	}
+}
+
+func TestDurableTransactionQuotaExceeded(t *testing.T) {
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		return err
+	})
+	var evictions int
+	errEvict := errors.New("nothing to evict")
+	dt, err := NewDurableTransactionWithOptions(db, idb.TransactionReadWrite, Options{
+		OnQuotaExceeded: func(estimate idb.StorageEstimate, err error) error {
+			evictions++
+			if !errors.Is(err, idb.ErrQuotaExceeded) {
+				t.Errorf("expected a quota error, got %v", err)
+			}
+			if evictions == 3 {
+				return errEvict
+			}
+			return nil
+		},
+	}, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts int
+	err = store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		attempts++
+		if attempts == 1 {
+			return idb.ErrQuotaExceeded
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 || evictions != 1 {
+		t.Errorf("expected 2 attempts and 1 eviction, got %d and %d", attempts, evictions)
+	}
+	// start the next operations in a new transaction, so they have no earlier operations to roll back
+	if err := dt.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// only retried once
+	err = store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		return idb.ErrQuotaExceeded
+	})
+	if !errors.Is(err, idb.ErrQuotaExceeded) || evictions != 2 {
+		t.Errorf("expected the quota error after 2 evictions, got %v after %d", err, evictions)
+	}
+
+	err = store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		return idb.ErrQuotaExceeded
+	})
+	if !errors.Is(err, idb.ErrQuotaExceeded) || !errors.Is(err, errEvict) {
+		t.Errorf("expected the quota and eviction errors, got %v", err)
+	}
+}
+
+func TestDurableTransactionQuotaExceededRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		return err
+	})
+	var evictions int
+	dt, err := NewDurableTransactionWithOptions(db, idb.TransactionReadWrite, Options{
+		OnQuotaExceeded: func(estimate idb.StorageEstimate, err error) error {
+			evictions++
+			return nil
+		},
+	}, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.PutKey(ctx, safejs.Safe(js.ValueOf(1)), safejs.Safe(js.ValueOf("one"))); err != nil {
+		t.Fatal(err)
+	}
+	err = store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		req, err := store.PutKey(safejs.Safe(js.ValueOf(2)), safejs.Safe(js.ValueOf("two")))
+		if err != nil {
+			return err
+		}
+		if _, err := req.Await(ctx); err != nil {
+			return err
+		}
+		return idb.ErrQuotaExceeded
+	})
+	if !errors.Is(err, ErrRolledBack) || !errors.Is(err, idb.ErrQuotaExceeded) {
+		t.Errorf("expected a rolled back quota error, got %v", err)
+	}
+	if evictions != 0 {
+		t.Errorf("expected no evictions, got %d", evictions)
+	}
+	count, err := store.Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("expected both puts to be rolled back, got %d records", count)
+	}
+}
+
+func TestDurableTransactionStrict(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		return err
+	})
+	dt, err := NewDurableTransactionWithOptions(db, idb.TransactionReadWrite, Options{Strict: true}, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.PutKey(ctx, safejs.Safe(js.ValueOf(1)), safejs.Safe(js.ValueOf("one"))); err != nil {
+		t.Fatal(err)
+	}
+	expireTxn(t, dt)
+	err = store.PutKey(ctx, safejs.Safe(js.ValueOf(2)), safejs.Safe(js.ValueOf("two")))
+	if !errors.Is(err, ErrPartialCommit) || !idb.IsTxnFinishedErr(err) {
+		t.Fatalf("expected a partial commit error, got %v", err)
+	}
+
+	// the next operation starts a new transaction, so it can be retried again
+	if err := store.PutKey(ctx, safejs.Safe(js.ValueOf(2)), safejs.Safe(js.ValueOf("two"))); err != nil {
+		t.Fatal(err)
+	}
+	count, err := store.Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 records, got %d", count)
+	}
+}
+
+func TestDurableTransactionKeepAlive(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		return err
+	})
+	dt, err := NewDurableTransactionWithOptions(db, idb.TransactionReadWrite, Options{KeepAlive: time.Second}, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var txns []*idb.Transaction
+	for i := 0; i < 2; i++ {
+		err := store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+			txns = append(txns, txn)
+			req, err := store.PutKey(safejs.Safe(js.ValueOf(i)), safejs.Safe(js.ValueOf(i)))
+			if err != nil {
+				return err
+			}
+			_, err = req.Await(ctx)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// yield to the event loop, which would commit the transaction without keep-alive
+		time.Sleep(50 * time.Millisecond)
+	}
+	if len(txns) != 2 || txns[0] != txns[1] {
+		t.Errorf("expected both writes in one transaction without retrying, got %d attempts", len(txns))
+	}
+	if err := dt.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	count, err := store.Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 records, got %d", count)
+	}
+	if err := dt.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDurableTransactionContext(t *testing.T) {
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		return err
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := NewDurableTransactionContext(ctx, db, idb.TransactionReadWrite, Options{}, "missing"); !errors.Is(err, idb.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing store, got %v", err)
+	}
+
+	dt, err := NewDurableTransactionContext(ctx, db, idb.TransactionReadWrite, Options{}, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the durable transaction doesn't hold locks before its first operation
+	txn, err := db.Transaction(idb.TransactionReadWrite, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherStore, err := txn.ObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := otherStore.PutKey(safejs.Safe(js.ValueOf("other")), safejs.Safe(js.ValueOf(1))); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Await(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.PutKey(ctx, safejs.Safe(js.ValueOf("key")), safejs.Safe(js.ValueOf(2))); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	expireTxn(t, dt)
+	_, err = store.Count(context.Background())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the retry to fail with context.Canceled, got %v", err)
+	}
+}
+
+// expireTxn waits for dt's transaction to commit automatically, which it does once control returns to the event loop with no requests pending.
+// The next operation on dt then runs in a new transaction.
+func expireTxn(t *testing.T, dt *DurableTransaction) {
+	t.Helper()
+	if dt.txn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := dt.txn.Await(ctx); err != nil {
+		t.Errorf("expected the transaction to commit: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+//go:build js && wasm
+// +build js,wasm
+
+package durable
+
+import (
+	"context"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+// DurableIndex represents an index of a DurableObjectStore that automatically retries on failure.
+type DurableIndex struct {
+	store *DurableObjectStore
+	name  string
+}
+
+// Index returns the DurableIndex named name. If the store has no such index, its operations fail with idb.ErrNotFound.
+func (d *DurableObjectStore) Index(name string) *DurableIndex {
+	return &DurableIndex{store: d, name: name}
+}
+
+// IndexWithRetry accesses the index with retry if the txn is auto-committed.
+func (i *DurableIndex) IndexWithRetry(cb func(txn *idb.Transaction, index *idb.Index) error) error {
+	return i.store.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		index, err := store.Index(i.name)
+		if err != nil {
+			return err
+		}
+		return cb(txn, index)
+	})
+}
+
+// Count returns the total number of records in the index.
+func (i *DurableIndex) Count(ctx context.Context) (uint, error) {
+	var cnt uint
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.Count()
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		cnt = resp
+		return nil
+	})
+	return cnt, err
+}
+
+// CountKey returns the total number of records in the index that match the provided key.
+func (i *DurableIndex) CountKey(ctx context.Context, key safejs.Value) (uint, error) {
+	var cnt uint
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.CountKey(safejs.Unsafe(key))
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		cnt = resp
+		return nil
+	})
+	return cnt, err
+}
+
+// CountRange returns the total number of records in the index that match the provided KeyRange.
+func (i *DurableIndex) CountRange(ctx context.Context, keyRange *idb.KeyRange) (uint, error) {
+	var cnt uint
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.CountRange(keyRange)
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		cnt = resp
+		return nil
+	})
+	return cnt, err
+}
+
+// Get returns the first object in the index selected by the specified key.
+func (i *DurableIndex) Get(ctx context.Context, key safejs.Value) (safejs.Value, error) {
+	var value safejs.Value
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.Get(safejs.Unsafe(key))
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		value = resp
+		return nil
+	})
+	return value, err
+}
+
+// GetKey returns the primary key of the first object in the index selected by the specified key.
+func (i *DurableIndex) GetKey(ctx context.Context, key safejs.Value) (safejs.Value, error) {
+	var primaryKey safejs.Value
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.GetKey(safejs.Unsafe(key))
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		primaryKey = resp
+		return nil
+	})
+	return primaryKey, err
+}
+
+// GetAll returns all objects in the index.
+func (i *DurableIndex) GetAll(ctx context.Context) ([]safejs.Value, error) {
+	var values []safejs.Value
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.GetAll()
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		values = resp
+		return nil
+	})
+	return values, err
+}
+
+// GetAllRange returns all objects in the index matching the specified query. If maxCount is 0, retrieves all objects matching the query.
+func (i *DurableIndex) GetAllRange(ctx context.Context, query *idb.KeyRange, maxCount uint) ([]safejs.Value, error) {
+	var values []safejs.Value
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.GetAllRange(query, maxCount)
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		values = resp
+		return nil
+	})
+	return values, err
+}
+
+// GetAllKeys returns the primary keys of all objects in the index.
+func (i *DurableIndex) GetAllKeys(ctx context.Context) ([]safejs.Value, error) {
+	var keys []safejs.Value
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.GetAllKeys()
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		keys = resp
+		return nil
+	})
+	return keys, err
+}
+
+// GetAllKeysRange returns the primary keys of all objects in the index matching the specified query. If maxCount is 0, retrieves all keys matching the query.
+func (i *DurableIndex) GetAllKeysRange(ctx context.Context, query *idb.KeyRange, maxCount uint) ([]safejs.Value, error) {
+	var keys []safejs.Value
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.GetAllKeysRange(query, maxCount)
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		keys = resp
+		return nil
+	})
+	return keys, err
+}
+
+// OpenCursor opens a cursor over the index in index key order.
+// The cursor belongs to the current transaction and stops working if it finishes.
+func (i *DurableIndex) OpenCursor(ctx context.Context, direction idb.CursorDirection) (*idb.CursorWithValue, error) {
+	var cursor *idb.CursorWithValue
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.OpenCursor(direction)
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		cursor = resp
+		return nil
+	})
+	return cursor, err
+}
+
+// OpenCursorKey is the same as OpenCursor, but opens a cursor over the given key instead.
+func (i *DurableIndex) OpenCursorKey(ctx context.Context, key safejs.Value, direction idb.CursorDirection) (*idb.CursorWithValue, error) {
+	var cursor *idb.CursorWithValue
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.OpenCursorKey(safejs.Unsafe(key), direction)
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		cursor = resp
+		return nil
+	})
+	return cursor, err
+}
+
+// OpenCursorRange is the same as OpenCursor, but opens a cursor over the given range instead.
+func (i *DurableIndex) OpenCursorRange(ctx context.Context, keyRange *idb.KeyRange, direction idb.CursorDirection) (*idb.CursorWithValue, error) {
+	var cursor *idb.CursorWithValue
+	err := i.IndexWithRetry(func(txn *idb.Transaction, index *idb.Index) error {
+		req, err := index.OpenCursorRange(keyRange, direction)
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		cursor = resp
+		return nil
+	})
+	return cursor, err
+}
@@ -0,0 +1,77 @@
+//go:build js && wasm
+// +build js,wasm
+
+package durable
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+func TestDurableIndex(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t, func(db *idb.Database, oldVersion, newVersion uint) error {
+		store, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		if err != nil {
+			return err
+		}
+		_, err = store.CreateIndex("color", idb.NewKeyPath("color"), idb.IndexOptions{})
+		return err
+	})
+	dt, err := NewDurableTransaction(db, idb.TransactionReadWrite, "test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := dt.GetObjectStore("test_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, color := range []string{"red", "blue", "red"} {
+		value := safejs.Safe(js.ValueOf(map[string]interface{}{"color": color}))
+		if err := store.PutKey(ctx, safejs.Safe(js.ValueOf(i)), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	index := store.Index("color")
+	red := safejs.Safe(js.ValueOf("red"))
+	expireTxn(t, dt)
+	count, err := index.CountKey(ctx, red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 red records, got %d", count)
+	}
+
+	expireTxn(t, dt)
+	primaryKey, err := index.GetKey(ctx, safejs.Safe(js.ValueOf("blue")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !primaryKey.Equal(safejs.Safe(js.ValueOf(1))) {
+		t.Errorf("expected primary key 1, got %v", primaryKey)
+	}
+
+	expireTxn(t, dt)
+	keyRange, err := idb.NewKeyRangeOnly(red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := index.GetAllKeysRange(ctx, keyRange, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || !keys[0].Equal(safejs.Safe(js.ValueOf(0))) || !keys[1].Equal(safejs.Safe(js.ValueOf(2))) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	if _, err := store.Index("missing").Count(ctx); !errors.Is(err, idb.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing index, got %v", err)
+	}
+}
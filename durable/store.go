@@ -98,6 +98,51 @@ func (d *DurableObjectStore) Get(ctx context.Context, key safejs.Value) (safejs.
 	return value, err
 }
 
+// GetMany returns the values of the records with the given keys, in the same order as keys. Records which don't exist have an undefined value.
+func (d *DurableObjectStore) GetMany(ctx context.Context, keys []safejs.Value) ([]safejs.Value, error) {
+	var values []safejs.Value
+	err := d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		resp, err := store.GetMany(ctx, keys)
+		if err != nil {
+			return err
+		}
+		values = resp
+		return nil
+	})
+	return values, err
+}
+
+// Upsert reads the record at key, calls merge with its value, then stores the merged value at key. Returns the merged value.
+// If the transaction finishes before the merged value is stored, the whole read-modify-write restarts in a new transaction, so merge may be called more than once.
+// See idb.ObjectStore.Upsert.
+func (d *DurableObjectStore) Upsert(ctx context.Context, key safejs.Value, merge func(existing safejs.Value) (safejs.Value, error)) (safejs.Value, error) {
+	var value safejs.Value
+	err := d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		resp, err := store.Upsert(ctx, key, merge)
+		if err != nil {
+			return err
+		}
+		value = resp
+		return nil
+	})
+	return value, err
+}
+
+// Increment adds delta to the number stored at key and returns the new number. A missing record counts as 0.
+// If the transaction finishes before the new number is stored, the increment restarts in a new transaction, so delta is only applied once.
+func (d *DurableObjectStore) Increment(ctx context.Context, key safejs.Value, delta float64) (float64, error) {
+	var value float64
+	err := d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		resp, err := store.Increment(ctx, key, delta)
+		if err != nil {
+			return err
+		}
+		value = resp
+		return nil
+	})
+	return value, err
+}
+
 // Put creates a structured clone of the value, and stores the cloned value in the object store. This is for updating existing records in an object store when the transaction's mode is readwrite.
 func (d *DurableObjectStore) Put(ctx context.Context, value safejs.Value) error {
 	return d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
@@ -122,6 +167,36 @@ func (d *DurableObjectStore) PutKey(ctx context.Context, key, value safejs.Value
 	})
 }
 
+// PutBytes is the same as PutKey, but stores b as a Uint8Array.
+func (d *DurableObjectStore) PutBytes(ctx context.Context, key safejs.Value, b []byte) error {
+	return d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		req, err := store.PutBytes(key, b)
+		if err != nil {
+			return err
+		}
+		_, err = req.Await(ctx)
+		return err
+	})
+}
+
+// GetBytes is the same as Get, but reads an ArrayBuffer or typed array value into a byte slice. Returns nil if no record was found.
+func (d *DurableObjectStore) GetBytes(ctx context.Context, key safejs.Value) ([]byte, error) {
+	var value []byte
+	err := d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		req, err := store.GetBytes(key)
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		value = resp
+		return nil
+	})
+	return value, err
+}
+
 // AddKey is the same as Add, but includes the key to use to identify the record.
 func (d *DurableObjectStore) AddKey(ctx context.Context, key, value safejs.Value) error {
 	return d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
@@ -187,6 +262,42 @@ func (d *DurableObjectStore) CountRange(ctx context.Context, keyRange *idb.KeyRa
 	return cnt, err
 }
 
+// GetAll returns all objects in the object store.
+func (d *DurableObjectStore) GetAll(ctx context.Context) ([]safejs.Value, error) {
+	var values []safejs.Value
+	err := d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		req, err := store.GetAll()
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		values = resp
+		return nil
+	})
+	return values, err
+}
+
+// GetAllRange returns all objects in the object store matching the specified query. If maxCount is 0, retrieves all objects matching the query.
+func (d *DurableObjectStore) GetAllRange(ctx context.Context, query *idb.KeyRange, maxCount uint) ([]safejs.Value, error) {
+	var values []safejs.Value
+	err := d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		req, err := store.GetAllRange(query, maxCount)
+		if err != nil {
+			return err
+		}
+		resp, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		values = resp
+		return nil
+	})
+	return values, err
+}
+
 // GetAllKeys returns an ArrayRequest that retrieves record keys for all objects in the object store.
 func (d *DurableObjectStore) GetAllKeys(ctx context.Context) ([]safejs.Value, error) {
 	var keys []safejs.Value
@@ -223,7 +334,24 @@ func (d *DurableObjectStore) GetAllKeysRange(ctx context.Context, query *idb.Key
 	return keys, err
 }
 
+// GetPage returns up to limit records after afterKey, in direction order. If afterKey is undefined, starts at the first record in direction.
+// Pass the page's NextKey as afterKey to get the next page. If the transaction finishes while a page is read, the page is read again in a new transaction, so pages may be requested at any pace.
+// See idb.ObjectStore.GetPage.
+func (d *DurableObjectStore) GetPage(ctx context.Context, afterKey safejs.Value, limit uint, direction idb.CursorDirection) (idb.Page, error) {
+	var page idb.Page
+	err := d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
+		resp, err := store.GetPage(ctx, afterKey, limit, direction)
+		if err != nil {
+			return err
+		}
+		page = resp
+		return nil
+	})
+	return page, err
+}
+
 // OpenCursor returns a CursorWithValueRequest, and, in a separate thread, returns a new CursorWithValue. Used for iterating through an object store by primary key with a cursor.
+// The cursor belongs to the current transaction and stops working if it finishes. Use OpenDurableCursor or Iter to keep iterating across transactions.
 func (d *DurableObjectStore) OpenCursor(ctx context.Context, direction idb.CursorDirection) (*idb.CursorWithValue, error) {
 	var cursor *idb.CursorWithValue
 	err := d.StoreWithRetry(func(txn *idb.Transaction, store *idb.ObjectStore) error {
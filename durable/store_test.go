@@ -0,0 +1,172 @@
+//go:build js && wasm
+// +build js,wasm
+
+package durable
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+func TestDurableBytes(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 0)
+
+	key := safejs.Safe(js.ValueOf("some id"))
+	if err := store.PutBytes(ctx, key, []byte("some data")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := store.GetBytes(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "some data" {
+		t.Errorf("unexpected bytes: %q", b)
+	}
+}
+
+func TestDurableGetMany(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 3)
+
+	keys := []safejs.Value{safejs.Safe(js.ValueOf(2)), safejs.Safe(js.ValueOf(7)), safejs.Safe(js.ValueOf(0))}
+	values, err := store.GetMany(ctx, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 {
+		t.Fatalf("unexpected number of values: %d", len(values))
+	}
+	if !values[0].Equal(keys[0]) || !values[1].IsUndefined() || !values[2].Equal(keys[2]) {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestDurableUpsert(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 2)
+
+	key := safejs.Safe(js.ValueOf(1))
+	calls := 0
+	merged, err := store.Upsert(ctx, key, func(existing safejs.Value) (safejs.Value, error) {
+		calls++
+		if calls == 1 {
+			expireTxn(t, store.dt)
+		}
+		n, err := existing.Int()
+		if err != nil {
+			return safejs.Undefined(), err
+		}
+		return safejs.ValueOf(n + 10)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(merged) || !got.Equal(safejs.Safe(js.ValueOf(11))) {
+		t.Errorf("unexpected value: %v", got)
+	}
+	if calls != 2 {
+		t.Errorf("expected merge to restart once, got %d calls", calls)
+	}
+}
+
+func TestDurableIncrement(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 2)
+
+	n, err := store.Increment(ctx, safejs.Safe(js.ValueOf(1)), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Errorf("unexpected value: %v", n)
+	}
+	// the increment restarts in a new transaction, without applying the delta twice
+	expireTxn(t, store.dt)
+	n, err = store.Increment(ctx, safejs.Safe(js.ValueOf(1)), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 9 {
+		t.Errorf("unexpected value: %v", n)
+	}
+	stored, err := store.Get(ctx, safejs.Safe(js.ValueOf(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Equal(safejs.Safe(js.ValueOf(9))) {
+		t.Errorf("expected the stored value to be 1+5+3, got %v", stored)
+	}
+
+	n, err = store.Increment(ctx, safejs.Safe(js.ValueOf("missing")), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("unexpected value: %v", n)
+	}
+}
+
+func TestDurableGetAll(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 5)
+
+	values, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 5 {
+		t.Errorf("unexpected number of values: %d", len(values))
+	}
+	expireTxn(t, store.dt)
+	keyRange, err := idb.NewKeyRangeLowerBound(safejs.Safe(js.ValueOf(2)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err = store.GetAllRange(ctx, keyRange, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || !values[0].Equal(safejs.Safe(js.ValueOf(2))) || !values[1].Equal(safejs.Safe(js.ValueOf(3))) {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestDurableGetPage(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, 5)
+
+	var seen []safejs.Value
+	afterKey := safejs.Undefined()
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("too many pages")
+		}
+		page, err := store.GetPage(ctx, afterKey, 2, idb.CursorNext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen = append(seen, page.Keys...)
+		if page.NextKey.IsUndefined() {
+			break
+		}
+		afterKey = page.NextKey
+		expireTxn(t, store.dt)
+	}
+	if len(seen) != 5 {
+		t.Fatalf("expected 5 keys, got %v", seen)
+	}
+	for i, key := range seen {
+		if !key.Equal(safejs.Safe(js.ValueOf(i))) {
+			t.Errorf("expected key %d, got %v", i, key)
+		}
+	}
+}
@@ -4,7 +4,10 @@
 package durable
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/aperturerobotics/go-indexeddb/idb"
 )
@@ -14,45 +17,111 @@ import (
 //
 // See: ../../README.md#Transactions-Expiring
 type DurableTransaction struct {
+	ctx              context.Context
 	db               *idb.Database
 	txnMode          idb.TransactionMode
 	objectStoreNames []string
 	txn              *idb.Transaction
 	objectStores     map[string]*DurableObjectStore
+	retryable        func(error) bool
+	onQuotaExceeded  func(estimate idb.StorageEstimate, err error) error
+	strict           bool
+	keepAlive        time.Duration
+	// stopKeepAlive stops keeping txn alive, or is nil if it isn't kept alive.
+	stopKeepAlive func()
+	// txnOps is the number of operations which succeeded in txn.
+	txnOps int
+}
+
+var (
+	// ErrPartialCommit is returned in strict mode when an operation fails after the transaction finished, but earlier operations in it already committed.
+	ErrPartialCommit = errors.New("durable transaction partially committed")
+	// ErrRolledBack is returned when an operation exceeds the quota after earlier operations in the same transaction, which are rolled back instead of evicting data and retrying.
+	ErrRolledBack = errors.New("durable transaction rolled back")
+)
+
+// Options contains options for NewDurableTransactionWithOptions and NewDurableTransactionContext.
+type Options struct {
+	// Retryable reports whether an operation which failed with err should be retried in a new transaction. Defaults to idb.IsTxnFinishedErr.
+	// Extend it to retry other transient errors. Only the failed operation is retried, in a new transaction, so only return true for errors which leave it safe to repeat.
+	Retryable func(err error) bool
+	// OnQuotaExceeded, if set, is called when an operation fails with idb.ErrQuotaExceeded, to evict data so the operation fits.
+	// It receives the browser's storage estimate, zero if the browser can't estimate, and the error. If it returns nil, the operation is retried once in a new transaction.
+	// The failed transaction is aborted first, so OnQuotaExceeded may delete records in its own transactions on the same stores.
+	// Aborting would roll back earlier operations in the transaction, so if any succeeded, the transaction is aborted without evicting, and an error wrapping ErrRolledBack and the quota error is returned.
+	OnQuotaExceeded func(estimate idb.StorageEstimate, err error) error
+	// Strict only retries an operation in a new transaction if no earlier operation succeeded in the current one, so the operations between commits are applied all or nothing.
+	// If the transaction finishes after earlier operations, returns an error wrapping ErrPartialCommit and the operation's error instead of retrying.
+	// The earlier operations may have committed, so the caller decides whether to redo or compensate for them.
+	Strict bool
+	// KeepAlive, if positive, keeps the transaction alive between operations for up to this long, capped at idb.MaxKeepAlive, so it doesn't commit while the caller computes.
+	// This avoids retrying operations in new transactions during short pauses, and retrying remains the fallback for longer ones. See idb.Transaction.KeepAlive.
+	// Call Commit once done, since the transaction can't commit automatically while it's kept alive.
+	KeepAlive time.Duration
 }
 
 // NewDurableTransaction creates a new DurableTransaction.
 func NewDurableTransaction(db *idb.Database, txnMode idb.TransactionMode, objectStoreNames ...string) (*DurableTransaction, error) {
+	return NewDurableTransactionWithOptions(db, txnMode, Options{}, objectStoreNames...)
+}
+
+// NewDurableTransactionWithOptions creates a new DurableTransaction configured by options.
+func NewDurableTransactionWithOptions(db *idb.Database, txnMode idb.TransactionMode, options Options, objectStoreNames ...string) (*DurableTransaction, error) {
+	return NewDurableTransactionContext(context.Background(), db, txnMode, options, objectStoreNames...)
+}
+
+// NewDurableTransactionContext creates a new DurableTransaction configured by options, which stops retrying once ctx is done.
+// After ctx is done, operations which need a new transaction fail with ctx's error instead of opening one.
+//
+// The underlying transaction isn't opened until the first operation, so creating a DurableTransaction early doesn't hold locks on its object stores.
+// Returns an error wrapping idb.ErrNotFound if the database has no object store named in objectStoreNames.
+func NewDurableTransactionContext(ctx context.Context, db *idb.Database, txnMode idb.TransactionMode, options Options, objectStoreNames ...string) (*DurableTransaction, error) {
 	if len(objectStoreNames) == 0 {
 		return nil, errors.New("transaction must have at least one object store")
 	}
+	dbStoreNames, err := db.ObjectStoreNames()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range objectStoreNames {
+		if !contains(dbStoreNames, name) {
+			return nil, fmt.Errorf("%w: no object store named %q", idb.ErrNotFound, name)
+		}
+	}
 
+	retryable := options.Retryable
+	if retryable == nil {
+		retryable = idb.IsTxnFinishedErr
+	}
 	dt := &DurableTransaction{
+		ctx:              ctx,
 		db:               db,
 		txnMode:          txnMode,
 		objectStoreNames: objectStoreNames,
 		objectStores:     make(map[string]*DurableObjectStore),
+		retryable:        retryable,
+		onQuotaExceeded:  options.OnQuotaExceeded,
+		strict:           options.Strict,
+		keepAlive:        options.KeepAlive,
 	}
-
-	if err := dt.ensureTransaction(); err != nil {
-		return nil, err
-	}
-
 	for _, name := range objectStoreNames {
-		store, err := dt.txn.ObjectStore(name)
-		if err != nil {
-			return nil, err
-		}
 		dt.objectStores[name] = &DurableObjectStore{
-			dt:    dt,
-			name:  name,
-			store: store,
+			dt:   dt,
+			name: name,
 		}
 	}
-
 	return dt, nil
 }
 
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 // GetObjectStore returns the DurableObjectStore for the given name.
 func (t *DurableTransaction) GetObjectStore(name string) (*DurableObjectStore, error) {
 	store, ok := t.objectStores[name]
@@ -67,6 +136,7 @@ func (t *DurableTransaction) GetObjectStore(name string) (*DurableObjectStore, e
 // Returns if the abort request did anything and any error.
 // NOTE: the transaction will commit automatically if the goroutine is backgrounded.
 func (t *DurableTransaction) Abort() (bool, error) {
+	t.endKeepAlive()
 	if t.txn == nil {
 		return false, nil
 	}
@@ -86,6 +156,7 @@ func (t *DurableTransaction) Abort() (bool, error) {
 // no-op if the transaction was already committed
 // NOTE: the transaction will commit automatically if the goroutine is backgrounded.
 func (t *DurableTransaction) Commit() error {
+	t.endKeepAlive()
 	if t.txn == nil {
 		return nil
 	}
@@ -98,17 +169,21 @@ func (t *DurableTransaction) Commit() error {
 	return err
 }
 
-// ensureTransaction ensures dt.txn is not nil.
+// ensureTransaction ensures dt.txn is not nil, opening a new transaction unless ctx is done.
 func (t *DurableTransaction) ensureTransaction() error {
 	if t.txn != nil {
 		return nil
 	}
+	if err := t.ctx.Err(); err != nil {
+		return err
+	}
 
 	txn, err := t.db.Transaction(t.txnMode, t.objectStoreNames[0], t.objectStoreNames[1:]...)
 	if err != nil {
 		return err
 	}
 	t.txn = txn
+	t.txnOps = 0
 
 	for name, durableStore := range t.objectStores {
 		store, err := t.txn.ObjectStore(name)
@@ -121,8 +196,11 @@ func (t *DurableTransaction) ensureTransaction() error {
 	return nil
 }
 
-// TxnWithRetry retries if we get a Transaction Finished error.
+// TxnWithRetry retries if we get a Transaction Finished error, or another error retryable by Options.Retryable.
+// If Options.OnQuotaExceeded is set, also retries once after evicting data if fn fails with idb.ErrQuotaExceeded.
 func (t *DurableTransaction) TxnWithRetry(fn func(txn *idb.Transaction) error) error {
+	t.endKeepAlive()
+	evicted := false
 	for {
 		if err := t.ensureTransaction(); err != nil {
 			return err
@@ -130,10 +208,34 @@ func (t *DurableTransaction) TxnWithRetry(fn func(txn *idb.Transaction) error) e
 
 		err := fn(t.txn)
 		if err == nil {
+			t.txnOps++
+			t.startKeepAlive()
 			return nil
 		}
 
-		if !idb.IsTxnFinishedErr(err) {
+		if t.strict && t.txnOps > 0 {
+			if t.retryable(err) {
+				t.txn = nil
+				return fmt.Errorf("%w: %w", ErrPartialCommit, err)
+			}
+			return err
+		}
+
+		if !evicted && t.onQuotaExceeded != nil && errors.Is(err, idb.ErrQuotaExceeded) {
+			if t.txnOps > 0 {
+				// retrying only this operation would silently drop the earlier ones
+				_ = t.txn.Abort()
+				t.txn = nil
+				return fmt.Errorf("%w: %w", ErrRolledBack, err)
+			}
+			evicted = true
+			if err := t.evict(err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !t.retryable(err) {
 			return err
 		}
 
@@ -141,3 +243,45 @@ func (t *DurableTransaction) TxnWithRetry(fn func(txn *idb.Transaction) error) e
 		t.txn = nil
 	}
 }
+
+// startKeepAlive keeps txn alive until the next operation, if Options.KeepAlive is set.
+func (t *DurableTransaction) startKeepAlive() {
+	if t.keepAlive <= 0 {
+		return
+	}
+	stop, err := t.txn.KeepAlive(t.ctx, t.keepAlive)
+	if err != nil {
+		// the transaction may have finished, so the next operation retries in a new one
+		return
+	}
+	t.stopKeepAlive = stop
+}
+
+// endKeepAlive stops keeping txn alive, so it's active for the next request.
+func (t *DurableTransaction) endKeepAlive() {
+	if t.stopKeepAlive != nil {
+		t.stopKeepAlive()
+		t.stopKeepAlive = nil
+	}
+}
+
+// markProgress records that part of an operation succeeded in the current transaction, like a record updated by IterMutate, so strict mode doesn't retry it.
+func (t *DurableTransaction) markProgress() {
+	t.txnOps++
+}
+
+// evict calls Options.OnQuotaExceeded for quotaErr, the error of an operation which exceeded the quota.
+func (t *DurableTransaction) evict(quotaErr error) error {
+	// finish the failed transaction, so the callback's transactions don't wait on it
+	_ = t.txn.Abort()
+	t.txn = nil
+
+	estimate, err := idb.EstimateStorage(t.ctx)
+	if err != nil {
+		estimate = idb.StorageEstimate{}
+	}
+	if err := t.onQuotaExceeded(estimate, quotaErr); err != nil {
+		return fmt.Errorf("%w: eviction failed: %w", quotaErr, err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package dynstore multiplexes named logical stores onto a fixed schema of two object stores, so stores can be created at runtime without a version change.
+//
+// Records of every logical store live in the data store, with keys prefixed by the logical store's name. The catalog store lists the logical stores which exist.
+// Once a logical store's schema settles, move it to a real object store with Migrate.
+package dynstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	// DataStoreName is the name of the object store holding every logical store's records.
+	DataStoreName = "dynstore.data"
+	// CatalogStoreName is the name of the object store listing the logical stores, keyed by name.
+	CatalogStoreName = "dynstore.catalog"
+)
+
+var (
+	// ErrStoreExists is returned by Create if the logical store already exists.
+	ErrStoreExists = errors.New("logical store already exists")
+	// ErrStoreNotFound is returned when a logical store doesn't exist.
+	ErrStoreNotFound = errors.New("logical store not found")
+)
+
+// CreateSchema creates the data and catalog object stores. Call it once during an upgrade.
+func CreateSchema(db *idb.Database) error {
+	if _, err := db.CreateObjectStore(DataStoreName, idb.ObjectStoreOptions{}); err != nil {
+		return err
+	}
+	_, err := db.CreateObjectStore(CatalogStoreName, idb.ObjectStoreOptions{})
+	return err
+}
+
+// Transaction starts a transaction over the data and catalog object stores, for use with Open.
+func Transaction(db *idb.Database, mode idb.TransactionMode) (*idb.Transaction, error) {
+	return db.Transaction(mode, DataStoreName, CatalogStoreName)
+}
+
+// Create creates the logical store named name. Returns ErrStoreExists if it already exists.
+func Create(ctx context.Context, db *idb.Database, name string) error {
+	return idb.RetryTxn(ctx, db, idb.TransactionReadWrite, func(txn *idb.Transaction) error {
+		catalog, err := txn.ObjectStore(CatalogStoreName)
+		if err != nil {
+			return err
+		}
+		exists, err := storeExists(ctx, catalog, name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", ErrStoreExists, name)
+		}
+		req, err := catalog.PutKeyValue(name, map[string]interface{}{"created": time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = req.Await(ctx)
+		return err
+	}, CatalogStoreName)
+}
+
+// Names returns the names of the logical stores, in ascending order.
+func Names(ctx context.Context, db *idb.Database) ([]string, error) {
+	var names []string
+	err := idb.RetryTxn(ctx, db, idb.TransactionReadOnly, func(txn *idb.Transaction) error {
+		catalog, err := txn.ObjectStore(CatalogStoreName)
+		if err != nil {
+			return err
+		}
+		req, err := catalog.GetAllKeys()
+		if err != nil {
+			return err
+		}
+		keys, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		names = make([]string, 0, len(keys))
+		for _, key := range keys {
+			name, err := key.String()
+			if err != nil {
+				return err
+			}
+			names = append(names, name)
+		}
+		return nil
+	}, CatalogStoreName)
+	return names, err
+}
+
+// Open returns the logical store named name within txn, which must include the data and catalog object stores. See Transaction.
+// Returns ErrStoreNotFound if the logical store doesn't exist.
+func Open(ctx context.Context, txn *idb.Transaction, name string) (*idb.StorePartition, error) {
+	catalog, err := txn.ObjectStore(CatalogStoreName)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := storeExists(ctx, catalog, name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrStoreNotFound, name)
+	}
+	data, err := txn.ObjectStore(DataStoreName)
+	if err != nil {
+		return nil, err
+	}
+	return data.Partition(name), nil
+}
+
+// Delete deletes the logical store named name and all of its records. Returns ErrStoreNotFound if it doesn't exist.
+func Delete(ctx context.Context, db *idb.Database, name string) error {
+	return idb.RetryTxn(ctx, db, idb.TransactionReadWrite, func(txn *idb.Transaction) error {
+		return deleteStore(ctx, txn, name)
+	}, DataStoreName, CatalogStoreName)
+}
+
+func deleteStore(ctx context.Context, txn *idb.Transaction, name string) error {
+	store, err := Open(ctx, txn, name)
+	if err != nil {
+		return err
+	}
+	if _, err := store.DeleteRange(nil); err != nil {
+		return err
+	}
+	catalog, err := txn.ObjectStore(CatalogStoreName)
+	if err != nil {
+		return err
+	}
+	req, err := catalog.DeleteValue(name)
+	if err != nil {
+		return err
+	}
+	return req.Await(ctx)
+}
+
+// Migrate moves every record of the logical store named name into the real object store named dst, then deletes the logical store, all in one transaction.
+// Create dst in an upgrade first. If dst uses in-line keys, the records' values must contain their keys at dst's key path. Otherwise, records keep their keys.
+func Migrate(ctx context.Context, db *idb.Database, name, dst string) error {
+	return idb.RetryTxn(ctx, db, idb.TransactionReadWrite, func(txn *idb.Transaction) error {
+		store, err := Open(ctx, txn, name)
+		if err != nil {
+			return err
+		}
+		dstStore, err := txn.ObjectStore(dst)
+		if err != nil {
+			return err
+		}
+		keyPath, err := dstStore.TypedKeyPath()
+		if err != nil {
+			return err
+		}
+		err = store.Iter(ctx, nil, idb.CursorNext, func(key, value safejs.Value) error {
+			var err error
+			if keyPath.IsZero() {
+				_, err = dstStore.PutKey(key, value)
+			} else {
+				_, err = dstStore.Put(value)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return deleteStore(ctx, txn, name)
+	}, DataStoreName, CatalogStoreName, dst)
+}
+
+func storeExists(ctx context.Context, catalog *idb.ObjectStore, name string) (bool, error) {
+	key, err := safejs.ValueOf(name)
+	if err != nil {
+		return false, err
+	}
+	req, err := catalog.CountKey(key)
+	if err != nil {
+		return false, err
+	}
+	count, err := req.Await(ctx)
+	return count > 0, err
+}
@@ -0,0 +1,138 @@
+//go:build js && wasm
+// +build js,wasm
+
+package dynstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+// testDB opens a uniquely named database for the test with the dynstore schema and a "migrated" object store, deleting it on cleanup.
+func testDB(t *testing.T) *idb.Database {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("dynstore-test-%s-%d", t.Name(), time.Now().UnixNano())
+	dbReq, err := idb.Global().Open(ctx, name, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		if err := CreateSchema(db); err != nil {
+			return err
+		}
+		_, err := db.CreateObjectStore("migrated", idb.ObjectStoreOptions{})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := dbReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+	return db
+}
+
+func TestLogicalStores(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+
+	for _, name := range []string{"plugin.b", "plugin.a"} {
+		if err := Create(ctx, db, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Create(ctx, db, "plugin.a"); !errors.Is(err, ErrStoreExists) {
+		t.Errorf("expected ErrStoreExists, got %v", err)
+	}
+	names, err := Names(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"plugin.a", "plugin.b"}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+
+	txn, err := Transaction(db, idb.TransactionReadWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(ctx, txn, "missing"); !errors.Is(err, ErrStoreNotFound) {
+		t.Errorf("expected ErrStoreNotFound, got %v", err)
+	}
+	for _, name := range names {
+		store, err := Open(ctx, txn, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			if _, err := store.PutKey(safejs.Safe(js.ValueOf(i)), safejs.Safe(js.ValueOf(name))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := txn.Await(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(ctx, db, "plugin.a", "migrated"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(ctx, db, "plugin.b"); err != nil {
+		t.Fatal(err)
+	}
+	names, err = Names(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 0 {
+		t.Errorf("expected no logical stores, got %v", names)
+	}
+
+	txn, err = db.Transaction(idb.TransactionReadOnly, "migrated", DataStoreName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := txn.ObjectStore("migrated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := txn.ObjectStore(DataStoreName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migratedReq, err := migrated.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataReq, err := data.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := migratedReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 || !values[0].Equal(safejs.Safe(js.ValueOf("plugin.a"))) {
+		t.Errorf("unexpected migrated values: %v", values)
+	}
+	count, err := dataReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("expected no records left in the data store, got %d", count)
+	}
+}
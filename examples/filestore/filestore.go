@@ -0,0 +1,225 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package filestore is an example store of binary files, built only on the public API of this module.
+//
+// Files are split into chunks, so large files are never held in one record, and each file's chunks live in their own partition of a shared object store.
+// Writes replace a file's metadata and chunks in a single transaction, so readers never see a partially written file.
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	filesStoreName  = "files"
+	chunksStoreName = "chunks"
+	// ChunkSize is the maximum number of bytes stored in one chunk record.
+	ChunkSize = 64 << 10
+)
+
+var codec = idb.NewCodec()
+
+var schema = idb.Schema{Stores: []idb.StoreSchema{
+	{Name: filesStoreName, Options: idb.ObjectStoreOptions{KeyPath: idb.NewKeyPath("path")}},
+	{Name: chunksStoreName},
+}}
+
+// FileInfo describes a stored file.
+type FileInfo struct {
+	Path     string
+	Size     int
+	Modified time.Time
+}
+
+func parseFileInfo(value safejs.Value) (FileInfo, error) {
+	goValue, err := codec.GoValueOf(value)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	record, ok := goValue.(map[string]interface{})
+	if !ok {
+		return FileInfo{}, fmt.Errorf("unexpected file record %v", goValue)
+	}
+	path, _ := record["path"].(string)
+	size, _ := record["size"].(float64)
+	modified, _ := record["modified"].(time.Time)
+	return FileInfo{Path: path, Size: int(size), Modified: modified}, nil
+}
+
+// Store stores files by path.
+type Store struct {
+	db *idb.Database
+}
+
+// Open opens the file store in the database named name, creating or upgrading it as needed.
+func Open(ctx context.Context, factory *idb.Factory, name string) (*Store, error) {
+	db, err := factory.OpenSchema(ctx, name, schema)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database connection.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) stores(mode idb.TransactionMode) (txn *idb.Transaction, files, chunks *idb.ObjectStore, err error) {
+	txn, err = s.db.Transaction(mode, filesStoreName, chunksStoreName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	files, err = txn.ObjectStore(filesStoreName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	chunks, err = txn.ObjectStore(chunksStoreName)
+	return txn, files, chunks, err
+}
+
+// WriteFile stores data at path, replacing any existing file.
+func (s *Store) WriteFile(ctx context.Context, path string, data []byte) error {
+	info, err := idb.ValueOf(map[string]interface{}{
+		"path":     path,
+		"size":     len(data),
+		"modified": time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	txn, files, chunks, err := s.stores(idb.TransactionReadWrite)
+	if err != nil {
+		return err
+	}
+	fileChunks := chunks.Partition(path)
+	if _, err := fileChunks.DeleteRange(nil); err != nil {
+		return err
+	}
+	for n := 0; n*ChunkSize < len(data); n++ {
+		chunk, err := idb.BytesValue(data[n*ChunkSize : min((n+1)*ChunkSize, len(data))])
+		if err != nil {
+			return err
+		}
+		key, err := idb.ValueOf(n)
+		if err != nil {
+			return err
+		}
+		if _, err := fileChunks.PutKey(key, chunk); err != nil {
+			return err
+		}
+	}
+	if _, err := files.Put(info); err != nil {
+		return err
+	}
+	return txn.Await(ctx)
+}
+
+// ReadFile returns the contents of the file at path. Returns an error wrapping fs.ErrNotExist if it doesn't exist.
+func (s *Store) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	_, files, chunks, err := s.stores(idb.TransactionReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	infoReq, err := files.GetValue(path)
+	if err != nil {
+		return nil, err
+	}
+	chunksReq, err := chunks.Partition(path).GetAllRange(nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	infoValue, err := infoReq.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if infoValue.IsUndefined() {
+		return nil, &fs.PathError{Op: "read", Path: path, Err: fs.ErrNotExist}
+	}
+	info, err := parseFileInfo(infoValue)
+	if err != nil {
+		return nil, err
+	}
+	chunkValues, err := chunksReq.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var data bytes.Buffer
+	data.Grow(info.Size)
+	for _, chunkValue := range chunkValues {
+		chunk, err := idb.BytesFromValue(chunkValue)
+		if err != nil {
+			return nil, err
+		}
+		data.Write(chunk)
+	}
+	return data.Bytes(), nil
+}
+
+// Remove deletes the file at path. Returns an error wrapping fs.ErrNotExist if it doesn't exist.
+func (s *Store) Remove(ctx context.Context, path string) error {
+	txn, files, chunks, err := s.stores(idb.TransactionReadWrite)
+	if err != nil {
+		return err
+	}
+	key, err := idb.ValueOf(path)
+	if err != nil {
+		return err
+	}
+	countReq, err := files.CountKey(key)
+	if err != nil {
+		return err
+	}
+	count, err := countReq.Await(ctx)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		_ = txn.Abort()
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	if _, err := files.Delete(key); err != nil {
+		return err
+	}
+	if _, err := chunks.Partition(path).DeleteRange(nil); err != nil {
+		return err
+	}
+	return txn.Await(ctx)
+}
+
+// List returns the stored files, sorted by path.
+func (s *Store) List(ctx context.Context) ([]FileInfo, error) {
+	txn, err := s.db.Transaction(idb.TransactionReadOnly, filesStoreName)
+	if err != nil {
+		return nil, err
+	}
+	files, err := txn.ObjectStore(filesStoreName)
+	if err != nil {
+		return nil, err
+	}
+	req, err := files.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	values, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]FileInfo, 0, len(values))
+	for _, value := range values {
+		info, err := parseFileInfo(value)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
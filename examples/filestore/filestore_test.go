@@ -0,0 +1,94 @@
+//go:build js && wasm
+// +build js,wasm
+
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+)
+
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("filestore-test-%s-%d", t.Name(), time.Now().UnixNano())
+	store, err := Open(ctx, idb.Global(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = store.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+	return store
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+
+	large := bytes.Repeat([]byte("0123456789"), ChunkSize/4) // 2.5 chunks
+	for path, data := range map[string][]byte{
+		"/large": large,
+		"/small": []byte("hello"),
+		// shares a prefix with /small, which must not share its chunks
+		"/small2": []byte("world"),
+		"/empty":  {},
+	} {
+		if err := store.WriteFile(ctx, path, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// shrinking a file drops its extra chunks
+	if err := store.WriteFile(ctx, "/large", large[:ChunkSize+1]); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string][]byte{
+		"/large":  large[:ChunkSize+1],
+		"/small":  []byte("hello"),
+		"/small2": []byte("world"),
+		"/empty":  {},
+	} {
+		data, err := store.ReadFile(ctx, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Errorf("Expected %d bytes in %s, got %d", len(expected), path, len(data))
+		}
+	}
+
+	if err := store.Remove(ctx, "/small"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ReadFile(ctx, "/small"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist reading a removed file, got %v", err)
+	}
+	if err := store.Remove(ctx, "/small"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist removing a removed file, got %v", err)
+	}
+
+	infos, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listing []string
+	for _, info := range infos {
+		listing = append(listing, fmt.Sprintf("%s:%d", info.Path, info.Size))
+	}
+	if expected := fmt.Sprintf("[/empty:0 /large:%d /small2:5]", ChunkSize+1); fmt.Sprint(listing) != expected {
+		t.Errorf("Expected files %s, got %v", expected, listing)
+	}
+}
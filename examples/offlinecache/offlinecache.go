@@ -0,0 +1,179 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package offlinecache is an example cache of HTTP response bodies for offline use, built only on the public API of this module.
+//
+// Entries expire after a time to live. Expired entries are misses, and Prune deletes them by scanning a Date-keyed index over only the expired range.
+package offlinecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+)
+
+const (
+	storeName        = "responses"
+	expiresIndexName = "expires"
+)
+
+var (
+	// ErrMiss is returned when a URL isn't cached or its entry expired.
+	ErrMiss = errors.New("cache miss")
+
+	codec = idb.NewCodec()
+)
+
+var schema = idb.Schema{Stores: []idb.StoreSchema{{
+	Name:    storeName,
+	Options: idb.ObjectStoreOptions{KeyPath: idb.NewKeyPath("url")},
+	Indexes: []idb.IndexSchema{
+		{Name: expiresIndexName, KeyPath: idb.NewKeyPath("expires")},
+	},
+}}}
+
+// Cache stores response bodies by URL.
+type Cache struct {
+	db *idb.Database
+}
+
+// Open opens the cache in the database named name, creating or upgrading it as needed.
+func Open(ctx context.Context, factory *idb.Factory, name string) (*Cache, error) {
+	db, err := factory.OpenSchema(ctx, name, schema)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{db: db}, nil
+}
+
+// Close closes the database connection.
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// Put caches body for url, replacing any existing entry. The entry expires after ttl.
+func (c *Cache) Put(ctx context.Context, url string, body []byte, ttl time.Duration) error {
+	value, err := idb.ValueOf(map[string]interface{}{
+		"url":     url,
+		"body":    body,
+		"expires": time.Now().Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+	txn, err := c.db.Transaction(idb.TransactionReadWrite, storeName)
+	if err != nil {
+		return err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Put(value); err != nil {
+		return err
+	}
+	return txn.Await(ctx)
+}
+
+// Get returns the cached body for url. Returns ErrMiss if url isn't cached or its entry expired.
+func (c *Cache) Get(ctx context.Context, url string) ([]byte, error) {
+	txn, err := c.db.Transaction(idb.TransactionReadOnly, storeName)
+	if err != nil {
+		return nil, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return nil, err
+	}
+	req, err := store.GetValue(url)
+	if err != nil {
+		return nil, err
+	}
+	value, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsUndefined() {
+		return nil, ErrMiss
+	}
+	goValue, err := codec.GoValueOf(value)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := goValue.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected cache entry %v", goValue)
+	}
+	expires, _ := entry["expires"].(time.Time)
+	if !time.Now().Before(expires) {
+		return nil, ErrMiss
+	}
+	body, _ := entry["body"].([]byte)
+	return body, nil
+}
+
+// Prune deletes the expired entries and returns how many were deleted.
+func (c *Cache) Prune(ctx context.Context) (uint, error) {
+	expired, err := idb.NewKeyRangeTimeUpperBound(time.Now(), false)
+	if err != nil {
+		return 0, err
+	}
+	txn, err := c.db.Transaction(idb.TransactionReadWrite, storeName)
+	if err != nil {
+		return 0, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return 0, err
+	}
+	index, err := store.Index(expiresIndexName)
+	if err != nil {
+		return 0, err
+	}
+	req, err := index.OpenCursorRange(expired, idb.CursorNext)
+	if err != nil {
+		return 0, err
+	}
+	var deleted uint
+	err = req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+		_, err := cursor.Delete()
+		deleted++
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, txn.Await(ctx)
+}
+
+// URLs returns the URLs with cached entries, including expired ones not yet pruned.
+func (c *Cache) URLs(ctx context.Context) ([]string, error) {
+	txn, err := c.db.Transaction(idb.TransactionReadOnly, storeName)
+	if err != nil {
+		return nil, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return nil, err
+	}
+	req, err := store.GetAllKeys()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, len(keys))
+	for _, key := range keys {
+		url, err := key.String()
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
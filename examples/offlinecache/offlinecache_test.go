@@ -0,0 +1,74 @@
+//go:build js && wasm
+// +build js,wasm
+
+package offlinecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+)
+
+func testCache(t *testing.T) *Cache {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("offlinecache-test-%s-%d", t.Name(), time.Now().UnixNano())
+	cache, err := Open(ctx, idb.Global(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cache.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+	return cache
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cache := testCache(t)
+
+	if _, err := cache.Get(ctx, "/missing"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Expected ErrMiss for an uncached URL, got %v", err)
+	}
+	if err := cache.Put(ctx, "/fresh", []byte("hello"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put(ctx, "/stale", []byte("old"), -time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := cache.Get(ctx, "/fresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "hello" {
+		t.Errorf("Expected body %q, got %q", "hello", body)
+	}
+	if _, err := cache.Get(ctx, "/stale"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Expected ErrMiss for an expired entry, got %v", err)
+	}
+
+	pruned, err := cache.Prune(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 1 {
+		t.Errorf("Expected 1 pruned entry, got %d", pruned)
+	}
+	urls, err := cache.URLs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(urls) != "[/fresh]" {
+		t.Errorf("Unexpected URLs after pruning: %v", urls)
+	}
+}
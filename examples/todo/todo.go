@@ -0,0 +1,212 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package todo is an example storage layer for a to-do list app, built only on the public API of this module.
+//
+// It declares its object stores with an idb.Schema, so opening upgrades the database automatically, and updates records with durable transactions, which restart if the browser commits them early.
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/durable"
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	storeName        = "todos"
+	pendingIndexName = "pending"
+)
+
+var (
+	// ErrNotFound is returned when a to-do doesn't exist.
+	ErrNotFound = errors.New("to-do not found")
+
+	codec = idb.NewCodec()
+)
+
+var schema = idb.Schema{Stores: []idb.StoreSchema{{
+	Name:    storeName,
+	Options: idb.ObjectStoreOptions{KeyPath: idb.NewKeyPath("id"), AutoIncrement: true},
+	Indexes: []idb.IndexSchema{
+		// booleans aren't valid keys, so pending to-dos store a 1 and done ones store nothing, leaving them out of the index
+		{Name: pendingIndexName, KeyPath: idb.NewKeyPath("pending")},
+	},
+}}}
+
+// Todo is an item of the to-do list.
+type Todo struct {
+	ID      int
+	Title   string
+	Done    bool
+	Created time.Time
+}
+
+func (t Todo) jsValue() (safejs.Value, error) {
+	record := map[string]interface{}{
+		"title":   t.Title,
+		"created": t.Created,
+	}
+	if t.ID != 0 {
+		record["id"] = t.ID
+	}
+	if !t.Done {
+		record["pending"] = 1
+	}
+	return idb.ValueOf(record)
+}
+
+func parseTodo(value safejs.Value) (Todo, error) {
+	goValue, err := codec.GoValueOf(value)
+	if err != nil {
+		return Todo{}, err
+	}
+	record, ok := goValue.(map[string]interface{})
+	if !ok {
+		return Todo{}, fmt.Errorf("unexpected to-do record %v", goValue)
+	}
+	id, _ := record["id"].(float64)
+	title, _ := record["title"].(string)
+	created, _ := record["created"].(time.Time)
+	_, pending := record["pending"]
+	return Todo{ID: int(id), Title: title, Done: !pending, Created: created}, nil
+}
+
+// Store stores a to-do list.
+type Store struct {
+	db *idb.Database
+}
+
+// Open opens the to-do list in the database named name, creating or upgrading it as needed.
+func Open(ctx context.Context, factory *idb.Factory, name string) (*Store, error) {
+	db, err := factory.OpenSchema(ctx, name, schema)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database connection.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add adds a pending to-do with the given title and returns it.
+func (s *Store) Add(ctx context.Context, title string) (Todo, error) {
+	todo := Todo{Title: title, Created: time.Now().Truncate(time.Millisecond)}
+	value, err := todo.jsValue()
+	if err != nil {
+		return Todo{}, err
+	}
+	txn, err := s.db.Transaction(idb.TransactionReadWrite, storeName)
+	if err != nil {
+		return Todo{}, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return Todo{}, err
+	}
+	req, err := store.Put(value)
+	if err != nil {
+		return Todo{}, err
+	}
+	key, err := req.Await(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	todo.ID, err = key.Int()
+	if err != nil {
+		return Todo{}, err
+	}
+	return todo, txn.Await(ctx)
+}
+
+// SetDone marks the to-do with the given ID as done or pending. Returns ErrNotFound if it doesn't exist.
+func (s *Store) SetDone(ctx context.Context, id int, done bool) error {
+	txn, err := durable.NewDurableTransaction(s.db, idb.TransactionReadWrite, storeName)
+	if err != nil {
+		return err
+	}
+	store, err := txn.GetObjectStore(storeName)
+	if err != nil {
+		return err
+	}
+	key, err := idb.ValueOf(id)
+	if err != nil {
+		return err
+	}
+	_, err = store.Upsert(ctx, key, func(existing safejs.Value) (safejs.Value, error) {
+		if existing.IsUndefined() {
+			return safejs.Undefined(), fmt.Errorf("%w: %d", ErrNotFound, id)
+		}
+		todo, err := parseTodo(existing)
+		if err != nil {
+			return safejs.Undefined(), err
+		}
+		todo.Done = done
+		return todo.jsValue()
+	})
+	if err != nil {
+		_, _ = txn.Abort()
+		return err
+	}
+	return txn.Commit()
+}
+
+// List returns every to-do, oldest first.
+func (s *Store) List(ctx context.Context) ([]Todo, error) {
+	return s.read(ctx, func(store *idb.ObjectStore) (*idb.ArrayRequest, error) {
+		return store.GetAll()
+	})
+}
+
+// Pending returns the to-dos which aren't done, oldest first.
+func (s *Store) Pending(ctx context.Context) ([]Todo, error) {
+	return s.read(ctx, func(store *idb.ObjectStore) (*idb.ArrayRequest, error) {
+		index, err := store.Index(pendingIndexName)
+		if err != nil {
+			return nil, err
+		}
+		return index.GetAll()
+	})
+}
+
+func (s *Store) read(ctx context.Context, getAll func(*idb.ObjectStore) (*idb.ArrayRequest, error)) ([]Todo, error) {
+	txn, err := s.db.Transaction(idb.TransactionReadOnly, storeName)
+	if err != nil {
+		return nil, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return nil, err
+	}
+	req, err := getAll(store)
+	if err != nil {
+		return nil, err
+	}
+	values, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	todos := make([]Todo, 0, len(values))
+	for _, value := range values {
+		todo, err := parseTodo(value)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	return todos, nil
+}
+
+// ClearDone deletes every done to-do and returns how many were deleted.
+func (s *Store) ClearDone(ctx context.Context) (uint, error) {
+	return idb.DeleteWhere(ctx, s.db, storeName, nil, func(_, value safejs.Value) (bool, error) {
+		todo, err := parseTodo(value)
+		return todo.Done, err
+	}, idb.DeleteWhereOptions{})
+}
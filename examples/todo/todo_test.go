@@ -0,0 +1,100 @@
+//go:build js && wasm
+// +build js,wasm
+
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+)
+
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("todo-test-%s-%d", t.Name(), time.Now().UnixNano())
+	store, err := Open(ctx, idb.Global(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = store.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+	return store
+}
+
+func titles(todos []Todo) []string {
+	var titles []string
+	for _, todo := range todos {
+		titles = append(titles, todo.Title)
+	}
+	return titles
+}
+
+func TestTodo(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+
+	var added []Todo
+	for _, title := range []string{"write", "test", "ship"} {
+		todo, err := store.Add(ctx, title)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if todo.ID == 0 || todo.Done || todo.Created.IsZero() {
+			t.Fatalf("Unexpected new to-do: %+v", todo)
+		}
+		added = append(added, todo)
+	}
+	todos, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != len(added) {
+		t.Fatalf("Expected %d to-dos, got %+v", len(added), todos)
+	}
+	for i := range added {
+		if todos[i].ID != added[i].ID || todos[i].Title != added[i].Title || !todos[i].Created.Equal(added[i].Created) {
+			t.Errorf("Expected to-do %+v, got %+v", added[i], todos[i])
+		}
+	}
+
+	if err := store.SetDone(ctx, added[1].ID, true); err != nil {
+		t.Fatal(err)
+	}
+	pending, err := store.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(pending); fmt.Sprint(got) != "[write ship]" {
+		t.Errorf("Unexpected pending to-dos: %v", got)
+	}
+
+	if err := store.SetDone(ctx, 1000, true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	deleted, err := store.ClearDone(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 deleted to-do, got %d", deleted)
+	}
+	todos, err = store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(todos); fmt.Sprint(got) != "[write ship]" {
+		t.Errorf("Unexpected to-dos after clearing: %v", got)
+	}
+}
@@ -0,0 +1,201 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package geoindex queries points by bounding box using Z-order curve keys in a plain IndexedDB index.
+//
+// Store each record's Key in a field, index that field, then query the index with QueryBoundingBox.
+// Keys interleave the bits of the quantized latitude and longitude, so nearby points tend to have nearby keys, and any box is covered by a small number of key ranges.
+package geoindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+// bits is the number of bits of each coordinate in a key. Keys have 2*bits bits, which must fit exactly in a JavaScript number.
+const bits = 26
+
+// DefaultMaxRanges is the number of key ranges QueryBoundingBox covers a box with by default.
+const DefaultMaxRanges = 32
+
+// ErrInvalidPoint is returned for coordinates which are NaN, or outside of -90 to 90 degrees latitude or -180 to 180 degrees longitude.
+var ErrInvalidPoint = errors.New("invalid coordinates")
+
+// Key returns the Z-order key of a point, for storing in an indexed field.
+// Keys are precise to about 30cm of latitude, and about 60cm of longitude at the equator, narrowing toward the poles.
+// Returns an error wrapping ErrInvalidPoint if either coordinate is NaN or out of range.
+func Key(lat, lng float64) (float64, error) {
+	// NaN fails every comparison
+	if !(lat >= -90 && lat <= 90) || !(lng >= -180 && lng <= 180) {
+		return 0, fmt.Errorf("%w: %v, %v", ErrInvalidPoint, lat, lng)
+	}
+	return float64(interleave(quantize(lat, -90, 90), quantize(lng, -180, 180))), nil
+}
+
+// Decode returns the point of a key from Key, at the center of the area the key covers.
+func Decode(key float64) (lat, lng float64) {
+	y, x := deinterleave(uint64(key))
+	return dequantize(y, -90, 90), dequantize(x, -180, 180)
+}
+
+func quantize(value, lower, upper float64) uint32 {
+	const cells = 1 << bits
+	cell := math.Floor((value - lower) / (upper - lower) * cells)
+	return uint32(math.Max(0, math.Min(cells-1, cell)))
+}
+
+func dequantize(cell uint32, lower, upper float64) float64 {
+	return lower + (float64(cell)+0.5)/(1<<bits)*(upper-lower)
+}
+
+// interleave places the bits of y in the odd bits of the key and the bits of x in the even bits.
+func interleave(y, x uint32) uint64 {
+	var key uint64
+	for i := bits - 1; i >= 0; i-- {
+		key = key<<2 | uint64(y>>i&1)<<1 | uint64(x>>i&1)
+	}
+	return key
+}
+
+func deinterleave(key uint64) (y, x uint32) {
+	for i := bits - 1; i >= 0; i-- {
+		y = y<<1 | uint32(key>>(2*i+1)&1)
+		x = x<<1 | uint32(key>>(2*i)&1)
+	}
+	return y, x
+}
+
+// Box is an area between two latitudes and two longitudes, inclusive. Boxes crossing the antimeridian must be split into two.
+// Boxes extending past the poles or the antimeridian cover the points up to them.
+type Box struct {
+	MinLat, MinLng float64
+	MaxLat, MaxLng float64
+}
+
+// Contains returns true if the point is in the box.
+func (b Box) Contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// grid returns the grid coordinates covered by the box, inclusive.
+func (b Box) grid() (minY, minX, maxY, maxX uint32) {
+	return quantize(b.MinLat, -90, 90), quantize(b.MinLng, -180, 180), quantize(b.MaxLat, -90, 90), quantize(b.MaxLng, -180, 180)
+}
+
+// cell is a square area of the grid, covering the keys with the same prefix.
+type cell struct {
+	prefix uint64 // the key bits above level
+	level  int    // number of bits of each coordinate in prefix
+}
+
+// bounds returns the grid coordinates covered by the cell, inclusive.
+func (c cell) bounds() (minY, minX, maxY, maxX uint32) {
+	y, x := deinterleave(c.prefix << (2 * (bits - c.level)))
+	size := uint32(1)<<(bits-c.level) - 1
+	return y, x, y + size, x + size
+}
+
+func (c cell) keyRange() (*idb.KeyRange, error) {
+	shift := 2 * (bits - c.level)
+	lower := c.prefix << shift
+	upper := lower + 1<<shift - 1
+	return idb.NewKeyRangeBoundOf(float64(lower), float64(upper), false, false)
+}
+
+// Ranges covers box with at most maxRanges key ranges, which may include keys just outside of the box. If maxRanges is 0, uses DefaultMaxRanges.
+func Ranges(box Box, maxRanges int) ([]*idb.KeyRange, error) {
+	for _, coordinate := range []float64{box.MinLat, box.MinLng, box.MaxLat, box.MaxLng} {
+		if math.IsNaN(coordinate) {
+			return nil, fmt.Errorf("%w: box %+v", ErrInvalidPoint, box)
+		}
+	}
+	if box.MinLat > box.MaxLat || box.MinLng > box.MaxLng {
+		return nil, errors.New("box minimums must not be greater than its maximums")
+	}
+	if maxRanges <= 0 {
+		maxRanges = DefaultMaxRanges
+	}
+	minY, minX, maxY, maxX := box.grid()
+
+	// split cells partially covering the box, level by level, until splitting again would use too many ranges
+	var covered []cell
+	partial := []cell{{}}
+	for len(partial) > 0 {
+		if partial[0].level == bits || len(covered)+4*len(partial) > maxRanges {
+			covered = append(covered, partial...)
+			break
+		}
+		var next []cell
+		for _, parent := range partial {
+			for quadrant := uint64(0); quadrant < 4; quadrant++ {
+				child := cell{prefix: parent.prefix<<2 | quadrant, level: parent.level + 1}
+				cellMinY, cellMinX, cellMaxY, cellMaxX := child.bounds()
+				switch {
+				case cellMaxY < minY || cellMinY > maxY || cellMaxX < minX || cellMinX > maxX:
+				case cellMinY >= minY && cellMaxY <= maxY && cellMinX >= minX && cellMaxX <= maxX:
+					covered = append(covered, child)
+				default:
+					next = append(next, child)
+				}
+			}
+		}
+		partial = next
+	}
+
+	keyRanges := make([]*idb.KeyRange, 0, len(covered))
+	for _, c := range covered {
+		keyRange, err := c.keyRange()
+		if err != nil {
+			return nil, err
+		}
+		keyRanges = append(keyRanges, keyRange)
+	}
+	return idb.MergeKeyRanges(keyRanges...)
+}
+
+// Result is a record found by QueryBoundingBox.
+type Result struct {
+	PrimaryKey safejs.Value
+	Value      safejs.Value
+}
+
+// QueryBoundingBox returns the records in index whose keys, from Key, are in box, in key order. Points within a key's precision of the box's edges may be included.
+// The box is covered with at most maxRanges key ranges, which are read together, then records outside of the box are filtered out. If maxRanges is 0, uses DefaultMaxRanges.
+func QueryBoundingBox(ctx context.Context, index *idb.Index, box Box, maxRanges int) ([]Result, error) {
+	keyRanges, err := Ranges(box, maxRanges)
+	if err != nil {
+		return nil, err
+	}
+	minY, minX, maxY, maxX := box.grid()
+	var results []Result
+	err = index.OpenCursorMulti(ctx, keyRanges, idb.CursorNext, func(cursor *idb.CursorWithValue) error {
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		keyNumber, err := key.Float()
+		if err != nil {
+			return err
+		}
+		y, x := deinterleave(uint64(keyNumber))
+		if y < minY || y > maxY || x < minX || x > maxX {
+			return nil
+		}
+		primaryKey, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		results = append(results, Result{PrimaryKey: primaryKey, Value: value})
+		return nil
+	})
+	return results, err
+}
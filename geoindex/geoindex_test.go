@@ -0,0 +1,160 @@
+//go:build js && wasm
+// +build js,wasm
+
+package geoindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+func mustKey(t *testing.T, lat, lng float64) float64 {
+	t.Helper()
+	key, err := Key(lat, lng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+	for _, point := range [][2]float64{{0, 0}, {51.5007, -0.1246}, {-33.8568, 151.2153}, {90, 180}, {-90, -180}} {
+		key := mustKey(t, point[0], point[1])
+		if key < 0 || key >= 1<<(2*bits) || key != math.Trunc(key) {
+			t.Errorf("key %v of %v is not an integer within range", key, point)
+		}
+		lat, lng := Decode(key)
+		if math.Abs(lat-point[0]) > 1e-5 || math.Abs(lng-point[1]) > 1e-5 {
+			t.Errorf("expected %v to decode near itself, got %v, %v", point, lat, lng)
+		}
+	}
+	if mustKey(t, 10, 10) >= mustKey(t, 10, 10.0001) || mustKey(t, 10, 10) >= mustKey(t, 10.0001, 10) {
+		t.Error("expected keys to increase with each coordinate within a cell")
+	}
+	for _, point := range [][2]float64{{math.NaN(), 0}, {0, math.NaN()}, {90.1, 0}, {0, -180.1}, {math.Inf(1), 0}} {
+		if _, err := Key(point[0], point[1]); !errors.Is(err, ErrInvalidPoint) {
+			t.Errorf("expected ErrInvalidPoint for %v, got %v", point, err)
+		}
+	}
+	if _, err := Ranges(Box{MinLat: math.NaN(), MaxLat: 1, MaxLng: 1}, 0); !errors.Is(err, ErrInvalidPoint) {
+		t.Errorf("expected ErrInvalidPoint for a box with NaN, got %v", err)
+	}
+}
+
+func TestRanges(t *testing.T) {
+	t.Parallel()
+	box := Box{MinLat: 40, MinLng: -75, MaxLat: 41, MaxLng: -73}
+	for _, maxRanges := range []int{1, 4, 32, 200} {
+		keyRanges, err := Ranges(box, maxRanges)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keyRanges) == 0 || len(keyRanges) > maxRanges {
+			t.Errorf("expected 1 to %d ranges, got %d", maxRanges, len(keyRanges))
+		}
+		// every point in the box must be in a range
+		for _, point := range [][2]float64{{40, -75}, {41, -73}, {40.5, -74}, {40.999, -73.001}} {
+			key, err := safejs.ValueOf(mustKey(t, point[0], point[1]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, keyRange := range keyRanges {
+				includes, err := keyRange.Includes(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				found = found || includes
+			}
+			if !found {
+				t.Errorf("point %v not covered with %d ranges", point, maxRanges)
+			}
+		}
+	}
+	if _, err := Ranges(Box{MinLat: 1, MaxLat: 0}, 0); err == nil {
+		t.Error("expected an error for an inverted box")
+	}
+}
+
+func TestQueryBoundingBox(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	name := fmt.Sprintf("geoindex-test-%s-%d", t.Name(), time.Now().UnixNano())
+	dbReq, err := idb.Global().Open(ctx, name, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		store, err := db.CreateObjectStore("places", idb.ObjectStoreOptions{})
+		if err != nil {
+			return err
+		}
+		_, err = store.CreateIndex("location", idb.NewKeyPath("z"), idb.IndexOptions{})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := dbReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+
+	txn, err := db.Transaction(idb.TransactionReadWrite, "places")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := txn.ObjectStore("places")
+	if err != nil {
+		t.Fatal(err)
+	}
+	places := map[string][2]float64{
+		"new york":    {40.7128, -74.0060},
+		"newark":      {40.7357, -74.1724},
+		"philly":      {39.9526, -75.1652},
+		"london":      {51.5072, -0.1276},
+		"just inside": {40.0001, -74.9999},
+	}
+	for name, point := range places {
+		value := safejs.Safe(js.ValueOf(map[string]interface{}{"z": mustKey(t, point[0], point[1])}))
+		if _, err := store.PutKey(safejs.Safe(js.ValueOf(name)), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index, err := store.Index("location")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := QueryBoundingBox(ctx, index, Box{MinLat: 40, MinLng: -75, MaxLat: 41, MaxLng: -73}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, result := range results {
+		name, err := result.PrimaryKey.String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		found[name] = true
+	}
+	expect := map[string]bool{"new york": true, "newark": true, "just inside": true}
+	if !reflect.DeepEqual(found, expect) {
+		t.Errorf("expected %v, got %v", expect, found)
+	}
+	if err := txn.Await(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hack-pad/safejs"
+)
+
+// AggregateSource is the set of records read by Aggregate: an object store, or one of its indexes.
+type AggregateSource struct {
+	// DB is the database containing the object store.
+	DB *Database
+	// ObjectStore is the name of the object store to read.
+	ObjectStore string
+	// Index optionally names an index on ObjectStore. If set, records are read in index order and keys are index keys.
+	Index string
+	// ChunkSize is the maximum number of records read per transaction. Defaults to 1000.
+	ChunkSize uint
+}
+
+// GroupBy returns the name of the group a record belongs to.
+type GroupBy func(key, value safejs.Value) (string, error)
+
+// Reduce folds a record into its group's accumulated value. The first record of each group receives the zero value of T.
+type Reduce[T any] func(acc T, key, value safejs.Value) (T, error)
+
+// Aggregate streams the records in keyRange through groupBy and reduce, returning the accumulated value for each group.
+// If keyRange is nil, reads all records. If groupBy is nil, all records are reduced into a single group named "".
+//
+// Records are read with a cursor in a series of short read-only transactions, so
+// large ranges can be aggregated without materializing them in memory. When a
+// transaction finishes prematurely, reading resumes after the last reduced record.
+func Aggregate[T any](ctx context.Context, source AggregateSource, keyRange *KeyRange, groupBy GroupBy, reduce Reduce[T]) (map[string]T, error) {
+	if source.DB == nil {
+		return nil, errors.New("aggregate source must have a database")
+	}
+	if reduce == nil {
+		return nil, errors.New("aggregate reduce function must not be nil")
+	}
+	results := make(map[string]T)
+	err := chunkedScan{
+		db:        source.DB,
+		storeName: source.ObjectStore,
+		indexName: source.Index,
+		keyRange:  keyRange,
+		direction: CursorNext,
+		mode:      TransactionReadOnly,
+		chunkSize: source.ChunkSize,
+	}.run(ctx, func(_ *Transaction, cursor *CursorWithValue) error {
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		var group string
+		if groupBy != nil {
+			group, err = groupBy(key, value)
+			if err != nil {
+				return err
+			}
+		}
+		acc, err := reduce(results[group], key, value)
+		if err != nil {
+			return err
+		}
+		results[group] = acc
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
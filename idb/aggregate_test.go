@@ -0,0 +1,99 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func testAggregateDB(tb testing.TB) *Database {
+	tb.Helper()
+	db := testDB(tb, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(tb, err)
+		_, err = store.CreateIndex("category", NewKeyPath("category"), IndexOptions{})
+		assert.NoError(tb, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(tb, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(tb, err)
+	for i, category := range []string{"a", "b", "a", "c", "b", "a"} {
+		_, err := store.AddKey(safejs.Safe(js.ValueOf(i)), safejs.Safe(js.ValueOf(map[string]interface{}{
+			"category": category,
+			"amount":   i * 10,
+		})))
+		assert.NoError(tb, err)
+	}
+	assert.NoError(tb, txn.Await(context.Background()))
+	return db
+}
+
+func sumAmount(acc int, key, value safejs.Value) (int, error) {
+	amount, err := value.Get("amount")
+	if err != nil {
+		return 0, err
+	}
+	amountInt, err := amount.Int()
+	return acc + amountInt, err
+}
+
+func groupByCategory(key, value safejs.Value) (string, error) {
+	category, err := value.Get("category")
+	if err != nil {
+		return "", err
+	}
+	return category.String()
+}
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testAggregateDB(t)
+
+	t.Run("group by and sum", func(t *testing.T) {
+		results, err := Aggregate(ctx, AggregateSource{
+			DB:          db,
+			ObjectStore: "mystore",
+			ChunkSize:   2,
+		}, nil, groupByCategory, sumAmount)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": 70, "b": 50, "c": 30}, results)
+	})
+
+	t.Run("single group over range", func(t *testing.T) {
+		keyRange, err := NewKeyRangeBound(safejs.Safe(js.ValueOf(1)), safejs.Safe(js.ValueOf(3)), false, false)
+		assert.NoError(t, err)
+		results, err := Aggregate(ctx, AggregateSource{
+			DB:          db,
+			ObjectStore: "mystore",
+		}, keyRange, nil, Reduce[int](func(acc int, key, value safejs.Value) (int, error) {
+			return acc + 1, nil
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"": 3}, results)
+	})
+
+	t.Run("index source", func(t *testing.T) {
+		var keys []string
+		results, err := Aggregate(ctx, AggregateSource{
+			DB:          db,
+			ObjectStore: "mystore",
+			Index:       "category",
+			ChunkSize:   1,
+		}, nil, func(key, value safejs.Value) (string, error) {
+			category, err := key.String()
+			keys = append(keys, category)
+			return category, err
+		}, sumAmount)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": 70, "b": 50, "c": 30}, results)
+		assert.Equal(t, []string{"a", "a", "a", "b", "b", "c"}, keys)
+	})
+}
@@ -0,0 +1,50 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import "context"
+
+// Awaitable is a request which can be awaited with AwaitAll. Every request type in this package is Awaitable.
+type Awaitable interface {
+	Listen(ctx context.Context, success, failed func()) error
+	Err() error
+}
+
+// AwaitAll waits for every request in reqs to succeed, then returns nil. Returns the first error as soon as any request fails.
+// Read each request's results with its Result method afterward.
+//
+// Listening starts on every request before waiting on any of them, so no request finishes unobserved while waiting on another.
+// Call AwaitAll before yielding to the event loop after making the requests, since it can't observe requests which already finished.
+func AwaitAll(ctx context.Context, reqs ...Awaitable) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	succeeded := make(chan struct{}, len(reqs))
+	errCh := make(chan error, 1)
+	for _, req := range reqs {
+		req := req
+		err := req.Listen(ctx, func() {
+			succeeded <- struct{}{}
+		}, func() {
+			select {
+			case errCh <- req.Err():
+			default:
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for range reqs {
+		select {
+		case <-succeeded:
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
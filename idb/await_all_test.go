@@ -0,0 +1,60 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestAwaitAll(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		putReq, err := store.PutKeyValue("a", 1)
+		assert.NoError(t, err)
+		addReq, err := store.AddKeyValue("b", 2)
+		assert.NoError(t, err)
+		getReq, err := store.GetValue("a")
+		assert.NoError(t, err)
+		countReq, err := store.Count()
+		assert.NoError(t, err)
+
+		assert.NoError(t, AwaitAll(ctx, putReq, addReq, getReq, countReq))
+		value, err := getReq.Result()
+		assert.NoError(t, err)
+		n, err := value.Int()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		count, err := countReq.Result()
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), count)
+		assert.NoError(t, txn.Await(ctx))
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		first, err := store.AddKeyValue("duplicate", 1)
+		assert.NoError(t, err)
+		second, err := store.AddKeyValue("duplicate", 2)
+		assert.NoError(t, err)
+		after, err := store.GetValue("duplicate")
+		assert.NoError(t, err)
+		assert.ErrorIs(t, AwaitAll(ctx, first, second, after), NewDOMException("ConstraintError"))
+	})
+}
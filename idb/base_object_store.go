@@ -4,6 +4,9 @@
 package idb
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/hack-pad/safejs"
 )
 
@@ -23,13 +26,50 @@ func wrapBaseObjectStore(txn *Transaction, jsObjectStore safejs.Value) *baseObje
 	}
 }
 
+// opError annotates err from the method op of the store or index, made on key if it isn't undefined.
+func (b *baseObjectStore) opError(op string, key safejs.Value, err error) error {
+	return newOpError(op, b.jsObjectStore, key, tryAsDOMException(err))
+}
+
+// indexOpError annotates err from the method op of the store, made on the index named indexName.
+func (b *baseObjectStore) indexOpError(op, indexName string, err error) error {
+	err = b.opError(op, safejs.Undefined(), err)
+	if opErr, ok := err.(*OpError); ok {
+		opErr.Index = indexName
+	}
+	return err
+}
+
+// codec returns the Codec of the store's database, or nil if it has none.
+func (b *baseObjectStore) codec() *Codec {
+	if b.txn == nil || b.txn.db == nil {
+		return nil
+	}
+	return b.txn.db.Codec()
+}
+
+// setName renames the store or index. kind describes the renamed object in errors.
+func (b *baseObjectStore) setName(kind, name string) error {
+	// set with Reflect.set, since setting properties directly doesn't catch exceptions thrown by the setter
+	jsReflect, err := safejs.Global().Get("Reflect")
+	if err != nil {
+		return err
+	}
+	_, err = jsReflect.Call("set", b.jsObjectStore, "name", name)
+	err = tryAsDOMException(err)
+	if errors.Is(err, ErrInvalidState) {
+		return fmt.Errorf("%s can only be renamed during a version upgrade: %w", kind, err)
+	}
+	return err
+}
+
 // Count returns a UintRequest, and, in a separate thread, returns the total number of records in the store or index.
 func (b *baseObjectStore) Count() (*UintRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("count")
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("count", safejs.Undefined(), err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "count", reqValue)
 	return newUintRequest(req), nil
 }
 
@@ -37,9 +77,9 @@ func (b *baseObjectStore) Count() (*UintRequest, error) {
 func (b *baseObjectStore) CountKey(key safejs.Value) (*UintRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("count", key)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("count", key, err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "count", reqValue).withKey(key)
 	return newUintRequest(req), nil
 }
 
@@ -47,33 +87,57 @@ func (b *baseObjectStore) CountKey(key safejs.Value) (*UintRequest, error) {
 func (b *baseObjectStore) CountRange(keyRange *KeyRange) (*UintRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("count", keyRange.jsKeyRange)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("count", safejs.Undefined(), err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "count", reqValue)
 	return newUintRequest(req), nil
 }
 
+// GetAll returns an ArrayRequest that retrieves all objects in the object store or index.
+func (b *baseObjectStore) GetAll() (*ArrayRequest, error) {
+	reqValue, err := b.jsObjectStore.Call("getAll")
+	if err != nil {
+		return nil, b.opError("getAll", safejs.Undefined(), err)
+	}
+	req := wrapRequestOp(b.txn, "getAll", reqValue)
+	return newArrayRequest(req), nil
+}
+
+// GetAllRange returns an ArrayRequest that retrieves all objects in the object store or index matching the specified query, or all of them if query is nil. If maxCount is 0, retrieves all objects matching the query.
+func (b *baseObjectStore) GetAllRange(query *KeyRange, maxCount uint) (*ArrayRequest, error) {
+	args := []interface{}{jsQuery(query)}
+	if maxCount > 0 {
+		args = append(args, maxCount)
+	}
+	reqValue, err := b.jsObjectStore.Call("getAll", args...)
+	if err != nil {
+		return nil, b.opError("getAll", safejs.Undefined(), err)
+	}
+	req := wrapRequestOp(b.txn, "getAll", reqValue)
+	return newArrayRequest(req), nil
+}
+
 // GetAllKeys returns an ArrayRequest that retrieves record keys for all objects in the object store or index.
 func (b *baseObjectStore) GetAllKeys() (*ArrayRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("getAllKeys")
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("getAllKeys", safejs.Undefined(), err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "getAllKeys", reqValue)
 	return newArrayRequest(req), nil
 }
 
-// GetAllKeysRange returns an ArrayRequest that retrieves record keys for all objects in the object store or index matching the specified query. If maxCount is 0, retrieves all objects matching the query.
+// GetAllKeysRange returns an ArrayRequest that retrieves record keys for all objects in the object store or index matching the specified query, or all of them if query is nil. If maxCount is 0, retrieves all objects matching the query.
 func (b *baseObjectStore) GetAllKeysRange(query *KeyRange, maxCount uint) (*ArrayRequest, error) {
-	args := []interface{}{query.jsKeyRange}
+	args := []interface{}{jsQuery(query)}
 	if maxCount > 0 {
 		args = append(args, maxCount)
 	}
 	reqValue, err := b.jsObjectStore.Call("getAllKeys", args...)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("getAllKeys", safejs.Undefined(), err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "getAllKeys", reqValue)
 	return newArrayRequest(req), nil
 }
 
@@ -81,37 +145,37 @@ func (b *baseObjectStore) GetAllKeysRange(query *KeyRange, maxCount uint) (*Arra
 func (b *baseObjectStore) Get(key safejs.Value) (*Request, error) {
 	reqValue, err := b.jsObjectStore.Call("get", key)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("get", key, err)
 	}
-	return wrapRequest(b.txn, reqValue), nil
+	return wrapRequestOp(b.txn, "get", reqValue).withKey(key), nil
 }
 
 // GetKey returns a Request, and, in a separate thread retrieves and returns the record key for the object matching the specified parameter.
 func (b *baseObjectStore) GetKey(value safejs.Value) (*Request, error) {
 	reqValue, err := b.jsObjectStore.Call("getKey", value)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("getKey", value, err)
 	}
-	return wrapRequest(b.txn, reqValue), nil
+	return wrapRequestOp(b.txn, "getKey", reqValue).withKey(value), nil
 }
 
 // OpenCursor returns a CursorWithValueRequest, and, in a separate thread, returns a new CursorWithValue. Used for iterating through an object store or index by primary key with a cursor.
 func (b *baseObjectStore) OpenCursor(direction CursorDirection) (*CursorWithValueRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("openCursor", safejs.Null(), direction.jsValue())
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("openCursor", safejs.Undefined(), err)
 	}
-	req := wrapRequest(b.txn, reqValue)
-	return newCursorWithValueRequest(req), nil
+	req := wrapRequestOp(b.txn, "openCursor", reqValue)
+	return newBatchableCursorWithValueRequest(req, nil, direction), nil
 }
 
 // OpenCursorKey is the same as OpenCursor, but opens a cursor over the given key instead.
 func (b *baseObjectStore) OpenCursorKey(key safejs.Value, direction CursorDirection) (*CursorWithValueRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("openCursor", key, direction.jsValue())
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("openCursor", key, err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "openCursor", reqValue).withKey(key)
 	return newCursorWithValueRequest(req), nil
 }
 
@@ -119,19 +183,19 @@ func (b *baseObjectStore) OpenCursorKey(key safejs.Value, direction CursorDirect
 func (b *baseObjectStore) OpenCursorRange(keyRange *KeyRange, direction CursorDirection) (*CursorWithValueRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("openCursor", keyRange.jsKeyRange, direction.jsValue())
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("openCursor", safejs.Undefined(), err)
 	}
-	req := wrapRequest(b.txn, reqValue)
-	return newCursorWithValueRequest(req), nil
+	req := wrapRequestOp(b.txn, "openCursor", reqValue)
+	return newBatchableCursorWithValueRequest(req, keyRange, direction), nil
 }
 
 // OpenKeyCursor returns a CursorRequest, and, in a separate thread, returns a new Cursor. Used for iterating through all keys in an object store or index.
 func (b *baseObjectStore) OpenKeyCursor(direction CursorDirection) (*CursorRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("openKeyCursor", safejs.Null(), direction.jsValue())
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("openKeyCursor", safejs.Undefined(), err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "openKeyCursor", reqValue)
 	return newCursorRequest(req), nil
 }
 
@@ -139,9 +203,9 @@ func (b *baseObjectStore) OpenKeyCursor(direction CursorDirection) (*CursorReque
 func (b *baseObjectStore) OpenKeyCursorKey(key safejs.Value, direction CursorDirection) (*CursorRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("openKeyCursor", key, direction.jsValue())
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("openKeyCursor", key, err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "openKeyCursor", reqValue).withKey(key)
 	return newCursorRequest(req), nil
 }
 
@@ -149,8 +213,8 @@ func (b *baseObjectStore) OpenKeyCursorKey(key safejs.Value, direction CursorDir
 func (b *baseObjectStore) OpenKeyCursorRange(keyRange *KeyRange, direction CursorDirection) (*CursorRequest, error) {
 	reqValue, err := b.jsObjectStore.Call("openKeyCursor", keyRange.jsKeyRange, direction.jsValue())
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, b.opError("openKeyCursor", safejs.Undefined(), err)
 	}
-	req := wrapRequest(b.txn, reqValue)
+	req := wrapRequestOp(b.txn, "openKeyCursor", reqValue)
 	return newCursorRequest(req), nil
 }
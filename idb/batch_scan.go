@@ -0,0 +1,186 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hack-pad/safejs"
+)
+
+// BatchScan reads the records in keyRange in ascending key order, calling fn with up to batchSize keys and values at a time. A nil keyRange reads all records.
+// Each batch is read with getAll and getAllKeys requests instead of stepping a cursor, so a scan crosses into JavaScript a few times per batch rather than once per record.
+//
+// Return ErrCursorStopIter from fn to stop early. fn should return without waiting on other work, otherwise the transaction commits before the next batch is requested.
+func (o *ObjectStore) BatchScan(ctx context.Context, keyRange *KeyRange, batchSize uint, fn func(keys, values []safejs.Value) error) error {
+	return o.base.batchScan(ctx, keyRange, batchSize, false, fn)
+}
+
+// BatchScan reads the records in keyRange in ascending index key order, calling fn with up to batchSize primary keys and values at a time. See ObjectStore.BatchScan.
+//
+// Index keys may repeat, so continuing after each batch takes a couple of extra requests to find where the previous batch stopped.
+func (i *Index) BatchScan(ctx context.Context, keyRange *KeyRange, batchSize uint, fn func(primaryKeys, values []safejs.Value) error) error {
+	return i.base.batchScan(ctx, keyRange, batchSize, true, fn)
+}
+
+func (b *baseObjectStore) batchScan(ctx context.Context, keyRange *KeyRange, batchSize uint, isIndex bool, fn func(keys, values []safejs.Value) error) error {
+	if batchSize == 0 {
+		return errors.New("batch size must be at least 1")
+	}
+	bounds, err := parseRangeBounds(keyRange)
+	if err != nil {
+		return err
+	}
+	// skip counts the records at the start of keyRange that were already passed to fn. Only indexes have duplicate keys to skip.
+	var skip uint
+	sizer := newBatchSizer(batchSize)
+	for {
+		count := skip + sizer.next()
+		keysReq, err := b.getAllCount("getAllKeys", keyRange, count)
+		if err != nil {
+			return err
+		}
+		valuesReq, err := b.getAllCount("getAll", keyRange, count)
+		if err != nil {
+			return err
+		}
+		keys, err := keysReq.Await(ctx)
+		if err != nil {
+			return err
+		}
+		values, err := valuesReq.Await(ctx)
+		if err != nil {
+			return err
+		}
+		if uint(len(keys)) <= skip {
+			return nil
+		}
+		err = fn(keys[skip:], values[skip:])
+		if err != nil {
+			if err == ErrCursorStopIter {
+				return nil
+			}
+			return err
+		}
+		if uint(len(keys)) < count {
+			return nil
+		}
+
+		if !isIndex {
+			next, ok, err := bounds.from(keys[len(keys)-1], true)
+			if err != nil || !ok {
+				return err
+			}
+			bounds = next
+			keyRange, err = bounds.keyRange()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		lastKey, err := b.keyAt(ctx, keyRange, count-1)
+		if err != nil {
+			return err
+		}
+		skip, err = b.countThrough(ctx, bounds, lastKey, count)
+		if err != nil {
+			return err
+		}
+		next, ok, err := bounds.from(lastKey, false)
+		if err != nil || !ok {
+			return err
+		}
+		bounds = next
+		keyRange, err = bounds.keyRange()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// getAllCount calls the getAll or getAllKeys method with up to count results. A nil keyRange matches all records.
+func (b *baseObjectStore) getAllCount(method string, keyRange *KeyRange, count uint) (*ArrayRequest, error) {
+	reqValue, err := b.jsObjectStore.Call(method, jsQuery(keyRange), count)
+	if err != nil {
+		return nil, tryAsDOMException(err)
+	}
+	req := wrapRequestOp(b.txn, method, reqValue)
+	return newArrayRequest(req), nil
+}
+
+// keyAt returns the key of the record at the given position in keyRange.
+func (b *baseObjectStore) keyAt(ctx context.Context, keyRange *KeyRange, position uint) (safejs.Value, error) {
+	reqValue, err := b.jsObjectStore.Call("openKeyCursor", jsQuery(keyRange))
+	if err != nil {
+		return safejs.Undefined(), tryAsDOMException(err)
+	}
+	req := newCursorRequest(wrapRequestOp(b.txn, "openKeyCursor", reqValue))
+	cursor, err := req.Await(ctx)
+	if err == nil && cursor != nil && position > 0 {
+		err = cursor.Advance(position)
+		if err == nil {
+			cursor, err = req.Await(ctx)
+		}
+	}
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	if cursor == nil {
+		return safejs.Undefined(), errors.New("records were deleted during batch scan")
+	}
+	return cursor.Key()
+}
+
+// countThrough returns the number of records with key equal to lastKey among the first count records in bounds, where lastKey is the key of the last of those records.
+func (b *baseObjectStore) countThrough(ctx context.Context, bounds rangeBounds, lastKey safejs.Value, count uint) (uint, error) {
+	if bounds.hasLower {
+		cmp, err := compareKeys(bounds.lower, lastKey)
+		if err != nil {
+			return 0, err
+		}
+		if cmp == 0 {
+			return count, nil
+		}
+	}
+	below := bounds
+	below.upper, below.hasUpper, below.upperOpen = lastKey, true, true
+	belowRange, err := below.keyRange()
+	if err != nil {
+		return 0, err
+	}
+	reqValue, err := b.jsObjectStore.Call("count", jsQuery(belowRange))
+	if err != nil {
+		return 0, tryAsDOMException(err)
+	}
+	belowCount, err := newUintRequest(wrapRequestOp(b.txn, "count", reqValue)).Await(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return count - belowCount, nil
+}
+
+// from returns bounds starting at key instead of its lower bound. Returns false if the new range is empty.
+func (b rangeBounds) from(key safejs.Value, open bool) (rangeBounds, bool, error) {
+	if b.hasUpper {
+		cmp, err := compareKeys(key, b.upper)
+		if err != nil {
+			return b, false, err
+		}
+		if cmp > 0 || (cmp == 0 && (open || b.upperOpen)) {
+			return b, false, nil
+		}
+	}
+	b.lower, b.hasLower, b.lowerOpen = key, true, open
+	return b, true, nil
+}
+
+// jsQuery returns the JS query for keyRange, where null matches all keys.
+func jsQuery(keyRange *KeyRange) safejs.Value {
+	if keyRange == nil {
+		return safejs.Null()
+	}
+	return keyRange.jsKeyRange
+}
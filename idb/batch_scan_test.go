@@ -0,0 +1,106 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestBatchScan(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("myindex", NewKeyPath("group"), IndexOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := store.PutKeyValue(i, map[string]interface{}{"n": i, "group": i % 3})
+		assert.NoError(t, err)
+	}
+	index, err := store.Index("myindex")
+	assert.NoError(t, err)
+
+	type batch struct {
+		Keys   []int
+		Values []int
+	}
+	collect := func(batches *[]batch, stopAfter int) func(keys, values []safejs.Value) error {
+		return func(keys, values []safejs.Value) error {
+			var b batch
+			for i := range keys {
+				key, err := keys[i].Int()
+				if err != nil {
+					return err
+				}
+				n, err := values[i].Get("n")
+				if err != nil {
+					return err
+				}
+				value, err := n.Int()
+				if err != nil {
+					return err
+				}
+				b.Keys = append(b.Keys, key)
+				b.Values = append(b.Values, value)
+			}
+			*batches = append(*batches, b)
+			if len(*batches) == stopAfter {
+				return ErrCursorStopIter
+			}
+			return nil
+		}
+	}
+
+	var batches []batch
+	assert.NoError(t, store.BatchScan(ctx, nil, 4, collect(&batches, 0)))
+	assert.Equal(t, []batch{
+		{Keys: []int{0, 1, 2, 3}, Values: []int{0, 1, 2, 3}},
+		{Keys: []int{4, 5, 6, 7}, Values: []int{4, 5, 6, 7}},
+		{Keys: []int{8, 9}, Values: []int{8, 9}},
+	}, batches)
+
+	batches = nil
+	keyRange, err := NewKeyRangeBoundOf(2, 7, true, false)
+	assert.NoError(t, err)
+	assert.NoError(t, store.BatchScan(ctx, keyRange, 5, collect(&batches, 0)))
+	assert.Equal(t, []batch{
+		{Keys: []int{3, 4, 5, 6, 7}, Values: []int{3, 4, 5, 6, 7}},
+	}, batches)
+
+	batches = nil
+	assert.NoError(t, store.BatchScan(ctx, nil, 3, collect(&batches, 2)))
+	assert.Equal(t, 2, len(batches))
+
+	batches = nil
+	assert.NoError(t, index.BatchScan(ctx, nil, 2, collect(&batches, 0)))
+	assert.Equal(t, []batch{
+		{Keys: []int{0, 3}, Values: []int{0, 3}},
+		{Keys: []int{6, 9}, Values: []int{6, 9}},
+		{Keys: []int{1, 4}, Values: []int{1, 4}},
+		{Keys: []int{7, 2}, Values: []int{7, 2}},
+		{Keys: []int{5, 8}, Values: []int{5, 8}},
+	}, batches)
+
+	batches = nil
+	keyRange, err = NewKeyRangeUpperBoundOf(1, false)
+	assert.NoError(t, err)
+	assert.NoError(t, index.BatchScan(ctx, keyRange, 3, collect(&batches, 0)))
+	assert.Equal(t, []batch{
+		{Keys: []int{0, 3, 6}, Values: []int{0, 3, 6}},
+		{Keys: []int{9, 1, 4}, Values: []int{9, 1, 4}},
+		{Keys: []int{7}, Values: []int{7}},
+	}, batches)
+
+	assert.Error(t, store.BatchScan(ctx, nil, 0, collect(&batches, 0)))
+}
@@ -0,0 +1,83 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"github.com/hack-pad/safejs"
+)
+
+// BinaryKey copies b into a new ArrayBuffer for use as a key. Binary keys sort after all other key types, and compare byte by byte.
+func BinaryKey(b []byte) (safejs.Value, error) {
+	array, err := BytesValue(b)
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	return array.Get("buffer")
+}
+
+// BinaryKeyBytes copies a binary key, like those returned from a cursor or GetAllKeys, into a new byte slice.
+func BinaryKeyBytes(key safejs.Value) ([]byte, error) {
+	return BytesFromValue(key)
+}
+
+// NewKeyRangeBinaryBound is the same as NewKeyRangeBound, but with binary key bounds.
+func NewKeyRangeBinaryBound(lower, upper []byte, lowerOpen, upperOpen bool) (*KeyRange, error) {
+	lowerKey, err := BinaryKey(lower)
+	if err != nil {
+		return nil, err
+	}
+	upperKey, err := BinaryKey(upper)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeBound(lowerKey, upperKey, lowerOpen, upperOpen)
+}
+
+// NewKeyRangeBinaryLowerBound is the same as NewKeyRangeLowerBound, but with a binary key bound.
+func NewKeyRangeBinaryLowerBound(lower []byte, open bool) (*KeyRange, error) {
+	lowerKey, err := BinaryKey(lower)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeLowerBound(lowerKey, open)
+}
+
+// NewKeyRangeBinaryUpperBound is the same as NewKeyRangeUpperBound, but with a binary key bound.
+func NewKeyRangeBinaryUpperBound(upper []byte, open bool) (*KeyRange, error) {
+	upperKey, err := BinaryKey(upper)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeUpperBound(upperKey, open)
+}
+
+// NewKeyRangeBinaryOnly is the same as NewKeyRangeOnly, but with a binary key.
+func NewKeyRangeBinaryOnly(only []byte) (*KeyRange, error) {
+	key, err := BinaryKey(only)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeOnly(key)
+}
+
+// NewKeyRangeBinaryPrefix creates a new key range containing every binary key starting with prefix.
+func NewKeyRangeBinaryPrefix(prefix []byte) (*KeyRange, error) {
+	upper := binaryPrefixEnd(prefix)
+	if upper == nil {
+		return NewKeyRangeBinaryLowerBound(prefix, false)
+	}
+	return NewKeyRangeBinaryBound(prefix, upper, false, true)
+}
+
+// binaryPrefixEnd returns the smallest key greater than every key starting with prefix, or nil if there is none.
+func binaryPrefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestBinaryPrefixEnd(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []byte{0x01, 0x03}, binaryPrefixEnd([]byte{0x01, 0x02}))
+	assert.Equal(t, []byte{0x02}, binaryPrefixEnd([]byte{0x01, 0xff}))
+	assert.Equal(t, []byte(nil), binaryPrefixEnd([]byte{0xff, 0xff}))
+	assert.Equal(t, []byte(nil), binaryPrefixEnd(nil))
+}
+
+func TestObjectStoreBinaryKeys(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+
+	for _, key := range [][]byte{{0x01}, {0x01, 0x00}, {0x01, 0xff}, {0x02}, {0xff}} {
+		_, err := store.AddBinaryKey(key, safejs.Safe(js.ValueOf(len(key))))
+		assert.NoError(t, err)
+	}
+	_, err = store.PutBinaryKey([]byte{0x02}, safejs.Safe(js.ValueOf("two")))
+	assert.NoError(t, err)
+	_, err = store.DeleteBinaryKey([]byte{0xff})
+	assert.NoError(t, err)
+
+	getReq, err := store.GetBinaryKey([]byte{0x02})
+	assert.NoError(t, err)
+	value, err := getReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, safejs.Safe(js.ValueOf("two")), value)
+
+	binaryKeys := func(keyRange *KeyRange) [][]byte {
+		req, err := store.GetAllKeysRange(keyRange, 0)
+		assert.NoError(t, err)
+		keys, err := req.Await(ctx)
+		assert.NoError(t, err)
+		var result [][]byte
+		for _, key := range keys {
+			b, err := BinaryKeyBytes(key)
+			assert.NoError(t, err)
+			result = append(result, b)
+		}
+		return result
+	}
+
+	prefixRange, err := NewKeyRangeBinaryPrefix([]byte{0x01})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{0x01}, {0x01, 0x00}, {0x01, 0xff}}, binaryKeys(prefixRange))
+
+	boundRange, err := NewKeyRangeBinaryBound([]byte{0x01, 0x00}, []byte{0x02}, true, false)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{0x01, 0xff}, {0x02}}, binaryKeys(boundRange))
+
+	lowerRange, err := NewKeyRangeBinaryLowerBound([]byte{0x01, 0xff}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{0x01, 0xff}, {0x02}}, binaryKeys(lowerRange))
+
+	upperRange, err := NewKeyRangeBinaryUpperBound([]byte{0x01, 0x00}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{0x01}}, binaryKeys(upperRange))
+
+	onlyRange, err := NewKeyRangeBinaryOnly([]byte{0x02})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{0x02}}, binaryKeys(onlyRange))
+}
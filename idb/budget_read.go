@@ -0,0 +1,147 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	defaultMemoryBudget = 16 << 20
+	defaultProbeSize    = 100
+	// maxEstimateDepth bounds how deep estimateSize walks into nested values, which may contain cycles.
+	maxEstimateDepth = 32
+)
+
+// BudgetOptions contains options for GetAllBudget.
+type BudgetOptions struct {
+	// MemoryBudget is the approximate number of bytes of records to read at once. Defaults to 16 MiB.
+	MemoryBudget uint64
+	// ProbeSize is the number of records read first to estimate the size of a record. Defaults to 100.
+	ProbeSize uint
+}
+
+// GetAllBudget reads the records in keyRange in ascending key order, calling fn with their keys and values. A nil keyRange reads all records.
+// It first reads up to options.ProbeSize records to estimate the size of a record. If the whole range is estimated to fit in options.MemoryBudget, fn is called once with every record, like with GetAllRange.
+// Otherwise, records are read in batches which fit the budget, like with BatchScan, so reading a huge store doesn't exhaust memory.
+//
+// Return ErrCursorStopIter from fn to stop early. fn should return without waiting on other work, otherwise the transaction commits before the next batch is requested.
+func (o *ObjectStore) GetAllBudget(ctx context.Context, keyRange *KeyRange, options BudgetOptions, fn func(keys, values []safejs.Value) error) error {
+	return o.base.getAllBudget(ctx, keyRange, options, false, fn)
+}
+
+// GetAllBudget reads the records in keyRange in ascending index key order, calling fn with their primary keys and values. See ObjectStore.GetAllBudget.
+func (i *Index) GetAllBudget(ctx context.Context, keyRange *KeyRange, options BudgetOptions, fn func(primaryKeys, values []safejs.Value) error) error {
+	return i.base.getAllBudget(ctx, keyRange, options, true, fn)
+}
+
+func (b *baseObjectStore) getAllBudget(ctx context.Context, keyRange *KeyRange, options BudgetOptions, isIndex bool, fn func(keys, values []safejs.Value) error) error {
+	budget := options.MemoryBudget
+	if budget == 0 {
+		budget = defaultMemoryBudget
+	}
+	probeSize := options.ProbeSize
+	if probeSize == 0 {
+		probeSize = defaultProbeSize
+	}
+	probeReq, err := b.getAllCount("getAll", keyRange, probeSize)
+	if err != nil {
+		return err
+	}
+	probe, err := probeReq.Await(ctx)
+	if err != nil || len(probe) == 0 {
+		return err
+	}
+	var probeBytes uint64
+	for _, value := range probe {
+		size, err := estimateSize(value, 0)
+		if err != nil {
+			return err
+		}
+		probeBytes += size
+	}
+	recordSize := probeBytes/uint64(len(probe)) + 1
+
+	count := uint(len(probe))
+	if count == probeSize {
+		reqValue, err := b.jsObjectStore.Call("count", jsQuery(keyRange))
+		if err != nil {
+			return tryAsDOMException(err)
+		}
+		count, err = newUintRequest(wrapRequestOp(b.txn, "count", reqValue)).Await(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	batchSize := count
+	if uint64(count)*recordSize > budget {
+		batchSize = uint(budget / recordSize)
+		if batchSize == 0 {
+			batchSize = 1
+		}
+	}
+	return b.batchScan(ctx, keyRange, batchSize, isIndex, fn)
+}
+
+// estimateSize returns the approximate number of bytes value takes in memory.
+func estimateSize(value safejs.Value, depth int) (uint64, error) {
+	valueType, ok := safeType(value)
+	if !ok {
+		return 8, nil // BigInt
+	}
+	switch valueType {
+	case safejs.TypeString:
+		s, err := value.String()
+		return 2 * uint64(len(s)), err
+	case safejs.TypeNumber:
+		return 8, nil
+	case safejs.TypeBoolean:
+		return 4, nil
+	case safejs.TypeObject:
+	default:
+		return 0, nil
+	}
+	if depth >= maxEstimateDepth {
+		return 0, nil
+	}
+
+	isArrayBuffer, err := value.InstanceOf(jsArrayBuffer)
+	if err != nil {
+		return 0, err
+	}
+	isView, err := jsArrayBuffer.Call("isView", value)
+	if err != nil {
+		return 0, err
+	}
+	if isBinary, err := isView.Truthy(); isArrayBuffer || (err == nil && isBinary) {
+		byteLength, err := value.Get("byteLength")
+		if err != nil {
+			return 0, err
+		}
+		n, err := byteLength.Int()
+		return uint64(n), err
+	}
+
+	keys, err := jsObject.Call("keys", value)
+	if err != nil {
+		return 0, err
+	}
+	size := uint64(8)
+	err = iterArray(keys, func(_ int, key safejs.Value) (bool, error) {
+		keyString, err := key.String()
+		if err != nil {
+			return false, err
+		}
+		property, err := value.Get(keyString)
+		if err != nil {
+			return false, err
+		}
+		propertySize, err := estimateSize(property, depth+1)
+		size += 2*uint64(len(keyString)) + propertySize
+		return true, err
+	})
+	return size, err
+}
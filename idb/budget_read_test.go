@@ -0,0 +1,59 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestObjectStoreGetAllBudget(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := store.PutKeyValue(i, strings.Repeat("x", 1000)) // about 2000 bytes each
+		assert.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		description string
+		options     BudgetOptions
+		expectSizes []int
+	}{
+		{"fits the budget", BudgetOptions{ProbeSize: 3}, []int{10}},
+		{"probe reads everything", BudgetOptions{}, []int{10}},
+		{"exceeds the budget", BudgetOptions{MemoryBudget: 5000, ProbeSize: 3}, []int{2, 2, 2, 2, 2}},
+		{"smaller than a record", BudgetOptions{MemoryBudget: 10}, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+	} {
+		var sizes []int
+		var keys []int
+		err := store.GetAllBudget(ctx, nil, tc.options, func(batchKeys, values []safejs.Value) error {
+			sizes = append(sizes, len(values))
+			for _, key := range batchKeys {
+				n, err := key.Int()
+				assert.NoError(t, err)
+				keys = append(keys, n)
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		if !assert.Equal(t, tc.expectSizes, sizes) {
+			t.Log(tc.description)
+		}
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, keys)
+	}
+	assert.NoError(t, txn.Await(ctx))
+}
@@ -0,0 +1,86 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hack-pad/safejs"
+)
+
+// GetMany returns the values of the records with the given keys, in the same order as keys. Records which don't exist have an undefined value.
+// All get requests are made before any are awaited, so the transaction can't commit automatically between them.
+func (o *ObjectStore) GetMany(ctx context.Context, keys []safejs.Value) ([]safejs.Value, error) {
+	requests := make([]*Request, 0, len(keys))
+	for _, key := range keys {
+		req, err := o.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	values := make([]safejs.Value, 0, len(keys))
+	for _, req := range requests {
+		value, err := req.Await(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// PutMany stores each of values in the object store, then waits for the transaction to complete. If keys is nil, the store's key path or key generator provides the keys.
+// Otherwise, keys must be the same length as values, and each value is stored with the key at the same position.
+//
+// All put requests are made at once and only the transaction is awaited, so either every value is stored or the transaction aborts. No more requests can be made in the transaction after PutMany returns.
+func (o *ObjectStore) PutMany(ctx context.Context, keys, values []safejs.Value) error {
+	return o.writeMany(ctx, "put", keys, values)
+}
+
+// AddMany is the same as PutMany, but fails and aborts the transaction if a record already exists for any of the keys.
+func (o *ObjectStore) AddMany(ctx context.Context, keys, values []safejs.Value) error {
+	return o.writeMany(ctx, "add", keys, values)
+}
+
+// DeleteMany deletes the records with the given keys, then waits for the transaction to complete. Keys may also be key ranges.
+// No more requests can be made in the transaction after DeleteMany returns.
+func (o *ObjectStore) DeleteMany(ctx context.Context, keys []safejs.Value) error {
+	return o.bulkWrite(ctx, len(keys), func(i int) error {
+		_, err := o.base.jsObjectStore.Call("delete", keys[i])
+		return tryAsDOMException(err)
+	})
+}
+
+func (o *ObjectStore) writeMany(ctx context.Context, method string, keys, values []safejs.Value) error {
+	if keys != nil && len(keys) != len(values) {
+		return errors.New("number of keys must match number of values")
+	}
+	return o.bulkWrite(ctx, len(values), func(i int) error {
+		var err error
+		if keys == nil {
+			_, err = o.base.jsObjectStore.Call(method, values[i])
+		} else {
+			_, err = o.base.jsObjectStore.Call(method, values[i], keys[i])
+		}
+		return tryAsDOMException(err)
+	})
+}
+
+// bulkWrite makes n requests with request, then waits for the transaction to complete. Aborts the transaction if a request can't be made, so none of the writes are committed.
+func (o *ObjectStore) bulkWrite(ctx context.Context, n int, request func(i int) error) error {
+	txn, err := o.Transaction()
+	if err != nil {
+		return err
+	}
+	txn.trackFinished()
+	for i := 0; i < n; i++ {
+		if err := request(i); err != nil {
+			_ = txn.Abort()
+			return tryAsDOMException(err)
+		}
+	}
+	return txn.AwaitComplete(ctx)
+}
@@ -0,0 +1,112 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestObjectStoreGetMany(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := store.PutKeyValue(i, i*10)
+		assert.NoError(t, err)
+	}
+
+	var keys []safejs.Value
+	for _, key := range []int{2, 5, 0} {
+		jsKey, err := ValueOf(key)
+		assert.NoError(t, err)
+		keys = append(keys, jsKey)
+	}
+	values, err := store.GetMany(ctx, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(values))
+	n, err := values[0].Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 20, n)
+	assert.Equal(t, true, values[1].IsUndefined())
+	n, err = values[2].Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.NoError(t, txn.Await(ctx))
+}
+
+func TestObjectStoreBulkWrites(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("inline", ObjectStoreOptions{KeyPath: NewKeyPath("id")})
+		assert.NoError(t, err)
+	})
+	jsValues := func(values ...interface{}) []safejs.Value {
+		var jsValues []safejs.Value
+		for _, value := range values {
+			jsValue, err := ValueOf(value)
+			assert.NoError(t, err)
+			jsValues = append(jsValues, jsValue)
+		}
+		return jsValues
+	}
+	storeKeys := func(name string) []interface{} {
+		txn, err := db.Transaction(TransactionReadOnly, name)
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore(name)
+		assert.NoError(t, err)
+		req, err := store.GetAllKeys()
+		assert.NoError(t, err)
+		keys, err := req.Await(ctx)
+		assert.NoError(t, err)
+		n := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			i, err := key.Int()
+			assert.NoError(t, err)
+			n = append(n, i)
+		}
+		return n
+	}
+	writeTxn := func(name string) *ObjectStore {
+		txn, err := db.Transaction(TransactionReadWrite, name)
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore(name)
+		assert.NoError(t, err)
+		return store
+	}
+
+	assert.NoError(t, writeTxn("mystore").PutMany(ctx, jsValues(1, 2, 3), jsValues("a", "b", "c")))
+	assert.Equal(t, []interface{}{1, 2, 3}, storeKeys("mystore"))
+
+	err := writeTxn("mystore").PutMany(ctx, jsValues(4), jsValues("d", "e"))
+	assert.Error(t, err)
+
+	err = writeTxn("mystore").AddMany(ctx, jsValues(4, 2), jsValues("d", "b"))
+	assert.ErrorIs(t, err, ErrConstraint)
+	assert.Equal(t, []interface{}{1, 2, 3}, storeKeys("mystore"))
+
+	err = writeTxn("mystore").PutMany(ctx, jsValues(4, true), jsValues("d", "e"))
+	assert.ErrorIs(t, err, ErrData)
+	assert.Equal(t, []interface{}{1, 2, 3}, storeKeys("mystore"))
+
+	assert.NoError(t, writeTxn("mystore").DeleteMany(ctx, jsValues(1, 3)))
+	assert.Equal(t, []interface{}{2}, storeKeys("mystore"))
+
+	values := jsValues(map[string]interface{}{"id": 5}, map[string]interface{}{"id": 4})
+	assert.NoError(t, writeTxn("inline").AddMany(ctx, nil, values))
+	assert.Equal(t, []interface{}{4, 5}, storeKeys("inline"))
+}
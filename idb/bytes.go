@@ -0,0 +1,129 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsArrayBuffer safejs.Value
+	jsUint8Array  safejs.Value
+)
+
+func init() {
+	var err error
+	jsArrayBuffer, err = safejs.Global().Get("ArrayBuffer")
+	if err != nil {
+		panic(err)
+	}
+	jsUint8Array, err = safejs.Global().Get("Uint8Array")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// BytesValue copies b into a new JS Uint8Array.
+func BytesValue(b []byte) (safejs.Value, error) {
+	array, err := jsUint8Array.New(len(b))
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	if _, err := safejs.CopyBytesToJS(array, b); err != nil {
+		return safejs.Undefined(), err
+	}
+	return array, nil
+}
+
+// BytesFromValue copies the contents of a JS ArrayBuffer, typed array, or DataView into a new byte slice.
+func BytesFromValue(value safejs.Value) ([]byte, error) {
+	isArrayBuffer, err := value.InstanceOf(jsArrayBuffer)
+	if err != nil {
+		return nil, err
+	}
+	isView := false
+	if !isArrayBuffer {
+		isViewValue, err := jsArrayBuffer.Call("isView", value)
+		if err != nil {
+			return nil, err
+		}
+		isView, err = isViewValue.Truthy()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var array safejs.Value
+	switch {
+	case isArrayBuffer:
+		array, err = jsUint8Array.New(value)
+	case isView:
+		var properties []safejs.Value
+		properties, err = getProperties(value, "buffer", "byteOffset", "byteLength")
+		if err == nil {
+			array, err = jsUint8Array.New(properties[0], properties[1], properties[2])
+		}
+	default:
+		return nil, fmt.Errorf("expected an ArrayBuffer or typed array, got %s", value.Type())
+	}
+	if err != nil {
+		return nil, err
+	}
+	length, err := array.Length()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, length)
+	_, err = safejs.CopyBytesToGo(b, array)
+	return b, err
+}
+
+func getProperties(value safejs.Value, names ...string) ([]safejs.Value, error) {
+	properties := make([]safejs.Value, 0, len(names))
+	for _, name := range names {
+		property, err := value.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, property)
+	}
+	return properties, nil
+}
+
+// BytesRequest is a Request that retrieves a binary value as a byte slice
+type BytesRequest struct {
+	*Request
+}
+
+func newBytesRequest(req *Request) *BytesRequest {
+	return &BytesRequest{req}
+}
+
+// Result returns the result of the request. If the request failed and the result is not available, an error is returned. Returns nil if no record was found.
+func (b *BytesRequest) Result() ([]byte, error) {
+	result, err := b.Request.Result()
+	if err != nil {
+		return nil, err
+	}
+	return bytesFromResult(result)
+}
+
+// Await waits for success or failure, then returns the results. Returns nil if no record was found.
+func (b *BytesRequest) Await(ctx context.Context) ([]byte, error) {
+	result, err := b.Request.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bytesFromResult(result)
+}
+
+func bytesFromResult(result safejs.Value) ([]byte, error) {
+	if result.IsUndefined() {
+		return nil, nil
+	}
+	return BytesFromValue(result)
+}
@@ -0,0 +1,75 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestBytesFromValue(t *testing.T) {
+	t.Parallel()
+	array, err := BytesValue([]byte{1, 2, 3, 4})
+	assert.NoError(t, err)
+	buffer, err := array.Get("buffer")
+	assert.NoError(t, err)
+	subarray, err := array.Call("subarray", 1, 3)
+	assert.NoError(t, err)
+	jsDataView, err := safejs.Global().Get("DataView")
+	assert.NoError(t, err)
+	dataView, err := jsDataView.New(buffer, 2)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		name        string
+		value       safejs.Value
+		expectBytes []byte
+		expectErr   bool
+	}{
+		{name: "Uint8Array", value: array, expectBytes: []byte{1, 2, 3, 4}},
+		{name: "ArrayBuffer", value: buffer, expectBytes: []byte{1, 2, 3, 4}},
+		{name: "subarray", value: subarray, expectBytes: []byte{2, 3}},
+		{name: "DataView", value: dataView, expectBytes: []byte{3, 4}},
+		{name: "string", value: safejs.Safe(js.ValueOf("abc")), expectErr: true},
+	} {
+		b, err := BytesFromValue(tc.value)
+		if tc.expectErr {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectBytes, b)
+	}
+}
+
+func TestObjectStoreBytes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+
+	_, err = store.PutBytes(safejs.Safe(js.ValueOf("some id")), []byte("some data"))
+	assert.NoError(t, err)
+	getReq, err := store.GetBytes(safejs.Safe(js.ValueOf("some id")))
+	assert.NoError(t, err)
+	b, err := getReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("some data"), b)
+
+	getReq, err = store.GetBytes(safejs.Safe(js.ValueOf("missing id")))
+	assert.NoError(t, err)
+	b, err = getReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(nil), b)
+}
@@ -0,0 +1,396 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsObject         safejs.Value
+	jsObjectToString safejs.Value
+	jsBigInt         safejs.Value
+)
+
+func init() {
+	var err error
+	jsObject, err = safejs.Global().Get("Object")
+	if err != nil {
+		panic(err)
+	}
+	objectPrototype, err := jsObject.Get("prototype")
+	if err != nil {
+		panic(err)
+	}
+	jsObjectToString, err = objectPrototype.Get("toString")
+	if err != nil {
+		panic(err)
+	}
+	jsBigInt, err = safejs.Global().Get("BigInt")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TypeAdapter converts between a Go type and a kind of JS value which a Codec doesn't convert on its own, like JS Maps, Sets, or BigInts.
+type TypeAdapter struct {
+	// GoType is the type of the Go values converted by ToJS.
+	GoType reflect.Type
+	// JSTag is the tag of the JS values converted by FromJS, as in "[object Tag]" from Object.prototype.toString. For example, "Map" or "BigInt".
+	JSTag string
+	// ToJS converts a Go value of GoType into a JS value. Convert nested values with c.
+	ToJS func(c *Codec, value interface{}) (safejs.Value, error)
+	// FromJS converts a JS value tagged JSTag into a Go value, usually of GoType. Convert nested values with c.
+	FromJS func(c *Codec, value safejs.Value) (interface{}, error)
+}
+
+// Codec converts between native Go values and JS values, extended with TypeAdapters. A nil Codec converts like ValueOf and has no adapters.
+//
+// Without adapters, JS values convert into nil, bool, float64, string, time.Time (from a Date), []byte (from an ArrayBuffer or Uint8Array), []interface{} (from an Array), or map[string]interface{} (from a plain Object).
+type Codec struct {
+	byGoType map[reflect.Type]TypeAdapter
+	byJSTag  map[string]TypeAdapter
+}
+
+// NewCodec returns a Codec using adapters for the types they convert. Later adapters replace earlier ones for the same Go type or JS tag.
+func NewCodec(adapters ...TypeAdapter) *Codec {
+	c := &Codec{
+		byGoType: make(map[reflect.Type]TypeAdapter, len(adapters)),
+		byJSTag:  make(map[string]TypeAdapter, len(adapters)),
+	}
+	for _, adapter := range adapters {
+		if adapter.GoType != nil && adapter.ToJS != nil {
+			c.byGoType[adapter.GoType] = adapter
+		}
+		if adapter.JSTag != "" && adapter.FromJS != nil {
+			c.byJSTag[adapter.JSTag] = adapter
+		}
+	}
+	return c
+}
+
+func (c *Codec) goAdapter(goType reflect.Type) (TypeAdapter, bool) {
+	if c == nil {
+		return TypeAdapter{}, false
+	}
+	adapter, ok := c.byGoType[goType]
+	return adapter, ok
+}
+
+func (c *Codec) jsAdapter(tag string) (TypeAdapter, bool) {
+	if c == nil {
+		return TypeAdapter{}, false
+	}
+	adapter, ok := c.byJSTag[tag]
+	return adapter, ok
+}
+
+// ValueOf converts a native Go value into a JS value, like the package-level ValueOf, using c's adapters for the types they convert.
+func (c *Codec) ValueOf(value interface{}) (safejs.Value, error) {
+	plain, err := c.plainValueOf(reflect.ValueOf(value))
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	return safejs.ValueOf(plain)
+}
+
+// GoValueOf converts a JS value, like a value read from an object store, into a native Go value, using c's adapters for the JS values they convert.
+func (c *Codec) GoValueOf(value safejs.Value) (interface{}, error) {
+	if value.IsUndefined() || value.IsNull() {
+		return nil, nil
+	}
+	valueType, ok := safeType(value)
+	switch {
+	case !ok: // BigInts have no type in syscall/js, so go by their tag
+	case valueType == safejs.TypeBoolean:
+		return value.Bool()
+	case valueType == safejs.TypeNumber:
+		return value.Float()
+	case valueType == safejs.TypeString:
+		return value.String()
+	case valueType == safejs.TypeSymbol, valueType == safejs.TypeFunction:
+		return nil, fmt.Errorf("unsupported JS value type %s", valueType)
+	}
+
+	tag, err := jsTag(value)
+	if err != nil {
+		return nil, err
+	}
+	if adapter, ok := c.jsAdapter(tag); ok {
+		return adapter.FromJS(c, value)
+	}
+	switch tag {
+	case "Date":
+		return KeyTime(value)
+	case "ArrayBuffer", "Uint8Array":
+		return BytesFromValue(value)
+	case "Array":
+		var values []interface{}
+		err := iterArray(value, func(_ int, elem safejs.Value) (bool, error) {
+			goElem, err := c.GoValueOf(elem)
+			values = append(values, goElem)
+			return true, err
+		})
+		return values, err
+	case "Object":
+		keys, err := jsObject.Call("keys", value)
+		if err != nil {
+			return nil, err
+		}
+		object := make(map[string]interface{})
+		err = iterArray(keys, func(_ int, key safejs.Value) (bool, error) {
+			keyString, err := key.String()
+			if err != nil {
+				return false, err
+			}
+			elem, err := value.Get(keyString)
+			if err != nil {
+				return false, err
+			}
+			object[keyString], err = c.GoValueOf(elem)
+			return true, err
+		})
+		return object, err
+	default:
+		return nil, fmt.Errorf("unsupported JS value %s: add a TypeAdapter for it", tag)
+	}
+}
+
+// safeType returns the type of value, or false if syscall/js has none for it, like for BigInts.
+func safeType(value safejs.Value) (valueType safejs.Type, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return value.Type(), true
+}
+
+// jsTag returns the tag of value from Object.prototype.toString, like "Map" for "[object Map]".
+func jsTag(value safejs.Value) (string, error) {
+	tagValue, err := jsObjectToString.Call("call", value)
+	if err != nil {
+		return "", err
+	}
+	tag, err := tagValue.String()
+	if err != nil {
+		return "", err
+	}
+	const prefix, suffix = "[object ", "]"
+	if len(tag) < len(prefix)+len(suffix) {
+		return "", fmt.Errorf("unexpected JS value tag %q", tag)
+	}
+	return tag[len(prefix) : len(tag)-len(suffix)], nil
+}
+
+// MapAdapter converts between Go map[interface{}]interface{} values and JS Maps, which allow keys of any type.
+// Keys read from JS must convert into comparable Go values, so they can't be Arrays or Objects.
+func MapAdapter() TypeAdapter {
+	return TypeAdapter{
+		GoType: reflect.TypeOf(map[interface{}]interface{}(nil)),
+		JSTag:  "Map",
+		ToJS: func(c *Codec, value interface{}) (safejs.Value, error) {
+			jsMap, err := safejs.Global().Get("Map")
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			m, err := jsMap.New()
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			for key, elem := range value.(map[interface{}]interface{}) {
+				jsKey, err := c.ValueOf(key)
+				if err != nil {
+					return safejs.Undefined(), err
+				}
+				jsElem, err := c.ValueOf(elem)
+				if err != nil {
+					return safejs.Undefined(), err
+				}
+				if _, err := m.Call("set", jsKey, jsElem); err != nil {
+					return safejs.Undefined(), err
+				}
+			}
+			return m, nil
+		},
+		FromJS: func(c *Codec, value safejs.Value) (interface{}, error) {
+			m := make(map[interface{}]interface{})
+			err := iterJSIterable(value, func(entry safejs.Value) error {
+				key, err := entry.Index(0)
+				if err != nil {
+					return err
+				}
+				elem, err := entry.Index(1)
+				if err != nil {
+					return err
+				}
+				goKey, err := comparableGoValueOf(c, key)
+				if err != nil {
+					return err
+				}
+				m[goKey], err = c.GoValueOf(elem)
+				return err
+			})
+			return m, err
+		},
+	}
+}
+
+// SetAdapter converts between Go map[interface{}]struct{} values and JS Sets.
+// Elements read from JS must convert into comparable Go values, so they can't be Arrays or Objects.
+func SetAdapter() TypeAdapter {
+	return TypeAdapter{
+		GoType: reflect.TypeOf(map[interface{}]struct{}(nil)),
+		JSTag:  "Set",
+		ToJS: func(c *Codec, value interface{}) (safejs.Value, error) {
+			jsSet, err := safejs.Global().Get("Set")
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			set, err := jsSet.New()
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			for elem := range value.(map[interface{}]struct{}) {
+				jsElem, err := c.ValueOf(elem)
+				if err != nil {
+					return safejs.Undefined(), err
+				}
+				if _, err := set.Call("add", jsElem); err != nil {
+					return safejs.Undefined(), err
+				}
+			}
+			return set, nil
+		},
+		FromJS: func(c *Codec, value safejs.Value) (interface{}, error) {
+			set := make(map[interface{}]struct{})
+			err := iterJSIterable(value, func(elem safejs.Value) error {
+				goElem, err := comparableGoValueOf(c, elem)
+				set[goElem] = struct{}{}
+				return err
+			})
+			return set, err
+		},
+	}
+}
+
+// BigIntAdapter converts between Go *big.Int values and JS BigInts, so integers beyond 2^53 keep their precision.
+func BigIntAdapter() TypeAdapter {
+	return TypeAdapter{
+		GoType: reflect.TypeOf((*big.Int)(nil)),
+		JSTag:  "BigInt",
+		ToJS: func(_ *Codec, value interface{}) (safejs.Value, error) {
+			n := value.(*big.Int)
+			if n == nil {
+				return safejs.Null(), nil
+			}
+			return jsBigInt.Invoke(n.String())
+		},
+		FromJS: func(_ *Codec, value safejs.Value) (interface{}, error) {
+			// BigInts have no type in syscall/js, so their methods can't be called. Convert with String instead.
+			s, err := safejs.Global().Call("String", value)
+			if err != nil {
+				return nil, err
+			}
+			str, err := s.String()
+			if err != nil {
+				return nil, err
+			}
+			n, ok := new(big.Int).SetString(str, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid BigInt %q", str)
+			}
+			return n, nil
+		},
+	}
+}
+
+// TypedArrayElem is the element type of a JS typed array supported by TypedArrayAdapter.
+type TypedArrayElem interface {
+	int8 | int16 | uint16 | int32 | uint32 | float32 | float64
+}
+
+// TypedArrayAdapter converts between Go slices of T and the JS typed arrays of T, like []float64 and Float64Array.
+// Byte slices are always converted to and from Uint8Arrays, so there's no adapter for them.
+func TypedArrayAdapter[T TypedArrayElem]() TypeAdapter {
+	var tag string
+	switch any(T(0)).(type) {
+	case int8:
+		tag = "Int8Array"
+	case int16:
+		tag = "Int16Array"
+	case uint16:
+		tag = "Uint16Array"
+	case int32:
+		tag = "Int32Array"
+	case uint32:
+		tag = "Uint32Array"
+	case float32:
+		tag = "Float32Array"
+	case float64:
+		tag = "Float64Array"
+	}
+	return TypeAdapter{
+		GoType: reflect.TypeOf([]T(nil)),
+		JSTag:  tag,
+		ToJS: func(_ *Codec, value interface{}) (safejs.Value, error) {
+			var buf bytes.Buffer
+			// typed arrays use the platform's byte order, which is little-endian for WebAssembly hosts
+			if err := binary.Write(&buf, binary.LittleEndian, value.([]T)); err != nil {
+				return safejs.Undefined(), err
+			}
+			array, err := BytesValue(buf.Bytes())
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			buffer, err := array.Get("buffer")
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			constructor, err := safejs.Global().Get(tag)
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			return constructor.New(buffer)
+		},
+		FromJS: func(_ *Codec, value safejs.Value) (interface{}, error) {
+			b, err := BytesFromValue(value)
+			if err != nil {
+				return nil, err
+			}
+			elems := make([]T, len(b)/binary.Size(T(0)))
+			err = binary.Read(bytes.NewReader(b), binary.LittleEndian, elems)
+			return elems, err
+		},
+	}
+}
+
+// comparableGoValueOf converts value like GoValueOf, failing if the result can't be a Go map key.
+func comparableGoValueOf(c *Codec, value safejs.Value) (interface{}, error) {
+	goValue, err := c.GoValueOf(value)
+	if err != nil {
+		return nil, err
+	}
+	if goValue != nil && !reflect.TypeOf(goValue).Comparable() {
+		return nil, fmt.Errorf("unsupported key type %T: keys must be comparable", goValue)
+	}
+	return goValue, nil
+}
+
+// iterJSIterable calls visit with each value of a JS iterable, like a Map's entries or a Set's elements.
+func iterJSIterable(iterable safejs.Value, visit func(safejs.Value) error) error {
+	array, err := jsArray.Call("from", iterable)
+	if err != nil {
+		return err
+	}
+	return iterArray(array, func(_ int, value safejs.Value) (bool, error) {
+		return true, visit(value)
+	})
+}
@@ -0,0 +1,87 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	codec := NewCodec(MapAdapter(), SetAdapter(), BigIntAdapter(), TypedArrayAdapter[float64](), TypedArrayAdapter[int32]())
+	db.SetCodec(codec)
+	assert.Equal(t, codec, db.Codec())
+
+	huge, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	assert.Equal(t, true, ok)
+	date := time.UnixMilli(1700000000000).UTC()
+	value := map[string]interface{}{
+		"map":     map[interface{}]interface{}{1.0: "one", "two": 2.0},
+		"set":     map[interface{}]struct{}{"a": {}, 3.0: {}},
+		"bigint":  huge,
+		"floats":  []float64{1.5, -2.25},
+		"ints":    []int32{-1, 2, 3},
+		"bytes":   []byte{1, 2},
+		"date":    date,
+		"list":    []interface{}{"x", true, nil},
+		"nested":  map[string]interface{}{"n": 4.0},
+		"nothing": nil,
+	}
+
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue("key", value)
+	assert.NoError(t, err)
+	req, err := store.GetValue("key")
+	assert.NoError(t, err)
+	result, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	goValue, err := db.Codec().GoValueOf(result)
+	assert.NoError(t, err)
+	object := goValue.(map[string]interface{})
+	assert.Equal(t, 0, huge.Cmp(object["bigint"].(*big.Int)))
+	delete(object, "bigint")
+	assert.Equal(t, date, object["date"].(time.Time).UTC())
+	delete(object, "date")
+	delete(value, "bigint")
+	delete(value, "date")
+	assert.Equal(t, value, object)
+}
+
+func TestCodecUnsupported(t *testing.T) {
+	t.Parallel()
+	_, err := (*Codec)(nil).ValueOf(map[interface{}]interface{}{1: 2})
+	assert.Error(t, err)
+
+	jsMap, err := NewCodec(MapAdapter()).ValueOf(map[interface{}]interface{}{"a": 1})
+	assert.NoError(t, err)
+	_, err = (*Codec)(nil).GoValueOf(jsMap)
+	assert.Error(t, err)
+
+	jsSet, err := NewCodec(SetAdapter()).ValueOf(map[interface{}]struct{}{"a": {}})
+	assert.NoError(t, err)
+	array, err := ValueOf([]interface{}{jsSet})
+	assert.NoError(t, err)
+	// arrays aren't comparable, so they can't be map keys
+	jsMap, err = NewCodec(MapAdapter()).ValueOf(map[interface{}]interface{}{"a": 1})
+	assert.NoError(t, err)
+	_, err = jsMap.Call("set", array, 1)
+	assert.NoError(t, err)
+	_, err = NewCodec(MapAdapter(), SetAdapter()).GoValueOf(jsMap)
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hack-pad/safejs"
+)
+
+// CopyOptions contains options for CopyStore.
+type CopyOptions struct {
+	// KeyRange optionally limits the copied records. If nil, copies all records.
+	KeyRange *KeyRange
+	// Filter optionally selects which records to copy. Records are copied when it returns true.
+	Filter func(key, value safejs.Value) (bool, error)
+	// Transform optionally replaces each record's value before it's written to the destination.
+	Transform func(key, value safejs.Value) (safejs.Value, error)
+	// ChunkSize is the maximum number of records copied per transaction. Defaults to 1000.
+	ChunkSize uint
+}
+
+// CopyStore copies records from the object store named src into the object store named dst, overwriting records with the same keys.
+// Both stores must already exist, so CopyStore can run outside of a version upgrade.
+//
+// Records are copied in chunks, each in its own read-write transaction. If a transaction finishes prematurely, copying resumes after the last copied record.
+// If dst uses in-line keys, the copied values must contain their keys at dst's key path. Otherwise, records keep their keys from src.
+// Returns the number of records copied.
+func CopyStore(ctx context.Context, db *Database, src, dst string, options CopyOptions) (uint, error) {
+	if src == dst {
+		return 0, errors.New("source and destination object stores must differ")
+	}
+	keyPath, err := storeKeyPath(db, dst)
+	if err != nil {
+		return 0, err
+	}
+
+	var copied uint
+	err = chunkedScan{
+		db:          db,
+		storeName:   src,
+		extraStores: []string{dst},
+		keyRange:    options.KeyRange,
+		direction:   CursorNext,
+		mode:        TransactionReadWrite,
+		chunkSize:   options.ChunkSize,
+	}.run(ctx, func(txn *Transaction, cursor *CursorWithValue) error {
+		key, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		if options.Filter != nil {
+			include, err := options.Filter(key, value)
+			if err != nil || !include {
+				return err
+			}
+		}
+		if options.Transform != nil {
+			value, err = options.Transform(key, value)
+			if err != nil {
+				return err
+			}
+		}
+		store, err := txn.ObjectStore(dst)
+		if err != nil {
+			return err
+		}
+		if keyPath.IsZero() {
+			_, err = store.PutKey(key, value)
+		} else {
+			_, err = store.Put(value)
+		}
+		if err != nil {
+			return err
+		}
+		copied++
+		return nil
+	})
+	return copied, err
+}
+
+// storeKeyPath returns the key path of the object store with the given name.
+func storeKeyPath(db *Database, storeName string) (KeyPath, error) {
+	txn, err := db.Transaction(TransactionReadOnly, storeName)
+	if err != nil {
+		return KeyPath{}, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return KeyPath{}, err
+	}
+	return store.TypedKeyPath()
+}
@@ -0,0 +1,100 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestCopyStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("src", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("dst", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("inline", ObjectStoreOptions{KeyPath: NewKeyPath("id")})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "src")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("src")
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := store.PutKeyValue(i, map[string]interface{}{"id": i, "n": i})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, txn.Await(ctx))
+
+	readN := func(storeName string) []int {
+		txn, err := db.Transaction(TransactionReadOnly, storeName)
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore(storeName)
+		assert.NoError(t, err)
+		req, err := store.OpenCursor(CursorNext)
+		assert.NoError(t, err)
+		var values []int
+		assert.NoError(t, req.Iter(ctx, func(cursor *CursorWithValue) error {
+			value, err := cursor.Value()
+			if err != nil {
+				return err
+			}
+			n, err := value.Get("n")
+			if err != nil {
+				return err
+			}
+			i, err := n.Int()
+			values = append(values, i)
+			return err
+		}))
+		return values
+	}
+
+	t.Run("filter and transform", func(t *testing.T) {
+		keyRange, err := NewKeyRangeLowerBound(safejs.Safe(js.ValueOf(2)), false)
+		assert.NoError(t, err)
+		expired := false
+		copied, err := CopyStore(ctx, db, "src", "dst", CopyOptions{
+			KeyRange:  keyRange,
+			ChunkSize: 2,
+			Filter: func(key, value safejs.Value) (bool, error) {
+				i, err := key.Int()
+				return i%2 == 0, err
+			},
+			Transform: func(key, value safejs.Value) (safejs.Value, error) {
+				i, err := key.Int()
+				if err != nil {
+					return safejs.Undefined(), err
+				}
+				if i == 6 && !expired {
+					expired = true
+					time.Sleep(50 * time.Millisecond) // yield so the transaction commits
+				}
+				return ValueOf(map[string]interface{}{"n": i * 10})
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(4), copied)
+		assert.Equal(t, []int{20, 40, 60, 80}, readN("dst"))
+	})
+
+	t.Run("in-line keys", func(t *testing.T) {
+		copied, err := CopyStore(ctx, db, "src", "inline", CopyOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(10), copied)
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, readN("inline"))
+	})
+
+	t.Run("same store", func(t *testing.T) {
+		_, err := CopyStore(ctx, db, "src", "src", CopyOptions{})
+		assert.Error(t, err)
+	})
+}
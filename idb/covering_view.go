@@ -0,0 +1,286 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hack-pad/safejs"
+)
+
+// CoveringView maintains a compact projection of each record in ObjectStore inside a parallel object store, ViewStore, keyed identically.
+// Reading the projection avoids deserializing large records when only a few fields are needed, emulating a covering index.
+//
+// Write through the view's Put, PutKey, Delete, and Clear methods to update both stores in the same transaction. The transaction must include both stores.
+// Auto-incrementing object stores are not supported, since a record's key must be known before its projection is written.
+type CoveringView struct {
+	// ObjectStore is the name of the object store holding the full records.
+	ObjectStore string
+	// ViewStore is the name of the object store holding the projections.
+	ViewStore string
+	// Fields are the key paths copied from each record into its projection, like "title" or "author.name".
+	// When ObjectStore uses in-line keys, the fields of its key path are always copied as well.
+	Fields []string
+}
+
+// Validate returns an error if the view's configuration is invalid.
+func (v CoveringView) Validate() error {
+	switch {
+	case v.ObjectStore == "":
+		return errors.New("covering view object store name must be set")
+	case v.ViewStore == "":
+		return errors.New("covering view store name must be set")
+	case v.ObjectStore == v.ViewStore:
+		return errors.New("covering view store must differ from its object store")
+	case len(v.Fields) == 0:
+		return errors.New("covering view must project at least one field")
+	}
+	for _, field := range v.Fields {
+		if field == "" || !isValidKeyPathString(field) {
+			return fmt.Errorf("invalid covering view field %q: must be identifiers separated by periods", field)
+		}
+	}
+	return nil
+}
+
+// CreateObjectStores creates both the object store and its view store during a version upgrade.
+// The view store shares the object store's key path so projections are keyed identically.
+func (v CoveringView) CreateObjectStores(db *Database, options ObjectStoreOptions) (store, view *ObjectStore, err error) {
+	if err := v.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if options.AutoIncrement {
+		return nil, nil, errors.New("covering views do not support auto-incrementing object stores")
+	}
+	if options.KeyPath.Kind() == KeyPathSingle && options.KeyPath.Path() == "" {
+		return nil, nil, errors.New("covering views do not support an empty key path")
+	}
+	store, err = db.CreateObjectStore(v.ObjectStore, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	view, err = db.CreateObjectStore(v.ViewStore, ObjectStoreOptions{KeyPath: options.KeyPath})
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, view, nil
+}
+
+// Project returns a new object containing only the view's fields from value. Fields missing from value are omitted.
+func (v CoveringView) Project(value safejs.Value) (safejs.Value, error) {
+	return v.project(value, KeyPath{})
+}
+
+// project is like Project, but also copies the fields of keyPath
+func (v CoveringView) project(value safejs.Value, keyPath KeyPath) (safejs.Value, error) {
+	projection, err := newJSObject()
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	for _, field := range append(keyPath.Paths(), v.Fields...) {
+		fieldValue, ok, err := getValuePath(value, field)
+		if err != nil {
+			return safejs.Undefined(), err
+		}
+		if !ok {
+			continue
+		}
+		if err := setValuePath(projection, field, fieldValue); err != nil {
+			return safejs.Undefined(), err
+		}
+	}
+	return projection, nil
+}
+
+// Put stores value in the object store and its projection in the view store. The object store must use in-line keys.
+// Returns the object store's request, which resolves to the record's key.
+func (v CoveringView) Put(txn *Transaction, value safejs.Value) (*Request, error) {
+	store, view, err := v.objectStores(txn)
+	if err != nil {
+		return nil, err
+	}
+	keyPath, err := store.TypedKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	projection, err := v.project(value, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	req, err := store.Put(value)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := view.Put(projection); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// PutKey is the same as Put, but includes the key to use to identify the record. The object store must use out-of-line keys.
+func (v CoveringView) PutKey(txn *Transaction, key, value safejs.Value) (*Request, error) {
+	store, view, err := v.objectStores(txn)
+	if err != nil {
+		return nil, err
+	}
+	projection, err := v.Project(value)
+	if err != nil {
+		return nil, err
+	}
+	req, err := store.PutKey(key, value)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := view.PutKey(key, projection); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Delete deletes the record selected by key from both the object store and the view store.
+func (v CoveringView) Delete(txn *Transaction, key safejs.Value) (*AckRequest, error) {
+	store, view, err := v.objectStores(txn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.Delete(key); err != nil {
+		return nil, err
+	}
+	return view.Delete(key)
+}
+
+// Clear deletes all records from both the object store and the view store.
+func (v CoveringView) Clear(txn *Transaction) (*AckRequest, error) {
+	store, view, err := v.objectStores(txn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.Clear(); err != nil {
+		return nil, err
+	}
+	return view.Clear()
+}
+
+// Rebuild regenerates the view store from the object store, for example after changing Fields.
+// Projections are written in chunks of separate transactions, so readers may observe a partially rebuilt view until Rebuild returns.
+func (v CoveringView) Rebuild(ctx context.Context, db *Database) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	txn, err := db.Transaction(TransactionReadWrite, v.ObjectStore, v.ViewStore)
+	if err != nil {
+		return err
+	}
+	store, view, err := v.objectStores(txn)
+	if err != nil {
+		return err
+	}
+	keyPath, err := store.TypedKeyPath()
+	if err != nil {
+		return err
+	}
+	if _, err := view.Clear(); err != nil {
+		return err
+	}
+	if err := txn.Await(ctx); err != nil {
+		return err
+	}
+
+	return chunkedScan{
+		db:          db,
+		storeName:   v.ObjectStore,
+		extraStores: []string{v.ViewStore},
+		direction:   CursorNext,
+		mode:        TransactionReadWrite,
+	}.run(ctx, func(txn *Transaction, cursor *CursorWithValue) error {
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		projection, err := v.project(value, keyPath)
+		if err != nil {
+			return err
+		}
+		view, err := txn.ObjectStore(v.ViewStore)
+		if err != nil {
+			return err
+		}
+		if !keyPath.IsZero() {
+			_, err = view.Put(projection)
+			return err
+		}
+		key, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		_, err = view.PutKey(key, projection)
+		return err
+	})
+}
+
+func (v CoveringView) objectStores(txn *Transaction) (store, view *ObjectStore, err error) {
+	if err := v.Validate(); err != nil {
+		return nil, nil, err
+	}
+	store, err = txn.ObjectStore(v.ObjectStore)
+	if err != nil {
+		return nil, nil, err
+	}
+	view, err = txn.ObjectStore(v.ViewStore)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, view, nil
+}
+
+func newJSObject() (safejs.Value, error) {
+	jsObject, err := safejs.Global().Get("Object")
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	return jsObject.New()
+}
+
+// getValuePath returns the value at the period-separated path inside value. Returns false if the path does not exist.
+func getValuePath(value safejs.Value, path string) (safejs.Value, bool, error) {
+	for _, name := range strings.Split(path, ".") {
+		if value.Type() != safejs.TypeObject {
+			return safejs.Undefined(), false, nil
+		}
+		var err error
+		value, err = value.Get(name)
+		if err != nil {
+			return safejs.Undefined(), false, err
+		}
+	}
+	if value.IsUndefined() {
+		return safejs.Undefined(), false, nil
+	}
+	return value, true, nil
+}
+
+// setValuePath sets the period-separated path inside object to value, creating intermediate objects as needed.
+func setValuePath(object safejs.Value, path string, value safejs.Value) error {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		child, err := object.Get(name)
+		if err != nil {
+			return err
+		}
+		if child.Type() != safejs.TypeObject {
+			child, err = newJSObject()
+			if err != nil {
+				return err
+			}
+			if err := object.Set(name, child); err != nil {
+				return err
+			}
+		}
+		object = child
+	}
+	return object.Set(names[len(names)-1], value)
+}
@@ -0,0 +1,142 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestCoveringViewValidate(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name      string
+		view      CoveringView
+		expectErr bool
+	}{
+		{name: "valid", view: CoveringView{ObjectStore: "docs", ViewStore: "docs_view", Fields: []string{"title", "author.name"}}},
+		{name: "missing object store", view: CoveringView{ViewStore: "docs_view", Fields: []string{"title"}}, expectErr: true},
+		{name: "missing view store", view: CoveringView{ObjectStore: "docs", Fields: []string{"title"}}, expectErr: true},
+		{name: "same store", view: CoveringView{ObjectStore: "docs", ViewStore: "docs", Fields: []string{"title"}}, expectErr: true},
+		{name: "no fields", view: CoveringView{ObjectStore: "docs", ViewStore: "docs_view"}, expectErr: true},
+		{name: "empty field", view: CoveringView{ObjectStore: "docs", ViewStore: "docs_view", Fields: []string{""}}, expectErr: true},
+		{name: "invalid field", view: CoveringView{ObjectStore: "docs", ViewStore: "docs_view", Fields: []string{"a-b"}}, expectErr: true},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.view.Validate()
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCoveringViewProject(t *testing.T) {
+	t.Parallel()
+	view := CoveringView{ObjectStore: "docs", ViewStore: "docs_view", Fields: []string{"title", "author.name", "missing"}}
+	projection, err := view.Project(safejs.Safe(js.ValueOf(map[string]interface{}{
+		"title": "Notes",
+		"body":  "a very large body",
+		"author": map[string]interface{}{
+			"name":  "Ada",
+			"email": "ada@example.com",
+		},
+	})))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"title":"Notes","author":{"name":"Ada"}}`, jsonString(t, projection))
+}
+
+func TestCoveringView(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	view := CoveringView{ObjectStore: "docs", ViewStore: "docs_view", Fields: []string{"title"}}
+	db := testDB(t, func(db *Database) {
+		_, _, err := view.CreateObjectStores(db, ObjectStoreOptions{KeyPath: NewKeyPath("id")})
+		assert.NoError(t, err)
+	})
+
+	txn, err := db.Transaction(TransactionReadWrite, view.ObjectStore, view.ViewStore)
+	assert.NoError(t, err)
+	for _, doc := range []map[string]interface{}{
+		{"id": 1, "title": "first", "body": "1111"},
+		{"id": 2, "title": "second", "body": "2222"},
+	} {
+		_, err := view.Put(txn, safejs.Safe(js.ValueOf(doc)))
+		assert.NoError(t, err)
+	}
+	_, err = view.Delete(txn, safejs.Safe(js.ValueOf(1)))
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	assert.Equal(t, []string{`{"id":2,"title":"second"}`}, viewRecords(t, db, view.ViewStore))
+
+	view.Fields = []string{"body"}
+	assert.NoError(t, view.Rebuild(ctx, db))
+	assert.Equal(t, []string{`{"id":2,"body":"2222"}`}, viewRecords(t, db, view.ViewStore))
+}
+
+func TestCoveringViewOutOfLineKeys(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	view := CoveringView{ObjectStore: "docs", ViewStore: "docs_view", Fields: []string{"title"}}
+	db := testDB(t, func(db *Database) {
+		_, _, err := view.CreateObjectStores(db, ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, _, err = CoveringView{ObjectStore: "a", ViewStore: "b", Fields: []string{"title"}}.CreateObjectStores(db, ObjectStoreOptions{AutoIncrement: true})
+		assert.Error(t, err)
+	})
+
+	txn, err := db.Transaction(TransactionReadWrite, view.ObjectStore, view.ViewStore)
+	assert.NoError(t, err)
+	_, err = view.PutKey(txn, safejs.Safe(js.ValueOf("doc")), safejs.Safe(js.ValueOf(map[string]interface{}{
+		"title": "Notes",
+		"body":  "a very large body",
+	})))
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	assert.Equal(t, []string{`{"title":"Notes"}`}, viewRecords(t, db, view.ViewStore))
+	assert.NoError(t, view.Rebuild(ctx, db))
+	assert.Equal(t, []string{`{"title":"Notes"}`}, viewRecords(t, db, view.ViewStore))
+}
+
+func viewRecords(tb testing.TB, db *Database, storeName string) []string {
+	tb.Helper()
+	txn, err := db.Transaction(TransactionReadOnly, storeName)
+	assert.NoError(tb, err)
+	store, err := txn.ObjectStore(storeName)
+	assert.NoError(tb, err)
+	req, err := store.OpenCursor(CursorNext)
+	assert.NoError(tb, err)
+	var records []string
+	err = req.Iter(context.Background(), func(cursor *CursorWithValue) error {
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		records = append(records, jsonString(tb, value))
+		return nil
+	})
+	assert.NoError(tb, err)
+	return records
+}
+
+func jsonString(tb testing.TB, value safejs.Value) string {
+	tb.Helper()
+	jsJSON, err := safejs.Global().Get("JSON")
+	assert.NoError(tb, err)
+	str, err := jsJSON.Call("stringify", value)
+	assert.NoError(tb, err)
+	s, err := str.String()
+	assert.NoError(tb, err)
+	return s
+}
@@ -8,18 +8,7 @@ import (
 	"github.com/hack-pad/safejs"
 )
 
-var (
-	jsObjectStore        safejs.Value
-	cursorDirectionCache jscache.Strings
-)
-
-func init() {
-	var err error
-	jsObjectStore, err = safejs.Global().Get("IDBObjectStore")
-	if err != nil {
-		panic(err)
-	}
-}
+var cursorDirectionCache jscache.Strings
 
 // CursorDirection is the direction of traversal of the cursor
 type CursorDirection int
@@ -85,9 +74,9 @@ func (c *Cursor) Source() (objectStore *ObjectStore, index *Index, err error) {
 	if err != nil {
 		return
 	}
-	if isInstance, _ := jsSource.InstanceOf(jsObjectStore); isInstance {
+	if isInstance, _ := jsSource.InstanceOf(jsIDBObjectStore()); isInstance {
 		objectStore = wrapObjectStore(c.txn, jsSource)
-	} else if isInstance, _ := jsSource.InstanceOf(jsIDBIndex); isInstance {
+	} else if isInstance, _ := jsSource.InstanceOf(jsIDBIndex()); isInstance {
 		index = wrapIndex(c.txn, jsSource)
 	}
 	return
@@ -130,41 +119,66 @@ func (c *Cursor) Unwrap() safejs.Value {
 	return c.jsCursor
 }
 
+// opError annotates err from the cursor method op, made on key if it isn't undefined.
+func (c *Cursor) opError(op string, key safejs.Value, err error) error {
+	err = tryAsDOMException(err)
+	if err == nil {
+		return nil
+	}
+	source, sourceErr := c.jsCursor.Get("source")
+	if sourceErr != nil {
+		return err
+	}
+	return newOpError(op, source, key, err)
+}
+
 // Advance sets the number of times a cursor should move its position forward.
 func (c *Cursor) Advance(count uint) error {
 	c.iterated = true
 	_, err := c.jsCursor.Call("advance", count)
-	return tryAsDOMException(err)
+	if err == nil {
+		c.txn.resumeCursor(c.jsCursor)
+	}
+	return c.opError("advance", safejs.Undefined(), err)
 }
 
 // Continue advances the cursor to the next position along its direction.
 func (c *Cursor) Continue() error {
 	c.iterated = true
 	_, err := c.jsCursor.Call("continue")
-	return tryAsDOMException(err)
+	if err == nil {
+		c.txn.resumeCursor(c.jsCursor)
+	}
+	return c.opError("continue", safejs.Undefined(), err)
 }
 
 // ContinueKey advances the cursor to the next position along its direction.
 func (c *Cursor) ContinueKey(key safejs.Value) error {
 	c.iterated = true
 	_, err := c.jsCursor.Call("continue", key)
-	return tryAsDOMException(err)
+	if err == nil {
+		c.txn.resumeCursor(c.jsCursor)
+	}
+	return c.opError("continue", key, err)
 }
 
 // ContinuePrimaryKey sets the cursor to the given index key and primary key given as arguments. Returns an error if the source is not an index.
 func (c *Cursor) ContinuePrimaryKey(key, primaryKey safejs.Value) error {
 	c.iterated = true
 	_, err := c.jsCursor.Call("continuePrimaryKey", key, primaryKey)
-	return tryAsDOMException(err)
+	if err == nil {
+		c.txn.resumeCursor(c.jsCursor)
+	}
+	return c.opError("continuePrimaryKey", key, err)
 }
 
 // Delete returns an AckRequest, and, in a separate thread, deletes the record at the cursor's position, without changing the cursor's position. This can be used to delete specific records.
 func (c *Cursor) Delete() (*AckRequest, error) {
 	reqValue, err := c.jsCursor.Call("delete")
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, c.opError("delete", safejs.Undefined(), err)
 	}
-	req := wrapRequest(c.txn, reqValue)
+	req := wrapWriteRequest(c.txn, "delete", reqValue)
 	return newAckRequest(req), nil
 }
 
@@ -172,9 +186,9 @@ func (c *Cursor) Delete() (*AckRequest, error) {
 func (c *Cursor) Update(value safejs.Value) (*Request, error) {
 	reqValue, err := c.jsCursor.Call("update", value)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, c.opError("update", safejs.Undefined(), err)
 	}
-	return wrapRequest(c.txn, reqValue), nil
+	return wrapWriteRequest(c.txn, "update", reqValue), nil
 }
 
 // CursorWithValue represents a cursor for traversing or iterating over multiple records in a database. It is the same as the Cursor, except that it includes the value property.
@@ -0,0 +1,158 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+
+	"github.com/hack-pad/safejs"
+)
+
+// CursorQuery filters and limits the records of a cursor request, for list queries without manual iteration state.
+// Create one with Where, Skip, or Take on a CursorRequest or CursorWithValueRequest, like:
+//
+//	values, err := req.Where(isActive).Skip(20).Take(10).Collect(ctx)
+//
+// Operations apply in the order they're chained: Skip before Where skips records, Skip after Where skips matches.
+// Leading skips move the cursor with a single Advance, and iteration stops as soon as a Take is satisfied.
+// A CursorQuery is immutable, so each operation returns a new one. Only iterate a query once, since its request's cursor is consumed.
+type CursorQuery[C any] struct {
+	req    *Request
+	wrap   func(*Cursor) C
+	result func(C) (safejs.Value, error) // the value Collect returns for each record
+	stages []cursorStage[C]
+}
+
+type cursorStage[C any] struct {
+	where func(C) (bool, error) // non-nil for Where stages
+	take  bool                  // true for Take stages, otherwise a Skip stage
+	n     uint
+}
+
+func (c *CursorRequest) query() *CursorQuery[*Cursor] {
+	return &CursorQuery[*Cursor]{
+		req:    c.Request,
+		wrap:   func(cursor *Cursor) *Cursor { return cursor },
+		result: (*Cursor).PrimaryKey,
+	}
+}
+
+// Where returns a query over the cursor's records for which pred returns true. Collect returns the primary keys of the records.
+func (c *CursorRequest) Where(pred func(*Cursor) (bool, error)) *CursorQuery[*Cursor] {
+	return c.query().Where(pred)
+}
+
+// Skip returns a query over the cursor's records after the first n. Collect returns the primary keys of the records.
+func (c *CursorRequest) Skip(n uint) *CursorQuery[*Cursor] {
+	return c.query().Skip(n)
+}
+
+// Take returns a query over at most the first n of the cursor's records. Collect returns the primary keys of the records.
+func (c *CursorRequest) Take(n uint) *CursorQuery[*Cursor] {
+	return c.query().Take(n)
+}
+
+func (c *CursorWithValueRequest) query() *CursorQuery[*CursorWithValue] {
+	return &CursorQuery[*CursorWithValue]{
+		req:    c.Request,
+		wrap:   newCursorWithValue,
+		result: (*CursorWithValue).Value,
+	}
+}
+
+// Where returns a query over the cursor's records for which pred returns true. Collect returns the values of the records.
+func (c *CursorWithValueRequest) Where(pred func(*CursorWithValue) (bool, error)) *CursorQuery[*CursorWithValue] {
+	return c.query().Where(pred)
+}
+
+// Skip returns a query over the cursor's records after the first n. Collect returns the values of the records.
+func (c *CursorWithValueRequest) Skip(n uint) *CursorQuery[*CursorWithValue] {
+	return c.query().Skip(n)
+}
+
+// Take returns a query over at most the first n of the cursor's records. Collect returns the values of the records.
+func (c *CursorWithValueRequest) Take(n uint) *CursorQuery[*CursorWithValue] {
+	return c.query().Take(n)
+}
+
+func (q *CursorQuery[C]) with(stage cursorStage[C]) *CursorQuery[C] {
+	next := *q
+	next.stages = append(q.stages[:len(q.stages):len(q.stages)], stage)
+	return &next
+}
+
+// Where returns a query over the records so far for which pred returns true.
+func (q *CursorQuery[C]) Where(pred func(C) (bool, error)) *CursorQuery[C] {
+	return q.with(cursorStage[C]{where: pred})
+}
+
+// Skip returns a query over the records so far, after the first n.
+func (q *CursorQuery[C]) Skip(n uint) *CursorQuery[C] {
+	return q.with(cursorStage[C]{n: n})
+}
+
+// Take returns a query over at most the first n of the records so far.
+func (q *CursorQuery[C]) Take(n uint) *CursorQuery[C] {
+	return q.with(cursorStage[C]{take: true, n: n})
+}
+
+// Iter calls iter with the cursor at each record of the query. Return ErrCursorStopIter to stop early.
+func (q *CursorQuery[C]) Iter(ctx context.Context, iter func(C) error) error {
+	// skips before any Where or Take count raw records, so jump over them at once
+	var advance uint
+	stages := q.stages
+	for len(stages) > 0 && stages[0].where == nil && !stages[0].take {
+		advance += stages[0].n
+		stages = stages[1:]
+	}
+	counts := make([]uint, len(stages))
+	return cursorIter(ctx, q.req, func(cursor *Cursor) error {
+		if advance > 0 {
+			n := advance
+			advance = 0
+			return cursor.Advance(n)
+		}
+		c := q.wrap(cursor)
+		for i, stage := range stages {
+			switch {
+			case stage.where != nil:
+				match, err := stage.where(c)
+				if err != nil || !match {
+					return err
+				}
+			case stage.take:
+				if counts[i] == stage.n {
+					return ErrCursorStopIter
+				}
+				counts[i]++
+			case counts[i] < stage.n:
+				counts[i]++
+				return nil
+			}
+		}
+		if err := iter(c); err != nil {
+			return err
+		}
+		for i, stage := range stages {
+			if stage.take && counts[i] == stage.n {
+				return ErrCursorStopIter // no more records can pass this Take
+			}
+		}
+		return nil
+	})
+}
+
+// Collect returns the records of the query: their values for a CursorWithValueRequest, or their primary keys for a CursorRequest.
+func (q *CursorQuery[C]) Collect(ctx context.Context) ([]safejs.Value, error) {
+	var results []safejs.Value
+	err := q.Iter(ctx, func(c C) error {
+		result, err := q.result(c)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+		return nil
+	})
+	return results, err
+}
@@ -0,0 +1,86 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestCursorQuery(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("numbers", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "numbers")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("numbers")
+	assert.NoError(t, err)
+	for i := 1; i <= 10; i++ {
+		_, err := store.PutKeyValue(i, i*10)
+		assert.NoError(t, err)
+	}
+	ints := func(values []safejs.Value) []int {
+		var result []int
+		for _, value := range values {
+			n, err := value.Int()
+			assert.NoError(t, err)
+			result = append(result, n)
+		}
+		return result
+	}
+	even := func(cursor *CursorWithValue) (bool, error) {
+		value, err := cursor.Value()
+		if err != nil {
+			return false, err
+		}
+		n, err := value.Int()
+		return n%20 == 0, err
+	}
+
+	for _, tc := range []struct {
+		description string
+		query       func(*CursorWithValueRequest) *CursorQuery[*CursorWithValue]
+		expect      []int
+	}{
+		{"skip then take", func(req *CursorWithValueRequest) *CursorQuery[*CursorWithValue] {
+			return req.Skip(2).Take(3)
+		}, []int{30, 40, 50}},
+		{"filter then page", func(req *CursorWithValueRequest) *CursorQuery[*CursorWithValue] {
+			return req.Where(even).Skip(1).Take(2)
+		}, []int{40, 60}},
+		{"skip records then filter", func(req *CursorWithValueRequest) *CursorQuery[*CursorWithValue] {
+			return req.Skip(3).Where(even)
+		}, []int{40, 60, 80, 100}},
+		{"take records then filter", func(req *CursorWithValueRequest) *CursorQuery[*CursorWithValue] {
+			return req.Take(5).Where(even)
+		}, []int{20, 40}},
+		{"skip past the end", func(req *CursorWithValueRequest) *CursorQuery[*CursorWithValue] {
+			return req.Skip(20)
+		}, nil},
+		{"take none", func(req *CursorWithValueRequest) *CursorQuery[*CursorWithValue] {
+			return req.Take(0)
+		}, nil},
+	} {
+		req, err := store.OpenCursor(CursorNext)
+		assert.NoError(t, err)
+		values, err := tc.query(req).Collect(ctx)
+		assert.NoError(t, err)
+		if !assert.Equal(t, tc.expect, ints(values)) {
+			t.Log(tc.description)
+		}
+	}
+
+	keyReq, err := store.OpenKeyCursor(CursorPrevious)
+	assert.NoError(t, err)
+	keys, err := keyReq.Skip(1).Take(2).Collect(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{9, 8}, ints(keys))
+	assert.NoError(t, txn.Await(ctx))
+}
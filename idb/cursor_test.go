@@ -27,7 +27,7 @@ func someKeyStore(tb testing.TB) (*ObjectStore, *Index) {
 	db := testDB(tb, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(tb, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("primary")), IndexOptions{})
+		_, err = store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{})
 		assert.NoError(tb, err)
 	})
 	txn, err := db.Transaction(TransactionReadWrite, "mystore")
@@ -0,0 +1,181 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// ErrInvalidCursorToken is returned when a cursor token can't be decoded.
+var ErrInvalidCursorToken = errors.New("invalid cursor token")
+
+// cursorToken is the JSON form of a cursor's position.
+type cursorToken struct {
+	Direction  string   `json:"d"`
+	Key        tokenKey `json:"k"`
+	PrimaryKey tokenKey `json:"p"`
+}
+
+// tokenKey is the JSON form of a key. Type is "n" for numbers, "s" for strings, "d" for Dates, "b" for binary keys, and "a" for arrays.
+type tokenKey struct {
+	Type  string     `json:"t"`
+	Value string     `json:"v,omitempty"`
+	Array []tokenKey `json:"a,omitempty"`
+}
+
+// Token encodes the cursor's position and direction into an opaque string. Resume iterating after this position with OpenCursorFromToken, even from another page load.
+func (c *Cursor) Token() (string, error) {
+	key, err := c.Key()
+	if err != nil {
+		return "", err
+	}
+	primaryKey, err := c.PrimaryKey()
+	if err != nil {
+		return "", err
+	}
+	direction, err := c.Direction()
+	if err != nil {
+		return "", err
+	}
+	if key.IsUndefined() || primaryKey.IsUndefined() {
+		return "", errors.New("cursor has no position")
+	}
+	token := cursorToken{Direction: direction.String()}
+	if token.Key, err = encodeTokenKey(key); err != nil {
+		return "", err
+	}
+	if token.PrimaryKey, err = encodeTokenKey(primaryKey); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func encodeTokenKey(key safejs.Value) (tokenKey, error) {
+	switch key.Type() {
+	case safejs.TypeNumber:
+		n, err := key.Float()
+		return tokenKey{Type: "n", Value: strconv.FormatFloat(n, 'g', -1, 64)}, err
+	case safejs.TypeString:
+		s, err := key.String()
+		return tokenKey{Type: "s", Value: s}, err
+	case safejs.TypeObject:
+	default:
+		return tokenKey{}, fmt.Errorf("unsupported key type: %s", key.Type())
+	}
+
+	if isDate, err := key.InstanceOf(jsDate); err != nil || isDate {
+		if err != nil {
+			return tokenKey{}, err
+		}
+		t, err := KeyTime(key)
+		return tokenKey{Type: "d", Value: strconv.FormatInt(t.UnixMilli(), 10)}, err
+	}
+	isArray, err := key.InstanceOf(jsArray)
+	if err != nil {
+		return tokenKey{}, err
+	}
+	if !isArray {
+		b, err := BinaryKeyBytes(key)
+		return tokenKey{Type: "b", Value: base64.RawURLEncoding.EncodeToString(b)}, err
+	}
+	array := tokenKey{Type: "a", Array: []tokenKey{}}
+	err = iterArray(key, func(_ int, element safejs.Value) (bool, error) {
+		elementKey, err := encodeTokenKey(element)
+		array.Array = append(array.Array, elementKey)
+		return true, err
+	})
+	return array, err
+}
+
+func decodeCursorToken(token string) (CursorDirection, safejs.Value, safejs.Value, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, safejs.Undefined(), safejs.Undefined(), fmt.Errorf("%w: %v", ErrInvalidCursorToken, err)
+	}
+	var t cursorToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return 0, safejs.Undefined(), safejs.Undefined(), fmt.Errorf("%w: %v", ErrInvalidCursorToken, err)
+	}
+	key, err := t.Key.decode()
+	if err != nil {
+		return 0, safejs.Undefined(), safejs.Undefined(), err
+	}
+	primaryKey, err := t.PrimaryKey.decode()
+	if err != nil {
+		return 0, safejs.Undefined(), safejs.Undefined(), err
+	}
+	return parseCursorDirection(t.Direction), key, primaryKey, nil
+}
+
+func (k tokenKey) decode() (safejs.Value, error) {
+	switch k.Type {
+	case "n":
+		n, err := strconv.ParseFloat(k.Value, 64)
+		if err != nil {
+			return safejs.Undefined(), fmt.Errorf("%w: %v", ErrInvalidCursorToken, err)
+		}
+		return safejs.ValueOf(n)
+	case "s":
+		return safejs.ValueOf(k.Value)
+	case "d":
+		ms, err := strconv.ParseInt(k.Value, 10, 64)
+		if err != nil {
+			return safejs.Undefined(), fmt.Errorf("%w: %v", ErrInvalidCursorToken, err)
+		}
+		return TimeKey(time.UnixMilli(ms))
+	case "b":
+		b, err := base64.RawURLEncoding.DecodeString(k.Value)
+		if err != nil {
+			return safejs.Undefined(), fmt.Errorf("%w: %v", ErrInvalidCursorToken, err)
+		}
+		return BinaryKey(b)
+	case "a":
+		array, err := jsArray.New(len(k.Array))
+		if err != nil {
+			return safejs.Undefined(), err
+		}
+		for i, elementKey := range k.Array {
+			element, err := elementKey.decode()
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			if err := array.SetIndex(i, element); err != nil {
+				return safejs.Undefined(), err
+			}
+		}
+		return array, nil
+	default:
+		return safejs.Undefined(), fmt.Errorf("%w: unknown key type %q", ErrInvalidCursorToken, k.Type)
+	}
+}
+
+// OpenCursorFromToken opens a cursor which resumes iterating just past the position encoded in token, in the same direction. Create tokens with Cursor.Token on a cursor over this object store.
+// Records added or deleted since the token was created are reflected, since the cursor picks up from the token's key rather than replaying a snapshot.
+func (o *ObjectStore) OpenCursorFromToken(token string) (*CursorWithValueRequest, error) {
+	direction, _, primaryKey, err := decodeCursorToken(token)
+	if err != nil {
+		return nil, err
+	}
+	var keyRange *KeyRange
+	if direction == CursorNext || direction == CursorNextUnique {
+		keyRange, err = NewKeyRangeLowerBound(primaryKey, true)
+	} else {
+		keyRange, err = NewKeyRangeUpperBound(primaryKey, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return o.OpenCursorRange(keyRange, direction)
+}
@@ -0,0 +1,112 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestCursorToken(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		_, err := store.PutKeyValue(i, i)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, txn.Await(ctx))
+
+	for _, tc := range []struct {
+		name      string
+		direction CursorDirection
+		expect    []int
+	}{
+		{name: "next", direction: CursorNext, expect: []int{0, 1, 2, 3, 4, 5}},
+		{name: "prev", direction: CursorPrevious, expect: []int{5, 4, 3, 2, 1, 0}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// read one page of 2 records per transaction, resuming from the previous page's token
+			var keys []int
+			var token string
+			for page := 0; page < 4; page++ {
+				txn, err := db.Transaction(TransactionReadOnly, "mystore")
+				assert.NoError(t, err)
+				store, err := txn.ObjectStore("mystore")
+				assert.NoError(t, err)
+				var req *CursorWithValueRequest
+				if token == "" {
+					req, err = store.OpenCursor(tc.direction)
+				} else {
+					req, err = store.OpenCursorFromToken(token)
+				}
+				assert.NoError(t, err)
+				count := 0
+				err = req.Iter(ctx, func(cursor *CursorWithValue) error {
+					key, err := cursor.Key()
+					if err != nil {
+						return err
+					}
+					n, err := key.Int()
+					if err != nil {
+						return err
+					}
+					keys = append(keys, n)
+					token, err = cursor.Token()
+					if err != nil {
+						return err
+					}
+					count++
+					if count == 2 {
+						return ErrCursorStopIter
+					}
+					return nil
+				})
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expect, keys)
+		})
+	}
+}
+
+func TestCursorTokenKeys(t *testing.T) {
+	t.Parallel()
+	binaryKey, err := BinaryKey([]byte{1, 2, 0xff})
+	assert.NoError(t, err)
+	dateKey, err := TimeKey(time.UnixMilli(1700000000123))
+	assert.NoError(t, err)
+	arrayKey, err := safejs.ValueOf([]interface{}{1, "a", []interface{}{2.5}})
+	assert.NoError(t, err)
+	keys := []safejs.Value{dateKey, binaryKey, arrayKey}
+	for _, value := range []interface{}{-1.5, 0, math.Inf(-1), "", "some key"} {
+		key, err := ValueOf(value)
+		assert.NoError(t, err)
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		encoded, err := encodeTokenKey(key)
+		assert.NoError(t, err)
+		decoded, err := encoded.decode()
+		assert.NoError(t, err)
+		cmp, err := compareKeys(key, decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, cmp)
+	}
+
+	_, err = (&ObjectStore{}).OpenCursorFromToken("not a token")
+	assert.ErrorIs(t, err, ErrInvalidCursorToken)
+}
@@ -4,6 +4,10 @@
 package idb
 
 import (
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/aperturerobotics/go-indexeddb/idb/internal/jscache"
 	"github.com/hack-pad/safejs"
 )
@@ -12,12 +16,29 @@ import (
 type Database struct {
 	jsDB        safejs.Value
 	callStrings jscache.Strings
+
+	frozenMu    sync.Mutex
+	frozen      map[string]*StoreFreeze
+	freezeWatch *freezeChannel // set by WatchFreezes
+
+	codec atomic.Pointer[Codec]
 }
 
 func wrapDatabase(jsDB safejs.Value) *Database {
 	return &Database{jsDB: jsDB}
 }
 
+// WrapDatabase wraps an IDBDatabase connection opened outside this package, like by a JavaScript library sharing the connection.
+// Unlike connections from Factory.Open, it isn't closed automatically on version changes and doesn't count towards ErrConflictingOpen checks.
+func WrapDatabase(jsDB safejs.Value) *Database {
+	return wrapDatabase(jsDB)
+}
+
+// Unwrap returns the underlying JavaScript IDBDatabase object.
+func (db *Database) Unwrap() safejs.Value {
+	return db.jsDB
+}
+
 // Name returns the name of the connected database.
 func (db *Database) Name() (string, error) {
 	value, err := db.jsDB.Get("name")
@@ -48,10 +69,11 @@ func (db *Database) ObjectStoreNames() ([]string, error) {
 
 // CreateObjectStore creates and returns a new object store or index.
 func (db *Database) CreateObjectStore(name string, options ObjectStoreOptions) (*ObjectStore, error) {
-	jsObjectStore, err := db.jsDB.Call("createObjectStore", name, map[string]interface{}{
-		"autoIncrement": options.AutoIncrement,
-		"keyPath":       options.KeyPath,
-	})
+	jsOptions, err := options.jsValue()
+	if err != nil {
+		return nil, err
+	}
+	jsObjectStore, err := db.jsDB.Call("createObjectStore", name, jsOptions)
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
@@ -64,10 +86,26 @@ func (db *Database) DeleteObjectStore(name string) error {
 	return tryAsDOMException(err)
 }
 
-// Close closes the connection to a database.
+// SetCodec sets the Codec which converts native Go keys and values in this Database's transactions, like in ObjectStore.PutValue and ObjectStore.GetValue.
+// Set it before starting transactions. A nil Codec converts like ValueOf.
+func (db *Database) SetCodec(codec *Codec) {
+	db.codec.Store(codec)
+}
+
+// Codec returns the Codec set with SetCodec, or nil if there is none. Use its GoValueOf to convert values read from this Database.
+func (db *Database) Codec() *Codec {
+	return db.codec.Load()
+}
+
+// Close closes the connection to a database. Stores frozen with FreezeStore are unfrozen, and watching for freezes from other tabs stops.
 func (db *Database) Close() error {
 	_, err := db.jsDB.Call("close")
-	return tryAsDOMException(err)
+	if err != nil {
+		return tryAsDOMException(err)
+	}
+	unregisterOpenConn(db.jsDB)
+	db.stopWatchingFreezes()
+	return nil
 }
 
 // Transaction returns a transaction object containing the Transaction.ObjectStore() method, which you can use to access your object store.
@@ -79,11 +117,24 @@ func (db *Database) Transaction(mode TransactionMode, objectStoreName string, ob
 type TransactionOptions struct {
 	Mode       TransactionMode
 	Durability TransactionDurability
+	// MaxLifetime aborts the transaction if it hasn't finished this long after it starts, so a request which never resolves can't leave callers waiting forever.
+	// The transaction's pending requests and Await then fail with ErrTransactionLifetime. Zero means no limit.
+	MaxLifetime time.Duration
 }
 
 // TransactionWithOptions returns a transaction object containing the Transaction.ObjectStore() method, which you can use to access your object store.
 func (db *Database) TransactionWithOptions(options TransactionOptions, objectStoreName string, objectStoreNames ...string) (*Transaction, error) {
+	return db.transaction(options, nil, objectStoreName, objectStoreNames...)
+}
+
+// transaction starts a transaction, failing if it could write to a store frozen by anything other than freeze.
+func (db *Database) transaction(options TransactionOptions, freeze *StoreFreeze, objectStoreName string, objectStoreNames ...string) (*Transaction, error) {
 	objectStoreNames = append([]string{objectStoreName}, objectStoreNames...) // require at least one name
+	if options.Mode == TransactionReadWrite {
+		if err := db.checkFrozen(freeze, objectStoreNames); err != nil {
+			return nil, err
+		}
+	}
 
 	optionsMap := make(map[string]interface{})
 	if options.Durability != DurabilityDefault {
@@ -97,7 +148,14 @@ func (db *Database) TransactionWithOptions(options TransactionOptions, objectSto
 
 	jsTxn, err := db.jsDB.Call("transaction", args...)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, asTxnFinished(tryAsDOMException(err))
+	}
+	txn := wrapTransaction(db, jsTxn)
+	if options.MaxLifetime > 0 {
+		if err := txn.limitLifetime(options.MaxLifetime); err != nil {
+			_ = txn.Abort()
+			return nil, err
+		}
 	}
-	return wrapTransaction(db, jsTxn), nil
+	return txn, nil
 }
@@ -0,0 +1,56 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+
+	"github.com/hack-pad/safejs"
+)
+
+// OnVersionChange invokes fn each time another connection wants to upgrade or delete the database, until ctx is done. NewVersion is 0 for a delete.
+// This package closes the connection when a version change arrives, so the other connection isn't blocked. Use fn to stop using the Database, like by reopening it or asking the user to reload.
+func (db *Database) OnVersionChange(ctx context.Context, fn func(VersionChange)) error {
+	return db.listen(ctx, "versionchange", func(event safejs.Value) {
+		change, err := versionChangeOf(event)
+		if err != nil {
+			panic(err)
+		}
+		fn(change)
+	})
+}
+
+// OnClose invokes fn if the browser closes the connection unexpectedly, like when the database is deleted through developer tools or its storage is cleared, until ctx is done.
+// fn isn't invoked for Database.Close or for closes after a version change.
+func (db *Database) OnClose(ctx context.Context, fn func()) error {
+	return db.listen(ctx, "close", func(safejs.Value) {
+		fn()
+	})
+}
+
+// listen invokes fn for each of the connection's eventName events until ctx is done.
+func (db *Database) listen(ctx context.Context, eventName string, fn func(event safejs.Value)) error {
+	listener, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		var event safejs.Value
+		if len(args) > 0 {
+			event = args[0]
+		}
+		fn(event)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.jsDB.Call(addEventListener, eventName, listener)
+	if err != nil {
+		listener.Release()
+		return tryAsDOMException(err)
+	}
+	go func() {
+		<-ctx.Done()
+		_, _ = db.jsDB.Call(removeEventListener, eventName, listener)
+		listener.Release()
+	}()
+	return nil
+}
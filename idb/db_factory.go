@@ -6,7 +6,9 @@ package idb
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"syscall/js"
 
 	"github.com/hack-pad/safejs"
@@ -17,6 +19,9 @@ type Factory struct {
 	jsFactory safejs.Value
 }
 
+// ErrVersionTooNew is returned by Factory.OpenAtLeast when the database's version is newer than this build of the app supports.
+var ErrVersionTooNew = errors.New("database version is newer than supported")
+
 var (
 	global     *Factory
 	globalErr  error
@@ -25,66 +30,353 @@ var (
 
 // Global returns the global IndexedDB instance.
 // Can be called multiple times, will always return the same result (or error if one occurs).
+// Panics if IndexedDB isn't available. Use LoadGlobal or IsSupported to handle that instead.
 func Global() *Factory {
+	factory, err := LoadGlobal()
+	if err != nil {
+		panic(err)
+	}
+	return factory
+}
+
+// LoadGlobal is the same as Global, but returns an error wrapping ErrNotSupported instead of panicking if IndexedDB isn't available.
+func LoadGlobal() (*Factory, error) {
 	globalOnce.Do(func() {
-		var jsFactory safejs.Value
-		jsFactory, globalErr = safejs.Global().Get("indexedDB")
-		if globalErr != nil {
+		if globalErr = loadIDBGlobals(); globalErr != nil {
 			return
 		}
-		var truthy bool
-		truthy, globalErr = jsFactory.Truthy()
+		var jsFactory safejs.Value
+		jsFactory, globalErr = lookupGlobal("indexedDB")
 		if globalErr != nil {
 			return
 		}
-		if truthy {
-			global, globalErr = WrapFactory(safejs.Unsafe(jsFactory))
-		} else {
-			globalErr = errors.New("Global JS variable 'indexedDB' is not defined")
-		}
+		global, globalErr = WrapFactory(safejs.Unsafe(jsFactory))
 	})
-	if globalErr != nil {
-		panic(globalErr)
-	}
-	return global
+	return global, globalErr
 }
 
-// WrapFactory wraps the given IDBFactory object
+// WrapFactory wraps the given IDBFactory object. Use it instead of Global for factories other than the main global's indexedDB, like a worker's, another realm's, or a polyfill such as fake-indexeddb.
 func WrapFactory(jsFactory js.Value) (*Factory, error) {
-	return &Factory{
+	factory := &Factory{
 		jsFactory: safejs.Safe(jsFactory),
-	}, nil
+	}
+	wrappedFactory.CompareAndSwap(nil, factory)
+	return factory, nil
+}
+
+// Unwrap returns the underlying JavaScript IDBFactory object.
+func (f *Factory) Unwrap() safejs.Value {
+	return f.jsFactory
 }
 
 // Open requests to open a connection to a database.
+// Canceling upgradeCtx before the database opens abandons the request, so the connection is closed as soon as it opens and OpenDBRequest.Await returns an error wrapping ErrOpenCanceled.
+// Returns an error wrapping ErrConflictingOpen if this program already has a connection to the database open at a different version.
 func (f *Factory) Open(upgradeCtx context.Context, name string, version uint, upgrader Upgrader) (*OpenDBRequest, error) {
+	return f.OpenWithOptions(upgradeCtx, name, OpenOptions{Version: version, Upgrader: upgrader})
+}
+
+// OpenOptions contains all available options for opening a connection to a database
+type OpenOptions struct {
+	// Version is the version to open, upgrading the database if it's older. Zero opens the current version.
+	Version uint
+	// Upgrader upgrades the database if it's older than Version. If nil, upgrades don't change the schema.
+	Upgrader Upgrader
+	// OnUpgrade is like Upgrader, but also gets the upgrade's versionchange transaction and event, for migrating data. Only one of Upgrader and OnUpgrade may be set.
+	//
+	// It runs while the browser dispatches the upgradeneeded event, so it must not block. Await requests in a goroutine it starts, and abort the transaction to fail the upgrade.
+	// The open request succeeds once the transaction completes, after every request made in it.
+	OnUpgrade func(Upgrade) error
+	// OnBlocked is called when connections to the database in other tabs or workers don't close for a version change, which leaves the open request waiting until they do.
+	// Use it to prompt the user to close the other tabs. Connections opened by this package close themselves, so only connections from other code or older builds block.
+	OnBlocked func(VersionChange)
+	// WaitReady calls Factory.WaitReady with upgradeCtx before opening, to work around Safari's first open after a page load hanging.
+	WaitReady bool
+}
+
+// VersionChange describes a change of a database's version.
+type VersionChange struct {
+	OldVersion uint
+	// NewVersion is 0 if the database is being deleted.
+	NewVersion uint
+}
+
+// OpenWithOptions requests to open a connection to a database.
+// Returns an error wrapping ErrConflictingOpen if this program already has a connection to the database open at a different version.
+func (f *Factory) OpenWithOptions(upgradeCtx context.Context, name string, options OpenOptions) (*OpenDBRequest, error) {
+	upgrade := options.OnUpgrade
+	if options.Upgrader != nil {
+		if upgrade != nil {
+			return nil, errors.New("only one of Upgrader and OnUpgrade may be set")
+		}
+		upgrade = options.Upgrader.upgradeFunc()
+	}
+	if err := loadIDBGlobals(); err != nil {
+		return nil, err
+	}
+	if options.WaitReady {
+		if err := f.WaitReady(upgradeCtx); err != nil {
+			return nil, err
+		}
+	}
+	caller := callerOutsidePackage()
+	reservation, err := reserveOpen(name, options.Version, caller)
+	if err != nil {
+		return nil, err
+	}
 	args := []interface{}{name}
-	if version > 0 {
-		args = append(args, version)
+	if options.Version > 0 {
+		args = append(args, options.Version)
 	}
 	reqValue, err := f.jsFactory.Call("open", args...)
 	if err != nil {
+		reservation.release()
 		return nil, tryAsDOMException(err)
 	}
 	req := wrapRequest(nil, reqValue)
-	return newOpenDBRequest(upgradeCtx, req, upgrader)
+	openReq, err := newOpenDBRequest(upgradeCtx, req, upgrade, options.OnBlocked, caller, reservation)
+	if err != nil {
+		reservation.release()
+		return nil, err
+	}
+	return openReq, nil
+}
+
+// ErrDatabaseNotFound is returned by Factory.OpenCurrent when the database doesn't exist.
+var ErrDatabaseNotFound = errors.New("database not found")
+
+// OpenCurrent opens a connection to the current version of an existing database, without upgrading it.
+// Use it in tools, like exporters or inspectors, which must not change the database's version or create it.
+//
+// Returns an error wrapping ErrDatabaseNotFound if the database doesn't exist.
+func (f *Factory) OpenCurrent(ctx context.Context, name string) (*Database, error) {
+	if err := loadIDBGlobals(); err != nil {
+		return nil, err
+	}
+	reqValue, err := f.jsFactory.Call("open", name)
+	if err != nil {
+		return nil, tryAsDOMException(err)
+	}
+	var missing atomic.Bool
+	abortCreate, err := safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		// upgrades only happen when opening without a version creates the database, so abort to avoid creating it
+		missing.Store(true)
+		txn, err := reqValue.Get("transaction")
+		if err == nil {
+			_, _ = txn.Call("abort")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = reqValue.Call(removeEventListener, "upgradeneeded", abortCreate)
+		abortCreate.Release()
+	}()
+
+	req, err := newOpenDBRequest(ctx, wrapRequest(nil, reqValue), nil, nil, callerOutsidePackage(), nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = reqValue.Call(addEventListener, "upgradeneeded", abortCreate)
+	if err != nil {
+		return nil, tryAsDOMException(err)
+	}
+	db, err := req.Await(ctx)
+	if missing.Load() {
+		if err == nil {
+			_ = db.Close()
+		}
+		return nil, fmt.Errorf("%w: %s", ErrDatabaseNotFound, name)
+	}
+	return db, err
+}
+
+// OpenAtLeast opens a connection to a database with a version of at least minVersion, upgrading it if the database is older or doesn't exist yet.
+// Databases already at minVersion or newer are opened at their current version without a version bump.
+//
+// If maxVersion is not 0 and the database's version exceeds it, returns an error wrapping ErrVersionTooNew. Use this to prompt users to update the app after a newer build upgraded the database.
+func (f *Factory) OpenAtLeast(ctx context.Context, name string, minVersion, maxVersion uint, upgrader Upgrader) (*Database, error) {
+	if minVersion == 0 {
+		return nil, errors.New("minimum version must be at least 1")
+	}
+	if maxVersion != 0 && maxVersion < minVersion {
+		return nil, fmt.Errorf("maximum version %d is less than minimum version %d", maxVersion, minVersion)
+	}
+
+	version := minVersion
+	if info, err := f.database(ctx, name); err == nil && info.Version > minVersion {
+		version = info.Version
+	} // if Databases isn't supported, fall back to handling VersionError below
+	if err := checkMaxVersion(version, maxVersion); err != nil {
+		return nil, err
+	}
+
+	db, err := f.openAwait(ctx, name, version, upgrader)
+	if errors.Is(err, ErrVersion) {
+		// the database is newer than version, so open its current version instead
+		db, err = f.openAwait(ctx, name, 0, upgrader)
+	}
+	if err != nil {
+		return nil, err
+	}
+	dbVersion, err := db.Version()
+	if err == nil {
+		err = checkMaxVersion(dbVersion, maxVersion)
+	}
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func checkMaxVersion(version, maxVersion uint) error {
+	if maxVersion != 0 && version > maxVersion {
+		return fmt.Errorf("%w: version %d exceeds maximum version %d", ErrVersionTooNew, version, maxVersion)
+	}
+	return nil
+}
+
+func (f *Factory) openAwait(ctx context.Context, name string, version uint, upgrader Upgrader) (*Database, error) {
+	req, err := f.Open(ctx, name, version, upgrader)
+	if err != nil {
+		return nil, err
+	}
+	return req.Await(ctx)
+}
+
+// DatabaseInfo contains the name and version of a database.
+type DatabaseInfo struct {
+	Name    string
+	Version uint
+}
+
+// Databases returns the names and versions of all available databases. Returns an error if the browser doesn't support listing databases.
+func (f *Factory) Databases(ctx context.Context) ([]DatabaseInfo, error) {
+	databases, err := f.jsFactory.Get("databases")
+	if err != nil {
+		return nil, err
+	}
+	if databases.Type() != safejs.TypeFunction {
+		return nil, errors.New("listing databases is not supported")
+	}
+	promise, err := f.jsFactory.Call("databases")
+	if err != nil {
+		return nil, tryAsDOMException(err)
+	}
+	result, err := awaitPromise(ctx, promise)
+	if err != nil {
+		return nil, err
+	}
+	var infos []DatabaseInfo
+	err = iterArray(result, func(_ int, value safejs.Value) (bool, error) {
+		properties, err := getProperties(value, "name", "version")
+		if err != nil {
+			return false, err
+		}
+		name, err := properties[0].String()
+		if err != nil {
+			return false, err
+		}
+		version, err := properties[1].Int()
+		if err != nil {
+			return false, err
+		}
+		infos = append(infos, DatabaseInfo{Name: name, Version: uint(version)})
+		return true, nil
+	})
+	return infos, err
+}
+
+// database returns the info for the database with the given name. Returns a zero DatabaseInfo if it doesn't exist.
+func (f *Factory) database(ctx context.Context, name string) (DatabaseInfo, error) {
+	infos, err := f.Databases(ctx)
+	if err != nil {
+		return DatabaseInfo{}, err
+	}
+	for _, info := range infos {
+		if info.Name == name {
+			return info, nil
+		}
+	}
+	return DatabaseInfo{}, nil
 }
 
 // DeleteDatabase requests the deletion of a database.
 func (f *Factory) DeleteDatabase(name string) (*AckRequest, error) {
+	return f.DeleteDatabaseWithOptions(name, DeleteOptions{})
+}
+
+// DeleteOptions contains all available options for deleting a database
+type DeleteOptions struct {
+	// OnBlocked is called when open connections to the database don't close for the deletion, which leaves the request waiting until they do.
+	// The VersionChange's NewVersion is 0.
+	OnBlocked func(VersionChange)
+}
+
+// DeleteDatabaseWithOptions requests the deletion of a database.
+func (f *Factory) DeleteDatabaseWithOptions(name string, options DeleteOptions) (*AckRequest, error) {
+	if err := loadIDBGlobals(); err != nil {
+		return nil, err
+	}
 	reqValue, err := f.jsFactory.Call("deleteDatabase", name)
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
 	req := wrapRequest(nil, reqValue)
+	if options.OnBlocked != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := listenBlocked(ctx, req, options.OnBlocked); err != nil {
+			cancel()
+			return nil, err
+		}
+		if err := req.Listen(ctx, cancel, cancel); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
 	return newAckRequest(req), nil
 }
 
+// ForceDeleteDatabase closes this program's open connections to a database, then deletes it and waits for the deletion to finish.
+// Use it in cleanup code which must not wait on connections it forgot to close. Databases using the closed connections fail their requests afterward.
+//
+// Connections in other tabs or workers still block the deletion until they close. Handle that with options.OnBlocked.
+func (f *Factory) ForceDeleteDatabase(ctx context.Context, name string, options DeleteOptions) error {
+	closeOpenConns(name)
+	req, err := f.DeleteDatabaseWithOptions(name, options)
+	if err != nil {
+		return err
+	}
+	return req.Await(ctx)
+}
+
 // CompareKeys compares two keys and returns a result indicating which one is greater in value.
 func (f *Factory) CompareKeys(a, b js.Value) (int, error) {
+	return f.compareKeys(safejs.Safe(a), safejs.Safe(b))
+}
+
+func (f *Factory) compareKeys(a, b safejs.Value) (int, error) {
 	compare, err := f.jsFactory.Call("cmp", a, b)
 	if err != nil {
 		return 0, tryAsDOMException(err)
 	}
 	return compare.Int()
 }
+
+// wrappedFactory is the first Factory made with WrapFactory, for comparing keys in hosts which only provide IndexedDB that way.
+var wrappedFactory atomic.Pointer[Factory]
+
+// compareKeys compares two keys using the global IndexedDB instance, or a wrapped one if there is no global. Key ordering is the same for every IDBFactory.
+// Returns an error wrapping ErrNotSupported if neither is available.
+func compareKeys(a, b safejs.Value) (int, error) {
+	factory, err := LoadGlobal()
+	if err != nil {
+		if factory = wrappedFactory.Load(); factory == nil {
+			return 0, err
+		}
+	}
+	return factory.compareKeys(a, b)
+}
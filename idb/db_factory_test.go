@@ -8,6 +8,7 @@ import (
 	"strings"
 	"syscall/js"
 	"testing"
+	"time"
 
 	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
 	"github.com/hack-pad/safejs"
@@ -180,3 +181,344 @@ func TestFactoryCompareKeys(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestFactoryDatabases(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+	req, err := dbFactory.Open(ctx, name, 3, func(*Database, uint, uint) error { return nil })
+	assert.NoError(t, err)
+	db, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	infos, err := dbFactory.Databases(ctx)
+	assert.NoError(t, err)
+	var found []DatabaseInfo
+	for _, info := range infos {
+		if info.Name == name {
+			found = append(found, info)
+		}
+	}
+	assert.Equal(t, []DatabaseInfo{{Name: name, Version: 3}}, found)
+}
+
+func TestFactoryOpenAtLeast(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+	openAtLeast := func(minVersion, maxVersion uint) (db *Database, upgrades [][2]uint, err error) {
+		db, err = dbFactory.OpenAtLeast(ctx, name, minVersion, maxVersion, func(_ *Database, oldVersion, newVersion uint) error {
+			upgrades = append(upgrades, [2]uint{oldVersion, newVersion})
+			return nil
+		})
+		return db, upgrades, err
+	}
+
+	db, upgrades, err := openAtLeast(2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]uint{{0, 2}}, upgrades)
+	assert.NoError(t, db.Close())
+
+	// a newer build upgrades the database
+	db, upgrades, err = openAtLeast(3, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]uint{{2, 3}}, upgrades)
+	assert.NoError(t, db.Close())
+
+	// an older build opens the newer version without downgrading or upgrading
+	db, upgrades, err = openAtLeast(2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]uint(nil), upgrades)
+	version, err := db.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), version)
+	assert.NoError(t, db.Close())
+
+	// an even older build doesn't support the current version
+	_, _, err = openAtLeast(1, 2)
+	assert.ErrorIs(t, err, ErrVersionTooNew)
+
+	_, _, err = openAtLeast(0, 0)
+	assert.Error(t, err)
+	_, _, err = openAtLeast(3, 2)
+	assert.Error(t, err)
+}
+
+func TestFactoryOpenCurrent(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+
+	_, err := dbFactory.OpenCurrent(ctx, name)
+	assert.ErrorIs(t, err, ErrDatabaseNotFound)
+	infos, err := dbFactory.Databases(ctx)
+	assert.NoError(t, err)
+	for _, info := range infos {
+		assert.NotEqual(t, name, info.Name)
+	}
+
+	req, err := dbFactory.Open(ctx, name, 3, func(db *Database, _, _ uint) error {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		return err
+	})
+	assert.NoError(t, err)
+	db, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	db, err = dbFactory.OpenCurrent(ctx, name)
+	assert.NoError(t, err)
+	version, err := db.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), version)
+	storeNames, err := db.ObjectStoreNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mystore"}, storeNames)
+	assert.NoError(t, db.Close())
+}
+
+func TestFactoryOpenBlocked(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+
+	// a connection without a versionchange handler, like one from other code, blocks upgrades until it closes
+	reqValue, err := dbFactory.jsFactory.Call("open", name, 1)
+	assert.NoError(t, err)
+	otherDB, err := wrapRequest(nil, reqValue).Await(ctx)
+	assert.NoError(t, err)
+
+	blocked := make(chan VersionChange, 1)
+	req, err := dbFactory.OpenWithOptions(ctx, name, OpenOptions{
+		Version: 2,
+		OnBlocked: func(change VersionChange) {
+			blocked <- change
+			_, err := otherDB.Call("close")
+			assert.NoError(t, err)
+		},
+	})
+	assert.NoError(t, err)
+	db, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, VersionChange{OldVersion: 1, NewVersion: 2}, <-blocked)
+	version, err := db.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), version)
+	assert.NoError(t, db.Close())
+}
+
+func TestFactoryDeleteDatabaseBlocked(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+
+	reqValue, err := dbFactory.jsFactory.Call("open", name, 3)
+	assert.NoError(t, err)
+	otherDB, err := wrapRequest(nil, reqValue).Await(ctx)
+	assert.NoError(t, err)
+
+	blocked := make(chan VersionChange, 1)
+	req, err := dbFactory.DeleteDatabaseWithOptions(name, DeleteOptions{
+		OnBlocked: func(change VersionChange) {
+			blocked <- change
+			_, err := otherDB.Call("close")
+			assert.NoError(t, err)
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, req.Await(ctx))
+	assert.Equal(t, VersionChange{OldVersion: 3}, <-blocked)
+}
+
+func TestFactoryForceDeleteDatabase(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+
+	req, err := dbFactory.Open(ctx, name, 1, func(db *Database, _, _ uint) error {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		return err
+	})
+	assert.NoError(t, err)
+	db, err := req.Await(ctx)
+	assert.NoError(t, err)
+
+	err = dbFactory.ForceDeleteDatabase(ctx, name, DeleteOptions{
+		OnBlocked: func(VersionChange) {
+			t.Error("Should not be blocked by this program's connections")
+		},
+	})
+	assert.NoError(t, err)
+	_, err = db.Transaction(TransactionReadOnly, "mystore")
+	assert.Error(t, err)
+
+	// the closed connection no longer conflicts with opening another version
+	req, err = dbFactory.Open(ctx, name, 2, func(*Database, uint, uint) error { return nil })
+	assert.NoError(t, err)
+	db, err = req.Await(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+}
+
+func TestFactoryOpenMigrateInUpgrade(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+
+	req, err := dbFactory.OpenWithOptions(ctx, name, OpenOptions{
+		Version: 1,
+		OnUpgrade: func(upgrade Upgrade) error {
+			_, err := upgrade.Database.CreateObjectStore("old", ObjectStoreOptions{})
+			if err != nil {
+				return err
+			}
+			store, err := upgrade.Transaction.ObjectStore("old")
+			if err != nil {
+				return err
+			}
+			_, err = store.PutKeyValue("key", "value")
+			return err
+		},
+	})
+	assert.NoError(t, err)
+	db, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	migrateErr := make(chan error, 1)
+	req, err = dbFactory.OpenWithOptions(ctx, name, OpenOptions{
+		Version: 2,
+		OnUpgrade: func(upgrade Upgrade) error {
+			assert.Equal(t, uint(1), upgrade.OldVersion)
+			assert.Equal(t, uint(2), upgrade.NewVersion)
+			newStore, err := upgrade.Database.CreateObjectStore("new", ObjectStoreOptions{})
+			if err != nil {
+				return err
+			}
+			oldStore, err := upgrade.Transaction.ObjectStore("old")
+			if err != nil {
+				return err
+			}
+			valuesReq, err := oldStore.GetValue("key")
+			if err != nil {
+				return err
+			}
+			go func() {
+				migrateErr <- func() error {
+					value, err := valuesReq.Await(ctx)
+					if err != nil {
+						return err
+					}
+					if _, err := newStore.PutKeyValue("key", value); err != nil {
+						return err
+					}
+					return upgrade.Database.DeleteObjectStore("old")
+				}()
+			}()
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	db, err = req.Await(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, <-migrateErr)
+
+	storeNames, err := db.ObjectStoreNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new"}, storeNames)
+	txn, err := db.Transaction(TransactionReadOnly, "new")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("new")
+	assert.NoError(t, err)
+	getReq, err := store.GetValue("key")
+	assert.NoError(t, err)
+	value, err := getReq.Await(ctx)
+	assert.NoError(t, err)
+	valueString, err := value.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "value", valueString)
+	assert.NoError(t, db.Close())
+
+	_, err = dbFactory.OpenWithOptions(ctx, name, OpenOptions{
+		Upgrader:  func(*Database, uint, uint) error { return nil },
+		OnUpgrade: func(Upgrade) error { return nil },
+	})
+	assert.Error(t, err)
+}
+
+func TestFactoryOpenUpgradeProgress(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+
+	req, err := dbFactory.OpenWithOptions(ctx, name, OpenOptions{
+		Version: 1,
+		OnUpgrade: func(upgrade Upgrade) error {
+			for i := uint(1); i <= 3; i++ {
+				upgrade.ReportProgress(UpgradeProgress{Step: "migrating", Migrated: i, Total: 3})
+			}
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	var last UpgradeProgress
+	received := make(chan struct{})
+	go func() {
+		defer close(received)
+		for progress := range req.Progress() {
+			last = progress
+		}
+	}()
+	db, err := req.Await(ctx)
+	assert.NoError(t, err)
+	<-received // the channel closes once the request succeeds
+	assert.Equal(t, UpgradeProgress{Step: "migrating", Migrated: 3, Total: 3}, last)
+	assert.NoError(t, db.Close())
+}
+
+func TestFactoryOpenCanceled(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := dbFactory.Open(ctx, name, 1, func(db *Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		return err
+	})
+	assert.NoError(t, err)
+	cancel()
+	_, err = req.Await(ctx)
+	assert.ErrorIs(t, err, ErrOpenCanceled)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the abandoned upgrade is aborted, so the next open upgrades the new database again
+	upgraded := false
+	req, err = dbFactory.Open(context.Background(), name, 1, func(*Database, uint, uint) error {
+		upgraded = true
+		return nil
+	})
+	assert.NoError(t, err)
+	timeoutCtx, timeoutCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer timeoutCancel()
+	db, err := req.Await(timeoutCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, true, upgraded)
+	assert.NoError(t, db.Close())
+
+	// no abandoned connection blocks deleting the database
+	deleteReq, err := dbFactory.DeleteDatabase(name)
+	assert.NoError(t, err)
+	assert.NoError(t, deleteReq.Await(timeoutCtx))
+}
+
+func TestIsSupported(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, true, IsSupported())
+	dbFactory, err := LoadGlobal()
+	assert.NoError(t, err)
+	assert.Equal(t, Global(), dbFactory)
+
+	_, err = lookupGlobal("missingIndexedDBPolyfill")
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
@@ -78,7 +78,7 @@ func TestDatabaseCreateObjectStore(t *testing.T) {
 		const storeName = "mystore"
 		db := testDB(t, func(db *Database) {
 			_, err := db.CreateObjectStore(storeName, ObjectStoreOptions{
-				KeyPath:       js.ValueOf("primary"),
+				KeyPath:       NewKeyPath("primary"),
 				AutoIncrement: true,
 			})
 			assert.NoError(t, err)
@@ -95,6 +95,21 @@ func TestDatabaseCreateObjectStore(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, true, autoIncrement)
 	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		t.Parallel()
+		testDB(t, func(db *Database) {
+			for _, options := range []ObjectStoreOptions{
+				{KeyPath: NewKeyPath("not valid")},
+				{KeyPath: NewKeyPath(""), AutoIncrement: true},
+				{KeyPath: NewCompoundKeyPath("a", "b"), AutoIncrement: true},
+			} {
+				assert.Error(t, options.Validate())
+				_, err := db.CreateObjectStore("mystore", options)
+				assert.Error(t, err)
+			}
+		})
+	})
 }
 
 func TestDatabaseDeleteObjectStore(t *testing.T) {
@@ -192,3 +207,74 @@ func TestDatabaseClose(t *testing.T) {
 	_, err = db.Transaction(TransactionReadOnly, "mystore")
 	assert.Error(t, err)
 }
+
+func TestDatabaseOnVersionChange(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := testDB(t, func(db *Database) {})
+	name, err := db.Name()
+	assert.NoError(t, err)
+
+	changes := make(chan VersionChange, 1)
+	assert.NoError(t, db.OnVersionChange(ctx, func(change VersionChange) {
+		changes <- change
+	}))
+	reqValue, err := Global().jsFactory.Call("open", name, 2)
+	assert.NoError(t, err)
+	otherDB, err := wrapRequest(nil, reqValue).Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, VersionChange{OldVersion: 1, NewVersion: 2}, <-changes)
+	_, err = otherDB.Call("close")
+	assert.NoError(t, err)
+}
+
+func TestDatabaseOnClose(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := testDB(t, func(db *Database) {})
+
+	closed := make(chan struct{}, 1)
+	assert.NoError(t, db.OnClose(ctx, func() {
+		closed <- struct{}{}
+	}))
+	// simulate the browser closing the connection
+	jsEvent, err := safejs.Global().Get("Event")
+	assert.NoError(t, err)
+	event, err := jsEvent.New("close")
+	assert.NoError(t, err)
+	_, err = db.jsDB.Call("dispatchEvent", event)
+	assert.NoError(t, err)
+	<-closed
+}
+
+func TestDatabaseWrap(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	dbFactory, err := WrapFactory(safejs.Unsafe(Global().Unwrap()))
+	assert.NoError(t, err)
+	assert.Equal(t, Global(), dbFactory)
+
+	wrapped := WrapDatabase(db.Unwrap())
+	names, err := wrapped.ObjectStoreNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mystore"}, names)
+
+	txn, err := wrapped.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	wrappedTxn, err := WrapTransaction(txn.Unwrap())
+	assert.NoError(t, err)
+	store, err := wrappedTxn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue("key", "value")
+	assert.NoError(t, err)
+	assert.NoError(t, wrappedTxn.Await(ctx))
+	txnDB, err := wrappedTxn.Database()
+	assert.NoError(t, err)
+	assert.Equal(t, true, txnDB.Unwrap().Equal(db.Unwrap()))
+}
@@ -0,0 +1,76 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+
+	"github.com/hack-pad/safejs"
+)
+
+// DeleteWhereOptions contains options for DeleteWhere.
+type DeleteWhereOptions struct {
+	// ChunkSize is the maximum number of records scanned per transaction. Defaults to 1000.
+	ChunkSize uint
+	// OnProgress is optionally called after each chunk's transaction completes. Return an error to stop deleting.
+	OnProgress func(DeleteProgress) error
+}
+
+// DeleteProgress reports the progress of DeleteWhere.
+type DeleteProgress struct {
+	// Scanned is the number of records scanned so far.
+	Scanned uint
+	// Deleted is the number of records deleted so far.
+	Deleted uint
+	// LastKey is the key of the last record scanned. Records up to and including LastKey have been processed and their deletions committed.
+	// To resume an interrupted job, call DeleteWhere again with ResumeKeyRange(keyRange, LastKey, true, CursorNext).
+	LastKey safejs.Value
+}
+
+// DeleteWhere deletes the records in keyRange of the object store named storeName for which predicate returns true. A nil keyRange scans all records.
+// Use it for cleanup jobs too complex to express as a key range passed to ObjectStore.Delete.
+//
+// Records are scanned in chunks, each in its own short read-write transaction, so other transactions on the store aren't blocked for the whole job.
+// If a transaction finishes prematurely, scanning resumes after the last record scanned.
+// Returns the number of records deleted.
+func DeleteWhere(ctx context.Context, db *Database, storeName string, keyRange *KeyRange, predicate func(key, value safejs.Value) (bool, error), options DeleteWhereOptions) (uint, error) {
+	var progress DeleteProgress
+	scan := chunkedScan{
+		db:        db,
+		storeName: storeName,
+		keyRange:  keyRange,
+		direction: CursorNext,
+		mode:      TransactionReadWrite,
+		chunkSize: options.ChunkSize,
+	}
+	if options.OnProgress != nil {
+		scan.chunkDone = func() error {
+			return options.OnProgress(progress)
+		}
+	}
+	err := scan.run(ctx, func(_ *Transaction, cursor *CursorWithValue) error {
+		key, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		matches, err := predicate(key, value)
+		if err != nil {
+			return err
+		}
+		if matches {
+			if _, err := cursor.Delete(); err != nil {
+				return err
+			}
+			progress.Deleted++
+		}
+		progress.Scanned++
+		progress.LastKey = key
+		return nil
+	})
+	return progress.Deleted, err
+}
@@ -0,0 +1,109 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestDeleteWhere(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	putRecords := func() {
+		t.Helper()
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			_, err := store.PutKeyValue(i, i*10)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, txn.Await(ctx))
+	}
+	remainingKeys := func() []int {
+		t.Helper()
+		txn, err := db.Transaction(TransactionReadOnly, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		req, err := store.GetAllKeys()
+		assert.NoError(t, err)
+		keys, err := req.Await(ctx)
+		assert.NoError(t, err)
+		var ints []int
+		for _, key := range keys {
+			n, err := key.Int()
+			assert.NoError(t, err)
+			ints = append(ints, n)
+		}
+		return ints
+	}
+	isMultipleOf := func(n int) func(key, value safejs.Value) (bool, error) {
+		return func(_, value safejs.Value) (bool, error) {
+			v, err := value.Int()
+			return v%n == 0, err
+		}
+	}
+
+	t.Run("deletes matching records", func(t *testing.T) {
+		putRecords()
+		var progress []DeleteProgress
+		deleted, err := DeleteWhere(ctx, db, "mystore", nil, isMultipleOf(20), DeleteWhereOptions{
+			ChunkSize: 4,
+			OnProgress: func(p DeleteProgress) error {
+				progress = append(progress, p)
+				return nil
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(5), deleted)
+		assert.Equal(t, []int{1, 3, 5, 7, 9}, remainingKeys())
+
+		var counts [][2]uint
+		var lastKeys []int
+		for _, p := range progress {
+			counts = append(counts, [2]uint{p.Scanned, p.Deleted})
+			lastKey, err := p.LastKey.Int()
+			assert.NoError(t, err)
+			lastKeys = append(lastKeys, lastKey)
+		}
+		assert.Equal(t, [][2]uint{{4, 2}, {8, 4}, {10, 5}}, counts)
+		assert.Equal(t, []int{3, 7, 9}, lastKeys)
+	})
+
+	t.Run("key range and resume", func(t *testing.T) {
+		putRecords()
+		stopErr := errors.New("stop")
+		var lastProgress DeleteProgress
+		keyRange, err := NewKeyRangeUpperBoundOf(7, false)
+		assert.NoError(t, err)
+		_, err = DeleteWhere(ctx, db, "mystore", keyRange, isMultipleOf(10), DeleteWhereOptions{
+			ChunkSize: 3,
+			OnProgress: func(p DeleteProgress) error {
+				lastProgress = p
+				return stopErr
+			},
+		})
+		assert.Equal(t, stopErr, err)
+		assert.Equal(t, []int{3, 4, 5, 6, 7, 8, 9}, remainingKeys())
+
+		keyRange, more, err := ResumeKeyRange(keyRange, lastProgress.LastKey, true, CursorNext)
+		assert.NoError(t, err)
+		assert.Equal(t, true, more)
+		deleted, err := DeleteWhere(ctx, db, "mystore", keyRange, isMultipleOf(10), DeleteWhereOptions{ChunkSize: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(5), deleted)
+		assert.Equal(t, []int{8, 9}, remainingKeys())
+	})
+}
@@ -0,0 +1,83 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"errors"
+	"log"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+const defaultMaxRequestGap = 10 * time.Millisecond
+
+var diagnostics atomic.Pointer[Diagnostics]
+
+// Diagnostics configures runtime warnings for transaction usage likely to hit auto-commit.
+//
+// IndexedDB commits a transaction automatically once it has no pending requests and control returns to the event loop.
+// In Go, that happens whenever a goroutine blocks on anything other than an IndexedDB request, like a channel, timer, or network call.
+// The next request then fails with "The transaction has finished."
+type Diagnostics struct {
+	// MaxRequestGap is the longest expected time between a transaction's last activity and its next request while no other requests are pending.
+	// Longer gaps log a warning. Defaults to 10ms.
+	MaxRequestGap time.Duration
+	// Logf logs warnings. Defaults to log.Printf.
+	Logf func(format string, args ...interface{})
+}
+
+// SetDiagnostics enables transaction diagnostics for transactions created afterward. Pass nil to disable them.
+//
+// Diagnostics log a stack trace with every warning, so only enable them while debugging.
+func SetDiagnostics(d *Diagnostics) {
+	diagnostics.Store(d)
+}
+
+func (d *Diagnostics) maxRequestGap() time.Duration {
+	if d.MaxRequestGap <= 0 {
+		return defaultMaxRequestGap
+	}
+	return d.MaxRequestGap
+}
+
+func (d *Diagnostics) warnf(format string, args ...interface{}) {
+	args = append(args, debug.Stack())
+	format = "idb: " + format + "\n%s"
+	if d.Logf != nil {
+		d.Logf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+// warnIfTxnInactive logs a warning if err indicates a request was made after its transaction finished.
+func warnIfTxnInactive(err error) {
+	d := diagnostics.Load()
+	if d == nil || !errors.Is(err, ErrTransactionInactive) {
+		return
+	}
+	d.warnf("%v\nThe transaction committed automatically before this request was made. Avoid blocking on non-IndexedDB work between a transaction's requests, or use RetryTxn to retry with a new transaction.", err)
+}
+
+// txnDiagnostics warns about a transaction's requests which allow it to commit automatically. Its requests are tracked by the transaction's requestRegistry.
+type txnDiagnostics struct {
+	config *Diagnostics
+}
+
+// newTxnDiagnostics returns diagnostics for a new transaction, or nil if diagnostics are disabled.
+func newTxnDiagnostics() *txnDiagnostics {
+	config := diagnostics.Load()
+	if config == nil {
+		return nil
+	}
+	return &txnDiagnostics{config: config}
+}
+
+// checkRequestGap warns if a request was made gap after the transaction's last activity, with no other requests pending.
+func (d *txnDiagnostics) checkRequestGap(gap time.Duration) {
+	if gap > d.config.maxRequestGap() {
+		d.config.warnf("request made %s after the transaction's last activity with no other requests pending. If the goroutine blocked on something other than an IndexedDB request in between, the transaction may have committed automatically.", gap)
+	}
+}
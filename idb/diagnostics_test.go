@@ -0,0 +1,80 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestDiagnostics(t *testing.T) { // not parallel: diagnostics are global
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	var warnings []string
+	SetDiagnostics(&Diagnostics{
+		MaxRequestGap: 20 * time.Millisecond,
+		Logf: func(format string, args ...interface{}) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	})
+	t.Cleanup(func() {
+		SetDiagnostics(nil)
+	})
+
+	t.Run("no warnings", func(t *testing.T) {
+		warnings = nil
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			req, err := store.PutKey(safejs.Safe(js.ValueOf(i)), safejs.Safe(js.ValueOf(i)))
+			assert.NoError(t, err)
+			_, err = req.Await(ctx)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, txn.Await(ctx))
+		assert.Equal(t, []string(nil), warnings)
+	})
+
+	t.Run("request gap", func(t *testing.T) {
+		warnings = nil
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		// busy wait without yielding, so the transaction stays active
+		for start := time.Now(); time.Since(start) < 40*time.Millisecond; {
+		}
+		_, err = store.PutKey(safejs.Safe(js.ValueOf(1)), safejs.Safe(js.ValueOf(1)))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(warnings))
+		assert.Equal(t, true, strings.Contains(warnings[0], "after the transaction's last activity"))
+		assert.NoError(t, txn.Await(ctx))
+	})
+
+	t.Run("finished transaction", func(t *testing.T) {
+		warnings = nil
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		time.Sleep(50 * time.Millisecond) // yield so the transaction commits
+		_, err = store.PutKey(safejs.Safe(js.ValueOf(1)), safejs.Safe(js.ValueOf(1)))
+		assert.Error(t, err)
+		assert.Equal(t, true, IsTxnFinishedErr(err))
+		assert.Equal(t, 1, len(warnings))
+		assert.Equal(t, true, strings.Contains(warnings[0], "committed automatically before this request was made"))
+	})
+}
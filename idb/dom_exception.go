@@ -4,18 +4,19 @@
 package idb
 
 import (
+	"errors"
 	"syscall/js"
 
 	"github.com/hack-pad/safejs"
 )
 
 func tryAsDOMException(err error) error {
-	switch err := err.(type) {
-	case js.Error:
-		return domExceptionAsError(safejs.Safe(err.Value))
-	default:
-		return err
+	var jsErr js.Error
+	if errors.As(err, &jsErr) {
+		err = domExceptionAsError(safejs.Safe(jsErr.Value))
+		warnIfTxnInactive(err)
 	}
+	return err
 }
 
 func domExceptionAsError(jsDOMException safejs.Value) error {
@@ -30,6 +31,39 @@ func domExceptionAsError(jsDOMException safejs.Value) error {
 	return domException
 }
 
+// The standard DOMExceptions thrown by IndexedDB. Errors returned from idb match them by name with errors.Is(), even when wrapped with more context.
+// Use errors.As() with a DOMException to read the browser's message.
+var (
+	// ErrAbort is returned for requests in a transaction which was aborted.
+	ErrAbort = NewDOMException("AbortError")
+	// ErrConstraint is returned when a write breaks a constraint, like adding a record whose key already exists or which duplicates a unique index key.
+	ErrConstraint = NewDOMException("ConstraintError")
+	// ErrData is returned for invalid keys and key ranges, and for values whose key path doesn't evaluate to a valid key.
+	ErrData = NewDOMException("DataError")
+	// ErrDataClone is returned when a value can't be stored because the structured clone algorithm doesn't support it, like functions.
+	ErrDataClone = NewDOMException("DataCloneError")
+	// ErrInvalidAccess is returned for invalid arguments, like an empty list of object store names for a transaction or an array key path with auto-increment.
+	ErrInvalidAccess = NewDOMException("InvalidAccessError")
+	// ErrInvalidState is returned for operations made in the wrong state, like calls on a deleted object store, a closed database, or a cursor which is already iterating.
+	ErrInvalidState = NewDOMException("InvalidStateError")
+	// ErrNotFound is returned when an object store or index doesn't exist.
+	ErrNotFound = NewDOMException("NotFoundError")
+	// ErrQuotaExceeded is returned when the origin runs out of storage quota.
+	ErrQuotaExceeded = NewDOMException("QuotaExceededError")
+	// ErrReadOnly is returned for writes in a read-only transaction.
+	ErrReadOnly = NewDOMException("ReadOnlyError")
+	// ErrSyntax is returned for invalid key paths.
+	ErrSyntax = NewDOMException("SyntaxError")
+	// ErrTimeout is returned when a transaction times out, like when acquiring its locks takes too long in some browsers.
+	ErrTimeout = NewDOMException("TimeoutError")
+	// ErrTransactionInactive is returned for requests made in a transaction which already committed, or which isn't active because control hasn't returned to the event loop.
+	ErrTransactionInactive = NewDOMException("TransactionInactiveError")
+	// ErrUnknown is returned for transient failures, like I/O errors reading from disk.
+	ErrUnknown = NewDOMException("UnknownError")
+	// ErrVersion is returned when opening a database with a version lower than its current version.
+	ErrVersion = NewDOMException("VersionError")
+)
+
 // DOMException is a JavaScript DOMException with a standard name.
 // Use errors.Is() to compare by name.
 type DOMException struct {
@@ -66,6 +100,16 @@ func parseJSDOMException(jsDOMException safejs.Value) (DOMException, error) {
 	}, nil
 }
 
+// Name returns the exception's name, like "ConstraintError".
+func (e DOMException) Name() string {
+	return e.name
+}
+
+// Message returns the browser's description of the exception. Messages vary between browsers and locales, so compare errors by name instead.
+func (e DOMException) Message() string {
+	return e.message
+}
+
 func (e DOMException) Error() string {
 	if e.message == "" {
 		return e.name
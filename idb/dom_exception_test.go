@@ -4,6 +4,8 @@
 package idb
 
 import (
+	"errors"
+	"fmt"
 	"syscall/js"
 	"testing"
 
@@ -47,3 +49,17 @@ func TestDOMExceptionAsError(t *testing.T) {
 	assert.ErrorIs(t, exception, DOMException{name: "name"})
 	assert.NotErrorIs(t, exception, DOMException{name: "other name"})
 }
+
+func TestDOMExceptionNames(t *testing.T) {
+	t.Parallel()
+	exceptionJS, err := domException.New("Key already exists in the object store.", "ConstraintError")
+	assert.NoError(t, err)
+	err = fmt.Errorf("adding user: %w", tryAsDOMException(js.Error{Value: safejs.Unsafe(exceptionJS)}))
+
+	assert.ErrorIs(t, err, ErrConstraint)
+	assert.NotErrorIs(t, err, ErrData)
+	var exception DOMException
+	assert.Equal(t, true, errors.As(err, &exception))
+	assert.Equal(t, "ConstraintError", exception.Name())
+	assert.Equal(t, "Key already exists in the object store.", exception.Message())
+}
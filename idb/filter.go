@@ -0,0 +1,364 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/hack-pad/safejs"
+)
+
+const defaultBatchSize = 100
+
+var jsArray safejs.Value
+
+func init() {
+	var err error
+	jsArray, err = safejs.Global().Get("Array")
+	if err != nil {
+		panic(err)
+	}
+}
+
+type filterOp int
+
+const (
+	filterEq filterOp = iota
+	filterGt
+	filterGte
+	filterLt
+	filterLte
+	filterContains
+	filterAnd
+	filterOr
+	filterNot
+)
+
+// Filter is a predicate over record values, evaluated in Go after records are read. Build filters with Eq, Gt, Gte, Lt, Lte, Contains, And, Or, and Not.
+//
+// Fields are dotted paths into the value, like key paths. Comparisons use IndexedDB's key ordering, so a field only matches a comparison if both its value and the compared value are valid keys.
+type Filter struct {
+	op      filterOp
+	field   string
+	value   interface{}
+	filters []Filter
+}
+
+// Eq matches records where field equals value.
+func Eq(field string, value interface{}) Filter {
+	return Filter{op: filterEq, field: field, value: value}
+}
+
+// Gt matches records where field is greater than value.
+func Gt(field string, value interface{}) Filter {
+	return Filter{op: filterGt, field: field, value: value}
+}
+
+// Gte matches records where field is greater than or equal to value.
+func Gte(field string, value interface{}) Filter {
+	return Filter{op: filterGte, field: field, value: value}
+}
+
+// Lt matches records where field is less than value.
+func Lt(field string, value interface{}) Filter {
+	return Filter{op: filterLt, field: field, value: value}
+}
+
+// Lte matches records where field is less than or equal to value.
+func Lte(field string, value interface{}) Filter {
+	return Filter{op: filterLte, field: field, value: value}
+}
+
+// Contains matches records where field is an array with an element equal to value, or a string containing the string value.
+func Contains(field string, value interface{}) Filter {
+	return Filter{op: filterContains, field: field, value: value}
+}
+
+// And matches records matching all of filters. An empty And matches every record.
+func And(filters ...Filter) Filter {
+	return Filter{op: filterAnd, filters: filters}
+}
+
+// Or matches records matching any of filters. An empty Or matches no records.
+func Or(filters ...Filter) Filter {
+	return Filter{op: filterOr, filters: filters}
+}
+
+// Not matches records which don't match filter.
+func Not(filter Filter) Filter {
+	return Filter{op: filterNot, filters: []Filter{filter}}
+}
+
+// Matcher reports whether a record's value matches a compiled Filter.
+type Matcher func(value safejs.Value) (bool, error)
+
+// Compile converts f into a Matcher. Compared values are converted to JavaScript once, so compile a filter once and reuse its Matcher for every record.
+func (f Filter) Compile() (Matcher, error) {
+	switch f.op {
+	case filterAnd, filterOr:
+		matchers := make([]Matcher, 0, len(f.filters))
+		for _, filter := range f.filters {
+			matcher, err := filter.Compile()
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, matcher)
+		}
+		all := f.op == filterAnd
+		return func(value safejs.Value) (bool, error) {
+			for _, matcher := range matchers {
+				matches, err := matcher(value)
+				if err != nil || matches != all {
+					return matches, err
+				}
+			}
+			return all, nil
+		}, nil
+	case filterNot:
+		matcher, err := f.filters[0].Compile()
+		if err != nil {
+			return nil, err
+		}
+		return func(value safejs.Value) (bool, error) {
+			matches, err := matcher(value)
+			return !matches, err
+		}, nil
+	}
+
+	operand, err := ValueOf(f.value)
+	if err != nil {
+		return nil, err
+	}
+	field := f.field
+	if f.op == filterContains {
+		operandString, isString := f.value.(string)
+		return func(value safejs.Value) (bool, error) {
+			fieldValue, ok, err := getValuePath(value, field)
+			if err != nil || !ok {
+				return false, err
+			}
+			if fieldValue.Type() == safejs.TypeString {
+				if !isString {
+					return false, nil
+				}
+				s, err := fieldValue.String()
+				return strings.Contains(s, operandString), err
+			}
+			isArray, err := fieldValue.InstanceOf(jsArray)
+			if err != nil || !isArray {
+				return false, err
+			}
+			found := false
+			err = iterArray(fieldValue, func(_ int, element safejs.Value) (bool, error) {
+				cmp, valid, err := compareFieldKeys(element, operand)
+				found = valid && cmp == 0
+				return !found, err
+			})
+			return found, err
+		}, nil
+	}
+
+	op := f.op
+	return func(value safejs.Value) (bool, error) {
+		fieldValue, ok, err := getValuePath(value, field)
+		if err != nil || !ok {
+			return false, err
+		}
+		cmp, valid, err := compareFieldKeys(fieldValue, operand)
+		if err != nil || !valid {
+			return false, err
+		}
+		switch op {
+		case filterEq:
+			return cmp == 0, nil
+		case filterGt:
+			return cmp > 0, nil
+		case filterGte:
+			return cmp >= 0, nil
+		case filterLt:
+			return cmp < 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	}, nil
+}
+
+// compareFieldKeys compares a and b as keys. Returns false if either isn't a valid key.
+func compareFieldKeys(a, b safejs.Value) (int, bool, error) {
+	cmp, err := compareKeys(a, b)
+	if errors.Is(err, ErrData) {
+		return 0, false, nil
+	}
+	return cmp, err == nil, err
+}
+
+// conjuncts returns the filters which must all match for f to match.
+func (f Filter) conjuncts() []Filter {
+	if f.op != filterAnd {
+		return []Filter{f}
+	}
+	var conjuncts []Filter
+	for _, filter := range f.filters {
+		conjuncts = append(conjuncts, filter.conjuncts()...)
+	}
+	return conjuncts
+}
+
+// fieldBounds returns the range of keys field must be in for f to match, combining every comparison on field which must match.
+// Returns false if f doesn't constrain field to a range.
+func (f Filter) fieldBounds(field string) (bounds rangeBounds, constrained bool, err error) {
+	for _, filter := range f.conjuncts() {
+		if filter.field != field || filter.op > filterLte {
+			continue
+		}
+		operand, err := ValueOf(filter.value)
+		if err != nil {
+			return bounds, false, err
+		}
+		if _, valid, err := compareFieldKeys(operand, operand); err != nil || !valid {
+			if err != nil {
+				return bounds, false, err
+			}
+			continue // can't be a key range, so the filter matches nothing and is left to the Matcher
+		}
+		constrained = true
+		if filter.op != filterLt && filter.op != filterLte {
+			open := filter.op == filterGt
+			if err := bounds.restrictLower(operand, open); err != nil {
+				return bounds, false, err
+			}
+		}
+		if filter.op != filterGt && filter.op != filterGte {
+			open := filter.op == filterLt
+			if err := bounds.restrictUpper(operand, open); err != nil {
+				return bounds, false, err
+			}
+		}
+	}
+	return bounds, constrained, nil
+}
+
+func (b *rangeBounds) restrictLower(key safejs.Value, open bool) error {
+	if b.hasLower {
+		cmp, err := compareKeys(key, b.lower)
+		if err != nil || cmp < 0 || (cmp == 0 && !open) {
+			return err
+		}
+	}
+	b.lower, b.hasLower, b.lowerOpen = key, true, open
+	return nil
+}
+
+func (b *rangeBounds) restrictUpper(key safejs.Value, open bool) error {
+	if b.hasUpper {
+		cmp, err := compareKeys(key, b.upper)
+		if err != nil || cmp > 0 || (cmp == 0 && !open) {
+			return err
+		}
+	}
+	b.upper, b.hasUpper, b.upperOpen = key, true, open
+	return nil
+}
+
+// isEmpty returns true if no keys are in the range.
+func (b rangeBounds) isEmpty() (bool, error) {
+	if !b.hasLower || !b.hasUpper {
+		return false, nil
+	}
+	cmp, err := compareKeys(b.lower, b.upper)
+	return cmp > 0 || (cmp == 0 && (b.lowerOpen || b.upperOpen)), err
+}
+
+// Query calls iter with the primary key and value of each record in the object store matching filter. Return ErrCursorStopIter to stop early.
+//
+// If filter requires a field to be in a range with Eq, Gt, Gte, Lt, or Lte, and that field is the store's key path or a non-multi-entry index's key path,
+// only that range of the store or index is read, in key order. Fields compared with Eq are preferred. Otherwise, every record is read in primary key order.
+func (o *ObjectStore) Query(ctx context.Context, filter Filter, iter func(primaryKey, value safejs.Value) error) error {
+	matcher, err := filter.Compile()
+	if err != nil {
+		return err
+	}
+	req, empty, err := o.planQuery(filter)
+	if err != nil || empty {
+		return err
+	}
+	return req.IterBatch(ctx, defaultBatchSize, func(primaryKey, value safejs.Value) error {
+		matches, err := matcher(value)
+		if err != nil || !matches {
+			return err
+		}
+		return iter(primaryKey, value)
+	})
+}
+
+// planQuery opens a cursor over the smallest range of the store or one of its indexes which could match filter. Returns true if no records can match.
+func (o *ObjectStore) planQuery(filter Filter) (_ *CursorWithValueRequest, empty bool, err error) {
+	sources := make(map[string]*Index) // key path field to index, or nil for the store's key path
+	keyPath, err := o.TypedKeyPath()
+	if err != nil {
+		return nil, false, err
+	}
+	if keyPath.Kind() == KeyPathSingle {
+		sources[keyPath.Path()] = nil
+	}
+	indexNames, err := o.IndexNames()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, name := range indexNames {
+		index, err := o.Index(name)
+		if err != nil {
+			return nil, false, err
+		}
+		indexKeyPath, err := index.TypedKeyPath()
+		if err != nil {
+			return nil, false, err
+		}
+		multiEntry, err := index.MultiEntry()
+		if err != nil {
+			return nil, false, err
+		}
+		if _, exists := sources[indexKeyPath.Path()]; indexKeyPath.Kind() == KeyPathSingle && !multiEntry && !exists {
+			sources[indexKeyPath.Path()] = index
+		}
+	}
+
+	var bestField string
+	var bestBounds rangeBounds
+	bestEq := false
+	for _, conjunct := range filter.conjuncts() {
+		if _, ok := sources[conjunct.field]; conjunct.op > filterLte || !ok || (bestField != "" && (bestEq || conjunct.op != filterEq)) {
+			continue
+		}
+		bounds, constrained, err := filter.fieldBounds(conjunct.field)
+		if err != nil {
+			return nil, false, err
+		}
+		if !constrained {
+			continue
+		}
+		bestField, bestBounds, bestEq = conjunct.field, bounds, conjunct.op == filterEq
+	}
+	if bestField == "" {
+		req, err := o.OpenCursor(CursorNext)
+		return req, false, err
+	}
+
+	empty, err = bestBounds.isEmpty()
+	if err != nil || empty {
+		return nil, empty, err
+	}
+	keyRange, err := bestBounds.keyRange()
+	if err != nil {
+		return nil, false, err
+	}
+	if index := sources[bestField]; index != nil {
+		req, err := index.OpenCursorRange(keyRange, CursorNext)
+		return req, false, err
+	}
+	req, err := o.OpenCursorRange(keyRange, CursorNext)
+	return req, false, err
+}
@@ -0,0 +1,131 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestFilterCompile(t *testing.T) {
+	t.Parallel()
+	value, err := ValueOf(map[string]interface{}{
+		"name": "gopher",
+		"age":  13,
+		"tags": []interface{}{"go", "wasm"},
+		"address": map[string]interface{}{
+			"city": "Mountain View",
+		},
+		"active": true,
+	})
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		filter Filter
+		expect bool
+	}{
+		{name: "eq", filter: Eq("name", "gopher"), expect: true},
+		{name: "eq mismatch", filter: Eq("name", "rustacean"), expect: false},
+		{name: "eq nested", filter: Eq("address.city", "Mountain View"), expect: true},
+		{name: "eq missing field", filter: Eq("missing", 1), expect: false},
+		{name: "eq invalid key", filter: Eq("active", true), expect: false},
+		{name: "gt", filter: Gt("age", 12), expect: true},
+		{name: "gt equal", filter: Gt("age", 13), expect: false},
+		{name: "gte", filter: Gte("age", 13), expect: true},
+		{name: "lt", filter: Lt("age", 13), expect: false},
+		{name: "lte", filter: Lte("age", 13), expect: true},
+		{name: "key ordering", filter: Gt("name", 100), expect: true},
+		{name: "contains array", filter: Contains("tags", "wasm"), expect: true},
+		{name: "contains array mismatch", filter: Contains("tags", "js"), expect: false},
+		{name: "contains string", filter: Contains("name", "ph"), expect: true},
+		{name: "contains string mismatch", filter: Contains("name", 1), expect: false},
+		{name: "and", filter: And(Eq("name", "gopher"), Gte("age", 10), Lt("age", 20)), expect: true},
+		{name: "and mismatch", filter: And(Eq("name", "gopher"), Gt("age", 20)), expect: false},
+		{name: "empty and", filter: And(), expect: true},
+		{name: "or", filter: Or(Eq("name", "rustacean"), Contains("tags", "go")), expect: true},
+		{name: "or mismatch", filter: Or(Eq("name", "rustacean"), Lt("age", 0)), expect: false},
+		{name: "empty or", filter: Or(), expect: false},
+		{name: "not", filter: Not(Eq("name", "rustacean")), expect: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			matcher, err := tc.filter.Compile()
+			assert.NoError(t, err)
+			matches, err := matcher(value)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, matches)
+		})
+	}
+
+	_, err = Eq("name", struct{}{}).Compile()
+	assert.Error(t, err)
+}
+
+func TestObjectStoreQuery(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{KeyPath: NewKeyPath("id")})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("group", NewKeyPath("group"), IndexOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("score", NewKeyPath("score"), IndexOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := store.PutValue(map[string]interface{}{"id": i, "group": i % 2, "score": 10 - i})
+		assert.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		filter    Filter
+		expectIDs []int
+		plan      string
+	}{
+		{name: "store key range", filter: And(Gte("id", 3), Lt("id", 6)), expectIDs: []int{3, 4, 5}, plan: "store"},
+		{name: "index range", filter: Lte("score", 3), expectIDs: []int{9, 8, 7}, plan: "score"},
+		{name: "prefers eq", filter: And(Gt("score", 4), Eq("group", 1)), expectIDs: []int{1, 3, 5}, plan: "group"},
+		{name: "no index", filter: Contains("missing", 1), expectIDs: nil, plan: "scan"},
+		{name: "or", filter: Or(Eq("id", 1), Eq("id", 8)), expectIDs: []int{1, 8}, plan: "scan"},
+		{name: "empty range", filter: And(Gt("id", 5), Lt("id", 3)), expectIDs: nil, plan: "empty"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, empty, err := store.planQuery(tc.filter)
+			assert.NoError(t, err)
+			plan := "empty"
+			if !empty {
+				objectStore, index, err := req.Source()
+				assert.NoError(t, err)
+				switch {
+				case index != nil:
+					plan, err = index.Name()
+					assert.NoError(t, err)
+				case req.batch.keyRange != nil && objectStore != nil:
+					plan = "store"
+				default:
+					plan = "scan"
+				}
+			}
+			assert.Equal(t, tc.plan, plan)
+
+			var ids []int
+			assert.NoError(t, store.Query(ctx, tc.filter, func(primaryKey, value safejs.Value) error {
+				id, err := primaryKey.Int()
+				ids = append(ids, id)
+				return err
+			}))
+			assert.Equal(t, tc.expectIDs, ids)
+		})
+	}
+}
@@ -0,0 +1,343 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// ErrStoreFrozen is returned when starting a read-write transaction on an object store frozen with Database.FreezeStore.
+var ErrStoreFrozen = errors.New("object store is frozen")
+
+const (
+	// freezeChannelPrefix prefixes the database name in the name of the BroadcastChannel freezes are announced on.
+	freezeChannelPrefix = "go-indexeddb-freeze:"
+	// freezeHeartbeat is how often a freeze is announced again, for tabs which started listening after it began.
+	freezeHeartbeat = time.Second
+	// freezeExpiry is how long a freeze from another tab holds without being announced again, so a closed tab can't leave a store frozen.
+	freezeExpiry = 5 * freezeHeartbeat
+)
+
+// StoreFreeze holds an object store frozen by Database.FreezeStore. Only its own transactions may write to the store until Unfreeze is called.
+type StoreFreeze struct {
+	db           *Database
+	name         string
+	id           string // identifies the freeze in announcements to other tabs
+	channel      *freezeChannel
+	stop         chan struct{}
+	unfreezeOnce sync.Once
+}
+
+// FreezeStore rejects new read-write transactions on the named object store with ErrStoreFrozen, then waits for already started read-write transactions to finish.
+// Use it to keep live writes from racing with maintenance jobs, like compaction, reindexing, or exports. Write to the store during maintenance with the returned StoreFreeze's Transaction method, then call Unfreeze.
+//
+// The freeze is announced to other tabs on a BroadcastChannel, so read-write transactions on the store fail with ErrStoreFrozen in tabs which called WatchFreezes too.
+// Announcements take a moment to arrive, so another tab may still start a write just after FreezeStore returns. A tab which stops announcing its freeze, like one which was closed, releases it after a few seconds.
+func (db *Database) FreezeStore(ctx context.Context, name string) (*StoreFreeze, error) {
+	channel, err := db.watchFreezes()
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	db.frozenMu.Lock()
+	if db.frozen[name] != nil || channel.frozen(name) {
+		db.frozenMu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrStoreFrozen, name)
+	}
+	if db.frozen == nil {
+		db.frozen = make(map[string]*StoreFreeze)
+	}
+	freeze := &StoreFreeze{db: db, name: name, id: hex.EncodeToString(id), channel: channel, stop: make(chan struct{})}
+	db.frozen[name] = freeze
+	db.frozenMu.Unlock()
+	channel.mu.Lock()
+	channel.local[freeze] = true
+	channel.mu.Unlock()
+
+	if err := channel.post(freezeAnnounce, name, freeze.id); err != nil {
+		freeze.Unfreeze()
+		return nil, err
+	}
+	go freeze.heartbeat()
+
+	// IndexedDB runs overlapping read-write transactions in the order they were created, so this one finishes after all earlier writers
+	txn, err := freeze.Transaction(TransactionReadWrite, name)
+	if err == nil {
+		err = txn.Await(ctx)
+	}
+	if err != nil {
+		freeze.Unfreeze()
+		return nil, err
+	}
+	return freeze, nil
+}
+
+// Transaction starts a transaction that may write to the frozen object store. See Database.Transaction.
+func (f *StoreFreeze) Transaction(mode TransactionMode, objectStoreName string, objectStoreNames ...string) (*Transaction, error) {
+	return f.TransactionWithOptions(TransactionOptions{Mode: mode}, objectStoreName, objectStoreNames...)
+}
+
+// TransactionWithOptions starts a transaction that may write to the frozen object store. See Database.TransactionWithOptions.
+func (f *StoreFreeze) TransactionWithOptions(options TransactionOptions, objectStoreName string, objectStoreNames ...string) (*Transaction, error) {
+	return f.db.transaction(options, f, objectStoreName, objectStoreNames...)
+}
+
+// Unfreeze allows writes to the object store again, here and in other tabs. Transactions already started by f are unaffected. Calling Unfreeze more than once has no effect.
+func (f *StoreFreeze) Unfreeze() {
+	f.unfreezeOnce.Do(func() {
+		close(f.stop)
+		f.channel.mu.Lock()
+		delete(f.channel.local, f)
+		f.channel.mu.Unlock()
+		_ = f.channel.post(freezeRelease, f.name, f.id)
+		f.db.frozenMu.Lock()
+		defer f.db.frozenMu.Unlock()
+		if f.db.frozen[f.name] == f {
+			delete(f.db.frozen, f.name)
+		}
+	})
+}
+
+// heartbeat announces the freeze again until it's unfrozen.
+func (f *StoreFreeze) heartbeat() {
+	ticker := time.NewTicker(freezeHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			_ = f.channel.post(freezeAnnounce, f.name, f.id)
+		}
+	}
+}
+
+// checkFrozen returns an error if any of storeNames is frozen by a freeze other than allowed, or by another tab if db watches for freezes.
+func (db *Database) checkFrozen(allowed *StoreFreeze, storeNames []string) error {
+	db.frozenMu.Lock()
+	defer db.frozenMu.Unlock()
+	for _, name := range storeNames {
+		if freeze := db.frozen[name]; (freeze != nil && freeze != allowed) || (db.freezeWatch != nil && db.freezeWatch.frozen(name)) {
+			return fmt.Errorf("%w: %s", ErrStoreFrozen, name)
+		}
+	}
+	return nil
+}
+
+// WatchFreezes listens for object stores frozen with FreezeStore in other tabs, so this Database's read-write transactions on them fail with ErrStoreFrozen.
+// Call it right after opening the database in tabs which write to stores other tabs may freeze. FreezeStore starts watching automatically.
+// Freezes which began before WatchFreezes are learned a moment later, once the freezing tab answers. Watching stops when the Database is closed.
+func (db *Database) WatchFreezes() error {
+	_, err := db.watchFreezes()
+	return err
+}
+
+func (db *Database) watchFreezes() (*freezeChannel, error) {
+	db.frozenMu.Lock()
+	defer db.frozenMu.Unlock()
+	if db.freezeWatch != nil {
+		return db.freezeWatch, nil
+	}
+	dbName, err := db.Name()
+	if err != nil {
+		return nil, err
+	}
+	channel, err := openFreezeChannel(dbName)
+	if err != nil {
+		return nil, err
+	}
+	db.freezeWatch = channel
+	return channel, nil
+}
+
+// stopWatchingFreezes unfreezes the stores db froze and stops watching for freezes from other tabs, once db is closed.
+func (db *Database) stopWatchingFreezes() {
+	db.frozenMu.Lock()
+	channel := db.freezeWatch
+	db.freezeWatch = nil
+	freezes := make([]*StoreFreeze, 0, len(db.frozen))
+	for _, freeze := range db.frozen {
+		freezes = append(freezes, freeze)
+	}
+	db.frozenMu.Unlock()
+	for _, freeze := range freezes {
+		freeze.Unfreeze()
+	}
+	if channel != nil {
+		channel.release()
+	}
+}
+
+// freezeMessageType is the kind of message posted to a freeze BroadcastChannel.
+type freezeMessageType string
+
+const (
+	freezeAnnounce freezeMessageType = "freeze"
+	freezeRelease  freezeMessageType = "unfreeze"
+	// freezeQuery asks other tabs to announce their freezes, sent when a tab starts listening.
+	freezeQuery freezeMessageType = "query"
+)
+
+// freezeChannel tracks the freezes other tabs announce for a database. It's shared by every Database watching the same database, and closed once the last of them closes.
+type freezeChannel struct {
+	name      string
+	channel   safejs.Value
+	onMessage safejs.Func
+	refs      int // guarded by freezeChannels
+
+	mu sync.Mutex
+	// remote holds the time each freeze of each store was last announced, by store name and freeze ID
+	remote map[string]map[string]time.Time
+	// local holds this program's freezes, to answer queries from other tabs
+	local map[*StoreFreeze]bool
+}
+
+// freezeChannels holds the open freezeChannel of each database watched for freezes, by database name.
+var freezeChannels = struct {
+	sync.Mutex
+	byName map[string]*freezeChannel
+}{byName: make(map[string]*freezeChannel)}
+
+// openFreezeChannel returns the freezeChannel for the database named dbName, listening for freezes from other tabs if it isn't already. Call release when done with it.
+func openFreezeChannel(dbName string) (*freezeChannel, error) {
+	freezeChannels.Lock()
+	defer freezeChannels.Unlock()
+	if channel := freezeChannels.byName[dbName]; channel != nil {
+		channel.refs++
+		return channel, nil
+	}
+	jsBroadcastChannel, err := safejs.Global().Get("BroadcastChannel")
+	if err != nil {
+		return nil, err
+	}
+	if jsBroadcastChannel.IsUndefined() {
+		return nil, errors.New("watching store freezes requires BroadcastChannel, which is not supported in this environment")
+	}
+	channel := &freezeChannel{
+		name:   dbName,
+		refs:   1,
+		remote: make(map[string]map[string]time.Time),
+		local:  make(map[*StoreFreeze]bool),
+	}
+	channel.channel, err = jsBroadcastChannel.New(freezeChannelPrefix + dbName)
+	if err != nil {
+		return nil, tryAsDOMException(err)
+	}
+	channel.onMessage, err = safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		if data, err := args[0].Get("data"); err == nil {
+			channel.receive(data)
+		}
+		return nil
+	})
+	if err != nil {
+		_, _ = channel.channel.Call("close")
+		return nil, err
+	}
+	if _, err := channel.channel.Call(addEventListener, "message", channel.onMessage); err != nil {
+		channel.close()
+		return nil, tryAsDOMException(err)
+	}
+	if err := channel.post(freezeQuery, "", ""); err != nil {
+		channel.close()
+		return nil, err
+	}
+	freezeChannels.byName[dbName] = channel
+	return channel, nil
+}
+
+// release closes the channel once every Database watching it is done with it.
+func (c *freezeChannel) release() {
+	freezeChannels.Lock()
+	defer freezeChannels.Unlock()
+	c.refs--
+	if c.refs > 0 {
+		return
+	}
+	if freezeChannels.byName[c.name] == c {
+		delete(freezeChannels.byName, c.name)
+	}
+	c.close()
+}
+
+func (c *freezeChannel) close() {
+	_, _ = c.channel.Call("close")
+	c.onMessage.Release()
+}
+
+// post sends a message to other tabs.
+func (c *freezeChannel) post(messageType freezeMessageType, storeName, id string) error {
+	message, err := ValueOf(map[string]interface{}{
+		"type":  string(messageType),
+		"store": storeName,
+		"id":    id,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.channel.Call("postMessage", message)
+	return tryAsDOMException(err)
+}
+
+// receive records a message from another tab.
+func (c *freezeChannel) receive(data safejs.Value) {
+	if dataType, ok := safeType(data); !ok || dataType != safejs.TypeObject {
+		return
+	}
+	messageType, err := jsGetString(data, "type")
+	if err != nil {
+		return
+	}
+	storeName, err := jsGetString(data, "store")
+	if err != nil {
+		return
+	}
+	id, err := jsGetString(data, "id")
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	var local []*StoreFreeze
+	switch freezeMessageType(messageType) {
+	case freezeAnnounce:
+		if c.remote[storeName] == nil {
+			c.remote[storeName] = make(map[string]time.Time)
+		}
+		c.remote[storeName][id] = time.Now()
+	case freezeRelease:
+		delete(c.remote[storeName], id)
+	case freezeQuery:
+		for freeze := range c.local {
+			local = append(local, freeze)
+		}
+	}
+	c.mu.Unlock()
+	for _, freeze := range local {
+		_ = c.post(freezeAnnounce, freeze.name, freeze.id)
+	}
+}
+
+// frozen returns true if another tab has announced a freeze of storeName which hasn't expired.
+func (c *freezeChannel) frozen(storeName string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, announced := range c.remote[storeName] {
+		if time.Since(announced) < freezeExpiry {
+			return true
+		}
+		delete(c.remote[storeName], id)
+	}
+	return false
+}
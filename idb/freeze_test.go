@@ -0,0 +1,149 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestFreezeStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("other", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+
+	// a write started before the freeze finishes before FreezeStore returns
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue("before", "value")
+	assert.NoError(t, err)
+
+	freeze, err := db.FreezeStore(ctx, "mystore")
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Err())
+
+	_, err = db.Transaction(TransactionReadWrite, "other", "mystore")
+	assert.ErrorIs(t, err, ErrStoreFrozen)
+	_, err = db.FreezeStore(ctx, "mystore")
+	assert.ErrorIs(t, err, ErrStoreFrozen)
+	_, err = db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	_, err = db.Transaction(TransactionReadWrite, "other")
+	assert.NoError(t, err)
+
+	txn, err = freeze.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue("during", "value")
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	freeze.Unfreeze()
+	freeze.Unfreeze()
+	_, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+}
+
+func TestFreezeStoreAcrossTabs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	dbName, err := db.Name()
+	assert.NoError(t, err)
+
+	// another tab's connection, listening on the same BroadcastChannel
+	jsBroadcastChannel, err := safejs.Global().Get("BroadcastChannel")
+	assert.NoError(t, err)
+	otherTab, err := jsBroadcastChannel.New(freezeChannelPrefix + dbName)
+	assert.NoError(t, err)
+	defer func() {
+		_, _ = otherTab.Call("close")
+	}()
+	received := make(chan string, 10)
+	onMessage, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		data, err := args[0].Get("data")
+		assert.NoError(t, err)
+		messageType, err := jsGetString(data, "type")
+		assert.NoError(t, err)
+		received <- messageType
+		return nil
+	})
+	assert.NoError(t, err)
+	defer onMessage.Release()
+	_, err = otherTab.Call(addEventListener, "message", onMessage)
+	assert.NoError(t, err)
+
+	post := func(messageType freezeMessageType) {
+		message, err := ValueOf(map[string]interface{}{"type": string(messageType), "store": "mystore", "id": "other"})
+		assert.NoError(t, err)
+		_, err = otherTab.Call("postMessage", message)
+		assert.NoError(t, err)
+	}
+
+	// writes don't listen for freezes from other tabs unless asked to
+	_, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	freezeChannels.Lock()
+	assert.Equal(t, (*freezeChannel)(nil), freezeChannels.byName[dbName])
+	freezeChannels.Unlock()
+
+	// a freeze from the other tab stops writes here once it arrives
+	assert.NoError(t, db.WatchFreezes())
+	post(freezeAnnounce)
+	for {
+		_, err := db.Transaction(TransactionReadWrite, "mystore")
+		if errors.Is(err, ErrStoreFrozen) {
+			break
+		}
+		assert.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	_, err = db.FreezeStore(ctx, "mystore")
+	assert.ErrorIs(t, err, ErrStoreFrozen)
+	post(freezeRelease)
+	for {
+		_, err := db.Transaction(TransactionReadWrite, "mystore")
+		if err == nil {
+			break
+		}
+		assert.ErrorIs(t, err, ErrStoreFrozen)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// a freeze here is announced to the other tab
+	_, err = db.FreezeStore(ctx, "mystore")
+	assert.NoError(t, err)
+	for messageType := range received {
+		if messageType == string(freezeAnnounce) {
+			break
+		}
+	}
+
+	// closing the Database releases the freeze and stops watching
+	assert.NoError(t, db.Close())
+	for messageType := range received {
+		if messageType == string(freezeRelease) {
+			break
+		}
+	}
+	freezeChannels.Lock()
+	assert.Equal(t, (*freezeChannel)(nil), freezeChannels.byName[dbName])
+	freezeChannels.Unlock()
+}
@@ -0,0 +1,73 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// IdempotencyStoreName returns the name of the sidecar object store that records the idempotency keys processed by PutIdempotent on storeName.
+func IdempotencyStoreName(storeName string) string {
+	return storeName + ".idempotency"
+}
+
+// CreateIdempotencyStore creates the sidecar object store used by PutIdempotent on storeName. Call it during an upgrade, alongside creating storeName.
+func (db *Database) CreateIdempotencyStore(storeName string) (*ObjectStore, error) {
+	return db.CreateObjectStore(IdempotencyStoreName(storeName), ObjectStoreOptions{})
+}
+
+// PutIdempotent puts value at key, unless idempotencyKey was already processed. Returns true if the value was put.
+// Use it for application-level operations that may be retried, like after a reload, so they don't apply twice.
+// If key is undefined, the store must use in-line keys or a key generator.
+//
+// The processed idempotency key is recorded with the time it was processed in the sidecar store from IdempotencyStoreName, within the same transaction. The transaction must be read-write and include the sidecar store.
+func (o *ObjectStore) PutIdempotent(ctx context.Context, idempotencyKey string, key, value safejs.Value) (bool, error) {
+	txn, err := o.Transaction()
+	if err != nil {
+		return false, err
+	}
+	name, err := o.Name()
+	if err != nil {
+		return false, err
+	}
+	processed, err := txn.ObjectStore(IdempotencyStoreName(name))
+	if err != nil {
+		return false, err
+	}
+	jsIdempotencyKey, err := safejs.ValueOf(idempotencyKey)
+	if err != nil {
+		return false, err
+	}
+	countReq, err := processed.CountKey(jsIdempotencyKey)
+	if err != nil {
+		return false, err
+	}
+	count, err := countReq.Await(ctx)
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	var req *Request
+	if key.IsUndefined() {
+		req, err = o.Put(value)
+	} else {
+		req, err = o.PutKey(key, value)
+	}
+	if err != nil {
+		return false, err
+	}
+	processedTime, err := TimeKey(time.Now())
+	if err != nil {
+		return false, err
+	}
+	_, err = processed.PutKey(jsIdempotencyKey, processedTime)
+	if err != nil {
+		return false, err
+	}
+	_, err = req.Await(ctx)
+	return err == nil, err
+}
@@ -0,0 +1,79 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestPutIdempotent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateIdempotencyStore("mystore")
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("inline", ObjectStoreOptions{KeyPath: NewKeyPath("id")})
+		assert.NoError(t, err)
+	})
+	assert.Equal(t, "mystore.idempotency", IdempotencyStoreName("mystore"))
+
+	putIdempotent := func(storeName, idempotencyKey string, key, value interface{}) bool {
+		t.Helper()
+		txn, err := db.Transaction(TransactionReadWrite, storeName, IdempotencyStoreName("mystore"))
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore(storeName)
+		assert.NoError(t, err)
+		jsKey := safejs.Undefined()
+		if key != nil {
+			jsKey, err = ValueOf(key)
+			assert.NoError(t, err)
+		}
+		jsValue, err := ValueOf(value)
+		assert.NoError(t, err)
+		applied, err := store.PutIdempotent(ctx, idempotencyKey, jsKey, jsValue)
+		assert.NoError(t, err)
+		assert.NoError(t, txn.Await(ctx))
+		return applied
+	}
+
+	assert.Equal(t, true, putIdempotent("mystore", "op-1", "key", "first"))
+	assert.Equal(t, false, putIdempotent("mystore", "op-1", "key", "retry"))
+	assert.Equal(t, true, putIdempotent("mystore", "op-2", "key", "second"))
+
+	txn, err := db.Transaction(TransactionReadOnly, "mystore", IdempotencyStoreName("mystore"))
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	req, err := store.GetValue("key")
+	assert.NoError(t, err)
+	value, err := req.Await(ctx)
+	assert.NoError(t, err)
+	valueString, err := value.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "second", valueString)
+	processed, err := txn.ObjectStore(IdempotencyStoreName("mystore"))
+	assert.NoError(t, err)
+	countReq, err := processed.Count()
+	assert.NoError(t, err)
+	count, err := countReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), count)
+
+	t.Run("missing sidecar store", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "inline")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("inline")
+		assert.NoError(t, err)
+		value, err := ValueOf(map[string]interface{}{"id": 1})
+		assert.NoError(t, err)
+		_, err = store.PutIdempotent(ctx, "op", safejs.Undefined(), value)
+		assert.Error(t, err)
+	})
+}
@@ -4,6 +4,7 @@
 package idb
 
 import (
+	"errors"
 	"syscall/js"
 
 	"github.com/hack-pad/safejs"
@@ -17,6 +18,25 @@ type IndexOptions struct {
 	MultiEntry bool
 }
 
+// Validate returns an error if the options can't be used to create an index on keyPath.
+func (o IndexOptions) Validate(keyPath KeyPath) error {
+	if o.MultiEntry && keyPath.Kind() == KeyPathCompound {
+		return errors.New("multi-entry index can't use a compound key path")
+	}
+	return nil
+}
+
+// jsValue validates the options and converts them to a JS IDBIndexParameters object.
+func (o IndexOptions) jsValue(keyPath KeyPath) (safejs.Value, error) {
+	if err := o.Validate(keyPath); err != nil {
+		return safejs.Undefined(), err
+	}
+	return safejs.ValueOf(map[string]interface{}{
+		"unique":     o.Unique,
+		"multiEntry": o.MultiEntry,
+	})
+}
+
 // Index provides asynchronous access to an index in a database. An index is a kind of object store for looking up records in another object store, called the referenced object store. You use this to retrieve data.
 type Index struct {
 	base *baseObjectStore // don't embed to avoid generated docs with the wrong receiver type (Index vs *Index)
@@ -44,12 +64,26 @@ func (i *Index) Name() (string, error) {
 	return name.String()
 }
 
+// SetName renames this index. Only allowed during a version upgrade.
+func (i *Index) SetName(name string) error {
+	return i.base.setName("index", name)
+}
+
 // KeyPath returns the key path of this index. If js.Null(), this index is not auto-populated.
 func (i *Index) KeyPath() (js.Value, error) {
 	value, err := i.base.jsObjectStore.Get("keyPath")
 	return safejs.Unsafe(value), err
 }
 
+// TypedKeyPath is the same as KeyPath, but decodes the key path into a KeyPath.
+func (i *Index) TypedKeyPath() (KeyPath, error) {
+	keyPath, err := i.base.jsObjectStore.Get("keyPath")
+	if err != nil {
+		return KeyPath{}, err
+	}
+	return parseKeyPath(keyPath)
+}
+
 // MultiEntry affects how the index behaves when the result of evaluating the index's key path yields an array. If true, there is one record in the index for each item in an array of keys. If false, then there is one record for each key that is an array.
 func (i *Index) MultiEntry() (bool, error) {
 	multiEntry, err := i.base.jsObjectStore.Get("multiEntry")
@@ -83,6 +117,16 @@ func (i *Index) CountRange(keyRange *KeyRange) (*UintRequest, error) {
 	return i.base.CountRange(keyRange)
 }
 
+// GetAll returns an ArrayRequest that retrieves all objects in the index.
+func (i *Index) GetAll() (*ArrayRequest, error) {
+	return i.base.GetAll()
+}
+
+// GetAllRange returns an ArrayRequest that retrieves all objects in the index matching the specified query. If maxCount is 0, retrieves all objects matching the query.
+func (i *Index) GetAllRange(query *KeyRange, maxCount uint) (*ArrayRequest, error) {
+	return i.base.GetAllRange(query, maxCount)
+}
+
 // GetAllKeys returns an ArrayRequest that retrieves record keys for all objects in the index.
 func (i *Index) GetAllKeys() (*ArrayRequest, error) {
 	return i.base.GetAllKeys()
@@ -98,6 +142,15 @@ func (i *Index) Get(key js.Value) (*Request, error) {
 	return i.base.Get(safejs.Safe(key))
 }
 
+// GetValue is the same as Get, but converts a native Go key with the database's Codec first.
+func (i *Index) GetValue(key interface{}) (*Request, error) {
+	jsKey, err := i.base.codec().ValueOf(key)
+	if err != nil {
+		return nil, err
+	}
+	return i.base.Get(jsKey)
+}
+
 // GetKey returns a Request, and, in a separate thread retrieves and returns the record key for the object matching the specified parameter.
 func (i *Index) GetKey(value js.Value) (*Request, error) {
 	return i.base.GetKey(safejs.Safe(value))
@@ -8,7 +8,6 @@ import (
 	"testing"
 
 	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
-	"github.com/hack-pad/safejs"
 )
 
 func TestIndexObjectStore(t *testing.T) {
@@ -16,7 +15,7 @@ func TestIndexObjectStore(t *testing.T) {
 	db := testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("primary")), IndexOptions{})
+		_, err = store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{})
 		assert.NoError(t, err)
 	})
 	txn, err := db.Transaction(TransactionReadWrite, "mystore")
@@ -36,7 +35,7 @@ func TestIndexName(t *testing.T) {
 	db := testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("primary")), IndexOptions{})
+		_, err = store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{})
 		assert.NoError(t, err)
 	})
 	txn, err := db.Transaction(TransactionReadWrite, "mystore")
@@ -56,7 +55,7 @@ func TestIndexKeyPath(t *testing.T) {
 	db := testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("primary")), IndexOptions{})
+		_, err = store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{})
 		assert.NoError(t, err)
 	})
 	txn, err := db.Transaction(TransactionReadWrite, "mystore")
@@ -76,7 +75,7 @@ func TestIndexMultiEntry(t *testing.T) {
 	db := testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("primary")), IndexOptions{
+		_, err = store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{
 			MultiEntry: true,
 		})
 		assert.NoError(t, err)
@@ -98,7 +97,7 @@ func TestIndexUnique(t *testing.T) {
 	db := testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("primary")), IndexOptions{
+		_, err = store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{
 			Unique: true,
 		})
 		assert.NoError(t, err)
@@ -114,3 +113,25 @@ func TestIndexUnique(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, unique)
 }
+
+func TestIndexSetName(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		index, err := store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, index.SetName("renamed"))
+		names, err := store.IndexNames()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"renamed"}, names)
+	})
+	txn, err := db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	index, err := store.Index("renamed")
+	assert.NoError(t, err)
+	err = index.SetName("other")
+	assert.ErrorIs(t, err, NewDOMException("InvalidStateError"))
+}
@@ -0,0 +1,88 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/hack-pad/safejs"
+)
+
+// maxSafeInteger is the largest integer JS numbers represent exactly, Number.MAX_SAFE_INTEGER.
+const maxSafeInteger = 1<<53 - 1
+
+// Int64Key encodes n as an 8 byte binary key which sorts in the same order as the integers.
+// Use it for IDs which may exceed 2^53: number keys are float64s, which can't represent those exactly, and BigInts aren't valid keys.
+func Int64Key(n int64) (safejs.Value, error) {
+	return Uint64Key(uint64(n) ^ 1<<63)
+}
+
+// KeyInt64 decodes a binary key from Int64Key.
+func KeyInt64(key safejs.Value) (int64, error) {
+	n, err := KeyUint64(key)
+	return int64(n ^ 1<<63), err
+}
+
+// Uint64Key encodes n as an 8 byte big-endian binary key, which sorts in the same order as the integers.
+func Uint64Key(n uint64) (safejs.Value, error) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	return BinaryKey(b[:])
+}
+
+// KeyUint64 decodes a binary key from Uint64Key.
+func KeyUint64(key safejs.Value) (uint64, error) {
+	b, err := BinaryKeyBytes(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != 8 {
+		return 0, fmt.Errorf("expected an 8 byte binary key, got %d bytes", len(b))
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// Int64StringKey encodes n as a 16 digit hexadecimal string key which sorts in the same order as the integers.
+// Use it instead of Int64Key where keys must be readable, like in developer tools, or combined with other string keys.
+func Int64StringKey(n int64) string {
+	return Uint64StringKey(uint64(n) ^ 1<<63)
+}
+
+// ParseInt64StringKey decodes a string key from Int64StringKey.
+func ParseInt64StringKey(key string) (int64, error) {
+	n, err := ParseUint64StringKey(key)
+	return int64(n ^ 1<<63), err
+}
+
+// Uint64StringKey encodes n as a 16 digit hexadecimal string key which sorts in the same order as the integers.
+func Uint64StringKey(n uint64) string {
+	return fmt.Sprintf("%016x", n)
+}
+
+// ParseUint64StringKey decodes a string key from Uint64StringKey.
+func ParseUint64StringKey(key string) (uint64, error) {
+	if len(key) != 16 {
+		return 0, fmt.Errorf("expected a 16 digit key, got %q", key)
+	}
+	return strconv.ParseUint(key, 16, 64)
+}
+
+// checkSafeInteger returns an error if key is an integer which a JS number can't represent exactly, so it would silently become a different key.
+func checkSafeInteger(key interface{}) error {
+	value := reflect.ValueOf(key)
+	var unsafe bool
+	switch value.Kind() {
+	case reflect.Int, reflect.Int64:
+		unsafe = value.Int() > maxSafeInteger || value.Int() < -maxSafeInteger
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		unsafe = value.Uint() > maxSafeInteger
+	}
+	if unsafe {
+		return fmt.Errorf("integer key %v exceeds %d, which numbers can't represent exactly: encode it with Int64Key or Uint64Key", key, maxSafeInteger)
+	}
+	return nil
+}
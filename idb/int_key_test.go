@@ -0,0 +1,54 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestInt64Key(t *testing.T) {
+	t.Parallel()
+	ints := []int64{math.MinInt64, -1 << 60, -1, 0, 1, 1<<53 + 1, math.MaxInt64}
+	var prevKey safejs.Value
+	var prevString string
+	for i, n := range ints {
+		key, err := Int64Key(n)
+		assert.NoError(t, err)
+		decoded, err := KeyInt64(key)
+		assert.NoError(t, err)
+		assert.Equal(t, n, decoded)
+
+		stringKey := Int64StringKey(n)
+		decoded, err = ParseInt64StringKey(stringKey)
+		assert.NoError(t, err)
+		assert.Equal(t, n, decoded)
+
+		if i > 0 {
+			cmp, err := compareKeys(prevKey, key)
+			assert.NoError(t, err)
+			assert.Equal(t, -1, cmp)
+			assert.Equal(t, true, prevString < stringKey)
+		}
+		prevKey, prevString = key, stringKey
+	}
+
+	key, err := Uint64Key(math.MaxUint64)
+	assert.NoError(t, err)
+	decoded, err := KeyUint64(key)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), decoded)
+	_, err = ParseUint64StringKey("123")
+	assert.Error(t, err)
+
+	_, err = NewKeyRangeOnlyOf(int64(1<<53 + 1))
+	assert.Error(t, err)
+	_, err = NewKeyRangeOnlyOf(uint64(1 << 53))
+	assert.Error(t, err)
+	_, err = NewKeyRangeOnlyOf(int64(-(1<<53 - 1)))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,93 @@
+//go:build js && wasm && go1.23
+// +build js,wasm,go1.23
+
+package idb
+
+import (
+	"context"
+	"iter"
+
+	"github.com/hack-pad/safejs"
+)
+
+// Record is a record read by an iterator, like ObjectStore.All.
+type Record struct {
+	// Key is the record's key in the object store or index it was read from.
+	Key safejs.Value
+	// PrimaryKey is the record's key in its object store. It's the same as Key when reading from an object store.
+	PrimaryKey safejs.Value
+	// Value is the record's value.
+	Value safejs.Value
+}
+
+// All returns an iterator over the records in the object store, in ascending key order.
+// If an error occurs, it is yielded with a zero Record and iteration stops.
+//
+// The iterator reads with a cursor in the object store's transaction, so the loop body should not wait on other work, otherwise the transaction commits before the next record is read.
+func (o *ObjectStore) All(ctx context.Context) iter.Seq2[Record, error] {
+	return recordSeq(ctx, func() (*CursorWithValueRequest, error) {
+		return o.OpenCursor(CursorNext)
+	})
+}
+
+// Keys returns an iterator over the keys in the object store, in ascending order. See All.
+func (o *ObjectStore) Keys(ctx context.Context) iter.Seq2[safejs.Value, error] {
+	return func(yield func(safejs.Value, error) bool) {
+		req, err := o.OpenKeyCursor(CursorNext)
+		if err != nil {
+			yield(safejs.Undefined(), err)
+			return
+		}
+		err = req.Iter(ctx, func(cursor *Cursor) error {
+			key, err := cursor.Key()
+			if err != nil {
+				return err
+			}
+			if !yield(key, nil) {
+				return ErrCursorStopIter
+			}
+			return nil
+		})
+		if err != nil {
+			yield(safejs.Undefined(), err)
+		}
+	}
+}
+
+// All returns an iterator over the records in the index, in ascending index key order. See ObjectStore.All.
+func (i *Index) All(ctx context.Context) iter.Seq2[Record, error] {
+	return recordSeq(ctx, func() (*CursorWithValueRequest, error) {
+		return i.OpenCursor(CursorNext)
+	})
+}
+
+// recordSeq returns an iterator over the records from the cursor opened by openCursor. The cursor is only opened once iteration starts.
+func recordSeq(ctx context.Context, openCursor func() (*CursorWithValueRequest, error)) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		req, err := openCursor()
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+		err = req.Iter(ctx, func(cursor *CursorWithValue) error {
+			var record Record
+			var err error
+			if record.Key, err = cursor.Key(); err != nil {
+				return err
+			}
+			if record.PrimaryKey, err = cursor.PrimaryKey(); err != nil {
+				return err
+			}
+			if record.Value, err = cursor.Value(); err != nil {
+				return err
+			}
+			if !yield(record, nil) {
+				return ErrCursorStopIter
+			}
+			return nil
+		})
+		if err != nil {
+			yield(Record{}, err)
+		}
+	}
+}
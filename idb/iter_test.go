@@ -0,0 +1,65 @@
+//go:build js && wasm && go1.23
+// +build js,wasm,go1.23
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestIterators(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, index := someKeyStore(t)
+
+	var keys, values []string
+	for record, err := range store.All(ctx) {
+		assert.NoError(t, err)
+		assert.Equal(t, record.Key, record.PrimaryKey)
+		key, err := record.Key.String()
+		assert.NoError(t, err)
+		keys = append(keys, key)
+		value, err := record.Value.Get("primary")
+		assert.NoError(t, err)
+		valueString, err := value.String()
+		assert.NoError(t, err)
+		values = append(values, valueString)
+	}
+	assert.Equal(t, []string{"some id 1", "some id 2", "some id 3", "some id 4", "some id 5"}, keys)
+	assert.Equal(t, []string{"some value 1", "some value 2", "some value 3", "some value 4", "some value 5"}, values)
+
+	keys = nil
+	for key, err := range store.Keys(ctx) {
+		assert.NoError(t, err)
+		keyString, err := key.String()
+		assert.NoError(t, err)
+		keys = append(keys, keyString)
+		if len(keys) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"some id 1", "some id 2"}, keys)
+
+	var indexKeys []string
+	for record, err := range index.All(ctx) {
+		assert.NoError(t, err)
+		key, err := record.Key.String()
+		assert.NoError(t, err)
+		indexKeys = append(indexKeys, key)
+		assert.Equal(t, safejs.Safe(js.ValueOf("some id "+key[len(key)-1:])), record.PrimaryKey)
+	}
+	assert.Equal(t, []string{"some value 1", "some value 2", "some value 3", "some value 4", "some value 5"}, indexKeys)
+
+	time.Sleep(10 * time.Millisecond) // let the transaction commit
+	var iterErr error
+	for _, err := range store.All(ctx) {
+		iterErr = err
+	}
+	assert.Error(t, iterErr)
+}
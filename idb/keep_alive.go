@@ -0,0 +1,112 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// MaxKeepAlive is the longest Transaction.KeepAlive keeps a transaction alive.
+const MaxKeepAlive = 5 * time.Second
+
+// KeepAlive keeps the transaction from committing automatically while the caller waits on non-IndexedDB work, like a channel receive or a short network call.
+// It issues cheap count requests back to back, so the transaction always has a pending request. Call stop as soon as the wait is over, then continue making requests.
+// stop waits for the last of these requests to complete and resumes the caller while handling its result, since new requests can only be made while the transaction is active.
+//
+// Keep-alive ends when stop is called, ctx is done, or maxDuration passes, which is capped at MaxKeepAlive. A warning is logged if it ends by reaching the time limit.
+// While kept alive, the transaction holds its locks and blocks other transactions on the same object stores, so prefer splitting work into separate transactions where possible.
+func (t *Transaction) KeepAlive(ctx context.Context, maxDuration time.Duration) (stop func(), err error) {
+	if maxDuration <= 0 || maxDuration > MaxKeepAlive {
+		maxDuration = MaxKeepAlive
+	}
+	storeNames, err := t.ObjectStoreNames()
+	if err != nil {
+		return nil, err
+	}
+	if len(storeNames) == 0 {
+		return nil, errNotInTransaction
+	}
+	store, err := t.ObjectStore(storeNames[0])
+	if err != nil {
+		return nil, err
+	}
+	// -Infinity is the lowest number key, so counting it is cheap
+	lowestKey, err := safejs.ValueOf(math.Inf(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	var stopped atomic.Bool
+	ended := make(chan struct{})
+	var ping func() error
+	var pinged safejs.Func
+	pinged, err = safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		succeeded := false
+		if len(args) > 0 {
+			eventType, err := args[0].Get("type")
+			if err == nil {
+				typeString, err := eventType.String()
+				succeeded = err == nil && typeString == "success"
+			}
+		}
+		if succeeded && !stopped.Load() && ping() == nil {
+			return nil
+		}
+		// no more pings are pending, so release once this callback returns
+		stopped.Store(true)
+		close(ended)
+		go pinged.Release()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ping = func() error {
+		req, err := store.base.jsObjectStore.Call("count", lowestKey)
+		if err == nil {
+			_, err = req.Call(addEventListener, "success", pinged)
+		}
+		if err == nil {
+			_, err = req.Call(addEventListener, "error", pinged)
+		}
+		return tryAsDOMException(err)
+	}
+	if err := ping(); err != nil {
+		pinged.Release()
+		return nil, err
+	}
+
+	stopCh := make(chan struct{})
+	var stopOnce sync.Once
+	stop = func() {
+		stopOnce.Do(func() {
+			stopped.Store(true)
+			close(stopCh)
+		})
+		<-ended
+	}
+	go func() {
+		timer := time.NewTimer(maxDuration)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+		case <-stopCh:
+		case <-ended:
+		case <-timer.C:
+			d := diagnostics.Load()
+			if d == nil {
+				d = &Diagnostics{}
+			}
+			d.warnf("transaction keep-alive stopped after reaching its limit of %s. Call the stop function returned by KeepAlive once done waiting.", maxDuration)
+		}
+		stopped.Store(true)
+	}()
+	return stop, nil
+}
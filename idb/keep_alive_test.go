@@ -0,0 +1,89 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestTransactionKeepAlive(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		stop, err := txn.KeepAlive(ctx, time.Second)
+		assert.NoError(t, err)
+		time.Sleep(50 * time.Millisecond) // would commit the transaction without keep-alive
+		stop()
+		stop()
+
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		_, err = store.PutKeyValue("key", "value")
+		assert.NoError(t, err)
+		assert.NoError(t, txn.Await(ctx))
+	})
+
+	t.Run("request right after stop", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		for i := 0; i < 5; i++ {
+			stop, err := txn.KeepAlive(ctx, time.Second)
+			assert.NoError(t, err)
+			done := make(chan struct{})
+			go func() {
+				time.Sleep(10 * time.Millisecond) // non-IndexedDB work
+				close(done)
+			}()
+			<-done
+			stop()
+
+			// stop returns while the transaction is still active, so a request made right away succeeds
+			_, err = store.PutKeyValue(i, "value")
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, txn.Await(ctx))
+	})
+
+	t.Run("time limit", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		_, err = txn.KeepAlive(ctx, 10*time.Millisecond)
+		assert.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		_, err = store.PutKeyValue("key", "value")
+		assert.Error(t, err)
+		assert.Equal(t, true, IsTxnFinishedErr(err))
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadOnly, "mystore")
+		assert.NoError(t, err)
+		ctx, cancel := context.WithCancel(ctx)
+		_, err = txn.KeepAlive(ctx, time.Second)
+		assert.NoError(t, err)
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		_, err = store.Count()
+		assert.Equal(t, true, IsTxnFinishedErr(err))
+	})
+}
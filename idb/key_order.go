@@ -0,0 +1,62 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"github.com/hack-pad/safejs"
+)
+
+// Ordering is the result of comparing two keys.
+type Ordering int
+
+const (
+	// Less indicates the first key sorts before the second.
+	Less Ordering = -1
+	// Equal indicates the keys are equal.
+	Equal Ordering = 0
+	// Greater indicates the first key sorts after the second.
+	Greater Ordering = 1
+)
+
+func (o Ordering) String() string {
+	switch {
+	case o < 0:
+		return "less"
+	case o > 0:
+		return "greater"
+	default:
+		return "equal"
+	}
+}
+
+// Compare compares two keys in IndexedDB key order. Returns an error if either isn't a valid key.
+func (f *Factory) Compare(a, b safejs.Value) (Ordering, error) {
+	compare, err := f.compareKeys(a, b)
+	return Ordering(compare), err
+}
+
+// CompareValues is the same as Compare, but converts native Go keys with ValueOf first, like the store methods taking Go values.
+func (f *Factory) CompareValues(a, b interface{}) (Ordering, error) {
+	jsA, err := ValueOf(a)
+	if err != nil {
+		return 0, err
+	}
+	jsB, err := ValueOf(b)
+	if err != nil {
+		return 0, err
+	}
+	return f.Compare(jsA, jsB)
+}
+
+// KeysEqual returns true if a and b are equal keys. Returns an error if either isn't a valid key.
+func (f *Factory) KeysEqual(a, b safejs.Value) (bool, error) {
+	order, err := f.Compare(a, b)
+	return order == Equal, err
+}
+
+// KeyLess returns true if a sorts before b. Returns an error if either isn't a valid key.
+func (f *Factory) KeyLess(a, b safejs.Value) (bool, error) {
+	order, err := f.Compare(a, b)
+	return order == Less, err
+}
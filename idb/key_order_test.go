@@ -0,0 +1,43 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestFactoryCompare(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		a, b   interface{}
+		expect Ordering
+	}{
+		{"a", "b", Less},
+		{"b", "b", Equal},
+		{2, 1, Greater},
+		{1, "a", Less}, // numbers sort before strings
+		{[]interface{}{"a", 2}, []interface{}{"a", 1}, Greater},
+	} {
+		order, err := Global().CompareValues(tc.a, tc.b)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expect, order)
+	}
+
+	_, err := Global().CompareValues(map[string]interface{}{"a": "a"}, "b")
+	assert.Error(t, err)
+
+	a, err := ValueOf("a")
+	assert.NoError(t, err)
+	b, err := ValueOf("b")
+	assert.NoError(t, err)
+	less, err := Global().KeyLess(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, true, less)
+	equal, err := Global().KeysEqual(a, a)
+	assert.NoError(t, err)
+	assert.Equal(t, true, equal)
+	assert.Equal(t, "greater", Greater.String())
+}
@@ -0,0 +1,181 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hack-pad/safejs"
+)
+
+// KeyPathKind is the shape of a KeyPath
+type KeyPathKind int
+
+const (
+	// KeyPathNone indicates no key path is set.
+	KeyPathNone KeyPathKind = iota
+	// KeyPathSingle indicates a single string key path.
+	KeyPathSingle
+	// KeyPathCompound indicates an array of string key paths.
+	KeyPathCompound
+)
+
+func (k KeyPathKind) String() string {
+	switch k {
+	case KeyPathSingle:
+		return "single"
+	case KeyPathCompound:
+		return "compound"
+	default:
+		return "none"
+	}
+}
+
+// KeyPath defines where the browser extracts a key from a stored value. It is either unset, a single string path, or an array of string paths producing a compound key.
+//
+// The zero value is an unset key path.
+type KeyPath struct {
+	paths    []string
+	compound bool
+}
+
+// NewKeyPath returns a KeyPath with a single string path, like "id" or "author.name". An empty string refers to the entire value.
+func NewKeyPath(path string) KeyPath {
+	return KeyPath{paths: []string{path}}
+}
+
+// NewCompoundKeyPath returns a KeyPath made of multiple string paths. Keys extracted with it are arrays containing the value at each path, in order.
+func NewCompoundKeyPath(paths ...string) KeyPath {
+	return KeyPath{
+		paths:    append([]string(nil), paths...),
+		compound: true,
+	}
+}
+
+func parseKeyPath(value safejs.Value) (KeyPath, error) {
+	switch value.Type() {
+	case safejs.TypeNull, safejs.TypeUndefined:
+		return KeyPath{}, nil
+	case safejs.TypeString:
+		path, err := value.String()
+		if err != nil {
+			return KeyPath{}, err
+		}
+		return NewKeyPath(path), nil
+	default:
+		paths, err := stringsFromArray(value)
+		if err != nil {
+			return KeyPath{}, err
+		}
+		return NewCompoundKeyPath(paths...), nil
+	}
+}
+
+// IsZero returns true if the key path is unset.
+func (k KeyPath) IsZero() bool {
+	return len(k.paths) == 0 && !k.compound
+}
+
+// Kind returns whether the key path is unset, a single path, or a compound path.
+func (k KeyPath) Kind() KeyPathKind {
+	switch {
+	case k.IsZero():
+		return KeyPathNone
+	case k.compound:
+		return KeyPathCompound
+	default:
+		return KeyPathSingle
+	}
+}
+
+// Path returns the path of a single key path. Returns an empty string for other kinds.
+func (k KeyPath) Path() string {
+	if k.Kind() != KeyPathSingle {
+		return ""
+	}
+	return k.paths[0]
+}
+
+// Paths returns the paths making up the key path: one path for a single key path, each path for a compound key path, or nil if unset.
+func (k KeyPath) Paths() []string {
+	if len(k.paths) == 0 {
+		return nil
+	}
+	return append([]string(nil), k.paths...)
+}
+
+// String returns the key path formatted like its JavaScript equivalent.
+func (k KeyPath) String() string {
+	switch {
+	case k.IsZero():
+		return "null"
+	case !k.compound:
+		return fmt.Sprintf("%q", k.paths[0])
+	default:
+		quoted := make([]string, 0, len(k.paths))
+		for _, path := range k.paths {
+			quoted = append(quoted, fmt.Sprintf("%q", path))
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+}
+
+// Validate returns an error if the key path is not a valid IndexedDB key path. An unset key path is valid.
+func (k KeyPath) Validate() error {
+	if k.compound && len(k.paths) == 0 {
+		return errors.New("compound key path must contain at least one path")
+	}
+	for _, path := range k.paths {
+		if !isValidKeyPathString(path) {
+			return fmt.Errorf("invalid key path %q: must be empty or identifiers separated by periods", path)
+		}
+	}
+	return nil
+}
+
+// jsValue validates the key path and converts it to a JS string, array of strings, or null.
+func (k KeyPath) jsValue() (safejs.Value, error) {
+	if err := k.Validate(); err != nil {
+		return safejs.Null(), err
+	}
+	switch {
+	case k.IsZero():
+		return safejs.Null(), nil
+	case !k.compound:
+		return safejs.ValueOf(k.paths[0])
+	default:
+		return safejs.ValueOf(sliceFromStrings(k.paths))
+	}
+}
+
+func isValidKeyPathString(path string) bool {
+	if path == "" {
+		return true
+	}
+	for _, identifier := range strings.Split(path, ".") {
+		if !isIdentifier(identifier) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifier approximates the ECMAScript IdentifierName production.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '$', r == '_', unicode.IsLetter(r):
+		case i > 0 && (unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r) || r == '\u200c' || r == '\u200d'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestKeyPathValidate(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name      string
+		keyPath   KeyPath
+		expectErr bool
+	}{
+		{name: "unset", keyPath: KeyPath{}},
+		{name: "empty string", keyPath: NewKeyPath("")},
+		{name: "identifier", keyPath: NewKeyPath("id")},
+		{name: "nested identifiers", keyPath: NewKeyPath("author.name")},
+		{name: "compound", keyPath: NewCompoundKeyPath("lastName", "firstName")},
+		{name: "leading digit", keyPath: NewKeyPath("1st"), expectErr: true},
+		{name: "trailing period", keyPath: NewKeyPath("author."), expectErr: true},
+		{name: "space", keyPath: NewKeyPath("first name"), expectErr: true},
+		{name: "empty compound", keyPath: NewCompoundKeyPath(), expectErr: true},
+		{name: "invalid compound member", keyPath: NewCompoundKeyPath("id", "a-b"), expectErr: true},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.keyPath.Validate()
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKeyPathString(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "null", KeyPath{}.String())
+	assert.Equal(t, `"id"`, NewKeyPath("id").String())
+	assert.Equal(t, `["a", "b.c"]`, NewCompoundKeyPath("a", "b.c").String())
+}
+
+func TestCompoundKeyPath(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{
+			KeyPath: NewCompoundKeyPath("lastName", "firstName"),
+		})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("myindex", NewCompoundKeyPath("city", "lastName"), IndexOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+
+	_, err = store.Add(safejs.Safe(js.ValueOf(map[string]interface{}{
+		"firstName": "Ada",
+		"lastName":  "Lovelace",
+		"city":      "London",
+	})))
+	assert.NoError(t, err)
+
+	getReq, err := store.Get(safejs.Safe(js.ValueOf([]interface{}{"Lovelace", "Ada"})))
+	assert.NoError(t, err)
+	value, err := getReq.Await(ctx)
+	assert.NoError(t, err)
+	city, err := value.Get("city")
+	assert.NoError(t, err)
+	assert.Equal(t, safejs.Safe(js.ValueOf("London")), city)
+
+	index, err := store.Index("myindex")
+	assert.NoError(t, err)
+	countReq, err := index.CountKey(js.ValueOf([]interface{}{"London", "Lovelace"}))
+	assert.NoError(t, err)
+	count, err := countReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), count)
+}
+
+func TestCreateIndexRequiresKeyPath(t *testing.T) {
+	t.Parallel()
+	testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("myindex", KeyPath{}, IndexOptions{})
+		assert.Error(t, err)
+		_, err = store.CreateIndex("myindex", NewKeyPath("not valid"), IndexOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestTypedKeyPath(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("nokeypath", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("single", ObjectStoreOptions{
+			KeyPath: NewKeyPath("id"),
+		})
+		assert.NoError(t, err)
+		store, err := db.CreateObjectStore("compound", ObjectStoreOptions{
+			KeyPath: NewCompoundKeyPath("a", "b.c"),
+		})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("myindex", NewCompoundKeyPath("d", "e"), IndexOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadOnly, "nokeypath", "single", "compound")
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		storeName    string
+		expectKind   KeyPathKind
+		expectPath   string
+		expectPaths  []string
+		expectString string
+	}{
+		{storeName: "nokeypath", expectKind: KeyPathNone, expectString: "null"},
+		{storeName: "single", expectKind: KeyPathSingle, expectPath: "id", expectPaths: []string{"id"}, expectString: `"id"`},
+		{storeName: "compound", expectKind: KeyPathCompound, expectPaths: []string{"a", "b.c"}, expectString: `["a", "b.c"]`},
+	} {
+		store, err := txn.ObjectStore(tc.storeName)
+		assert.NoError(t, err)
+		keyPath, err := store.TypedKeyPath()
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectKind, keyPath.Kind())
+		assert.Equal(t, tc.expectPath, keyPath.Path())
+		assert.Equal(t, tc.expectPaths, keyPath.Paths())
+		assert.Equal(t, tc.expectString, keyPath.String())
+	}
+
+	store, err := txn.ObjectStore("compound")
+	assert.NoError(t, err)
+	index, err := store.Index("myindex")
+	assert.NoError(t, err)
+	keyPath, err := index.TypedKeyPath()
+	assert.NoError(t, err)
+	assert.Equal(t, NewCompoundKeyPath("d", "e"), keyPath)
+}
@@ -4,6 +4,11 @@
 package idb
 
 import (
+	"fmt"
+	"syscall/js"
+	"time"
+	"unicode/utf16"
+
 	"github.com/hack-pad/safejs"
 )
 
@@ -74,6 +79,115 @@ func NewKeyRangeOnly(only safejs.Value) (*KeyRange, error) {
 	return WrapKeyRange(keyRange), nil
 }
 
+// Key is the set of native Go types accepted by the KeyRange constructors ending in "Of".
+// Numbers become number keys, time.Time becomes a Date key, and []byte becomes a binary key.
+type Key interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 | ~string | time.Time | []byte
+}
+
+// NewKeyRangeBoundOf is the same as NewKeyRangeBound, but converts native Go bounds.
+// Returns an error if lower is greater than upper, or if they are equal and either bound is open.
+func NewKeyRangeBoundOf[K Key](lower, upper K, lowerOpen, upperOpen bool) (*KeyRange, error) {
+	lowerKey, err := keyOf(lower)
+	if err != nil {
+		return nil, err
+	}
+	upperKey, err := keyOf(upper)
+	if err != nil {
+		return nil, err
+	}
+	cmp, err := compareKeys(lowerKey, upperKey)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case cmp > 0:
+		return nil, fmt.Errorf("key range lower bound %v is greater than upper bound %v", lower, upper)
+	case cmp == 0 && (lowerOpen || upperOpen):
+		return nil, fmt.Errorf("key range bounds are both %v, so neither bound can be open", lower)
+	}
+	return NewKeyRangeBound(lowerKey, upperKey, lowerOpen, upperOpen)
+}
+
+// NewKeyRangeLowerBoundOf is the same as NewKeyRangeLowerBound, but converts a native Go bound.
+func NewKeyRangeLowerBoundOf[K Key](lower K, open bool) (*KeyRange, error) {
+	lowerKey, err := keyOf(lower)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeLowerBound(lowerKey, open)
+}
+
+// NewKeyRangeUpperBoundOf is the same as NewKeyRangeUpperBound, but converts a native Go bound.
+func NewKeyRangeUpperBoundOf[K Key](upper K, open bool) (*KeyRange, error) {
+	upperKey, err := keyOf(upper)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeUpperBound(upperKey, open)
+}
+
+// NewKeyRangeOnlyOf is the same as NewKeyRangeOnly, but converts a native Go key.
+func NewKeyRangeOnlyOf[K Key](only K) (*KeyRange, error) {
+	key, err := keyOf(only)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeOnly(key)
+}
+
+// keyOf converts key to a JS key, returning an error if it isn't a valid key, like NaN, or it's an integer which a number can't represent exactly.
+func keyOf[K Key](key K) (safejs.Value, error) {
+	var jsKey safejs.Value
+	var err error
+	if b, ok := any(key).([]byte); ok {
+		jsKey, err = BinaryKey(b)
+	} else if err = checkSafeInteger(key); err == nil {
+		jsKey, err = ValueOf(key)
+	}
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	if _, err := compareKeys(jsKey, jsKey); err != nil {
+		return safejs.Undefined(), fmt.Errorf("invalid key %v: %w", key, err)
+	}
+	return jsKey, nil
+}
+
+// NewKeyRangePrefix creates a new key range containing every string key starting with prefix. Keys of other types are excluded.
+func NewKeyRangePrefix(prefix string) (*KeyRange, error) {
+	upper, err := stringPrefixEnd(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeBound(safejs.Safe(js.ValueOf(prefix)), upper, false, true)
+}
+
+// stringPrefixEnd returns the smallest key greater than every string key starting with prefix.
+// JS compares strings by UTF-16 code units, so this increments prefix's last code unit below 0xFFFF.
+func stringPrefixEnd(prefix string) (safejs.Value, error) {
+	units := utf16.Encode([]rune(prefix))
+	for i := len(units) - 1; i >= 0; i-- {
+		if units[i] < 0xffff {
+			args := make([]interface{}, 0, i+1)
+			for _, unit := range units[:i] {
+				args = append(args, unit)
+			}
+			args = append(args, units[i]+1)
+			// build the JS string from code units, which may not be valid UTF-16
+			jsString, err := safejs.Global().Get("String")
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+			return jsString.Call("fromCharCode", args...)
+		}
+	}
+	// every string starting with prefix sorts before all binary keys, the next key type
+	return BinaryKey(nil)
+}
+
 // Lower returns the lower bound of the key range.
 func (k *KeyRange) Lower() (safejs.Value, error) {
 	lower, err := k.jsKeyRange.Get("lower")
@@ -117,3 +231,60 @@ func (k *KeyRange) Includes(key safejs.Value) (bool, error) {
 func (k *KeyRange) Unwrap() safejs.Value {
 	return k.jsKeyRange
 }
+
+// ResumeKeyRange returns the part of keyRange which comes after key when iterating in the given direction.
+// If open is false, key itself remains part of the returned range.
+// keyRange may be nil to indicate all keys.
+//
+// Returns false if no keys remain, which is useful for resuming iteration in a new transaction.
+func ResumeKeyRange(keyRange *KeyRange, key safejs.Value, open bool, direction CursorDirection) (*KeyRange, bool, error) {
+	reverse := direction == CursorPrevious || direction == CursorPreviousUnique
+
+	bound := safejs.Undefined()
+	var boundOpen bool
+	if keyRange != nil {
+		var err error
+		if reverse {
+			bound, err = keyRange.Lower()
+			if err == nil {
+				boundOpen, err = keyRange.LowerOpen()
+			}
+		} else {
+			bound, err = keyRange.Upper()
+			if err == nil {
+				boundOpen, err = keyRange.UpperOpen()
+			}
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if bound.IsUndefined() {
+		var keyRange *KeyRange
+		var err error
+		if reverse {
+			keyRange, err = NewKeyRangeUpperBound(key, open)
+		} else {
+			keyRange, err = NewKeyRangeLowerBound(key, open)
+		}
+		return keyRange, err == nil, err
+	}
+
+	cmp, err := compareKeys(key, bound)
+	if err != nil {
+		return nil, false, err
+	}
+	if reverse {
+		cmp = -cmp
+	}
+	if cmp > 0 || (cmp == 0 && (open || boundOpen)) {
+		return nil, false, nil
+	}
+	if reverse {
+		keyRange, err = NewKeyRangeBound(bound, key, boundOpen, open)
+	} else {
+		keyRange, err = NewKeyRangeBound(key, bound, open, boundOpen)
+	}
+	return keyRange, err == nil, err
+}
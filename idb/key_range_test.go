@@ -5,8 +5,10 @@ package idb
 
 import (
 	"fmt"
+	"math"
 	"syscall/js"
 	"testing"
+	"time"
 
 	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
 	"github.com/hack-pad/safejs"
@@ -190,3 +192,155 @@ func TestKeyRangeBoundProperties(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, upperOpen)
 }
+
+func TestResumeKeyRange(t *testing.T) {
+	t.Parallel()
+	key := func(i int) safejs.Value {
+		return safejs.Safe(js.ValueOf(i))
+	}
+	closedRange, err := NewKeyRangeBound(key(0), key(10), false, false)
+	assert.NoError(t, err)
+	openRange, err := NewKeyRangeBound(key(0), key(10), true, true)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		name          string
+		keyRange      *KeyRange
+		key           int
+		open          bool
+		direction     CursorDirection
+		expectMore    bool
+		expectIncl    []int
+		expectNotIncl []int
+	}{
+		{name: "all keys next", key: 5, open: true, direction: CursorNext, expectMore: true, expectIncl: []int{6, 100}, expectNotIncl: []int{5, 4}},
+		{name: "all keys prev", key: 5, open: true, direction: CursorPrevious, expectMore: true, expectIncl: []int{4, -100}, expectNotIncl: []int{5, 6}},
+		{name: "closed next inclusive", keyRange: closedRange, key: 5, direction: CursorNext, expectMore: true, expectIncl: []int{5, 10}, expectNotIncl: []int{4, 11}},
+		{name: "closed prev", keyRange: closedRange, key: 5, open: true, direction: CursorPrevious, expectMore: true, expectIncl: []int{0, 4}, expectNotIncl: []int{-1, 5}},
+		{name: "at upper bound", keyRange: closedRange, key: 10, open: true, direction: CursorNext},
+		{name: "at upper bound inclusive", keyRange: closedRange, key: 10, direction: CursorNext, expectMore: true, expectIncl: []int{10}, expectNotIncl: []int{9}},
+		{name: "at open upper bound", keyRange: openRange, key: 10, direction: CursorNext},
+		{name: "past lower bound", keyRange: closedRange, key: -1, direction: CursorPrevious},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			keyRange, more, err := ResumeKeyRange(tc.keyRange, key(tc.key), tc.open, tc.direction)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectMore, more)
+			for _, k := range tc.expectIncl {
+				includes, err := keyRange.Includes(key(k))
+				assert.NoError(t, err)
+				assert.Equal(t, true, includes)
+			}
+			for _, k := range tc.expectNotIncl {
+				includes, err := keyRange.Includes(key(k))
+				assert.NoError(t, err)
+				assert.Equal(t, false, includes)
+			}
+		})
+	}
+}
+
+func TestNewKeyRangePrefix(t *testing.T) {
+	t.Parallel()
+	binaryKey, err := BinaryKey([]byte("app"))
+	assert.NoError(t, err)
+	jsString, err := safejs.Global().Get("String")
+	assert.NoError(t, err)
+	loneSurrogate, err := jsString.Call("fromCharCode", 'a', 0xd800)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		prefix  string
+		include []interface{}
+		exclude []interface{}
+	}{
+		{
+			prefix:  "app",
+			include: []interface{}{"app", "apple", "app\uffff", "app\uffff\uffff", "app\U0001F600"},
+			exclude: []interface{}{"ap", "apq", "b", 1, binaryKey, []interface{}{"app"}},
+		},
+		{
+			prefix:  "a\uffff",
+			include: []interface{}{"a\uffff", "a\uffffz", "a\uffff\uffff"},
+			exclude: []interface{}{"a", "a\ufffe", "b", binaryKey},
+		},
+		{
+			prefix:  "a\ud7ff",
+			include: []interface{}{"a\ud7ff", "a\ud7ffz"},
+			exclude: []interface{}{loneSurrogate, "a\U0001F600", "a"},
+		},
+		{
+			prefix:  "",
+			include: []interface{}{"", "a", "\uffff\uffff"},
+			exclude: []interface{}{1, binaryKey, []interface{}{}},
+		},
+		{
+			prefix:  "\uffff",
+			include: []interface{}{"\uffff", "\uffff\uffff"},
+			exclude: []interface{}{"\ufffe", binaryKey},
+		},
+	} {
+		keyRange, err := NewKeyRangePrefix(tc.prefix)
+		assert.NoError(t, err)
+		for _, key := range tc.include {
+			includes, err := keyRange.Includes(safejs.Safe(js.ValueOf(key)))
+			assert.NoError(t, err)
+			assert.Equal(t, true, includes)
+		}
+		for _, key := range tc.exclude {
+			if value, ok := key.(safejs.Value); ok {
+				key = safejs.Unsafe(value)
+			}
+			includes, err := keyRange.Includes(safejs.Safe(js.ValueOf(key)))
+			assert.NoError(t, err)
+			assert.Equal(t, false, includes)
+		}
+	}
+}
+
+func TestNewKeyRangeOf(t *testing.T) {
+	t.Parallel()
+	includes := func(keyRange *KeyRange, key interface{}) bool {
+		t.Helper()
+		jsKey, err := ValueOf(key)
+		assert.NoError(t, err)
+		includes, err := keyRange.Includes(jsKey)
+		assert.NoError(t, err)
+		return includes
+	}
+
+	intRange, err := NewKeyRangeBoundOf(1, 3, false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, true, includes(intRange, 1))
+	assert.Equal(t, false, includes(intRange, 3))
+
+	floatRange, err := NewKeyRangeLowerBoundOf(1.5, true)
+	assert.NoError(t, err)
+	assert.Equal(t, false, includes(floatRange, 1.5))
+	assert.Equal(t, true, includes(floatRange, 2))
+
+	stringRange, err := NewKeyRangeUpperBoundOf("m", false)
+	assert.NoError(t, err)
+	assert.Equal(t, true, includes(stringRange, "a"))
+	assert.Equal(t, false, includes(stringRange, "z"))
+
+	now := time.Now()
+	timeRange, err := NewKeyRangeOnlyOf(now)
+	assert.NoError(t, err)
+	assert.Equal(t, true, includes(timeRange, now))
+
+	bytesRange, err := NewKeyRangeBoundOf([]byte{1}, []byte{2}, false, false)
+	assert.NoError(t, err)
+	binaryKey, err := BinaryKey([]byte{1, 5})
+	assert.NoError(t, err)
+	assert.Equal(t, true, includes(bytesRange, binaryKey))
+
+	_, err = NewKeyRangeBoundOf(3, 1, false, false)
+	assert.Error(t, err)
+	_, err = NewKeyRangeBoundOf("a", "a", true, false)
+	assert.Error(t, err)
+	_, err = NewKeyRangeOnlyOf(math.NaN())
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+var (
+	memoryLimits atomic.Pointer[MemoryLimits]
+
+	memoryStats struct {
+		pressured  atomic.Uint64
+		shrinks    atomic.Uint64
+		collection atomic.Uint64
+	}
+)
+
+// MemoryLimits configures how chunked operations, like BatchScan, CopyStore, and Truncate, react to pressure on the Go heap.
+// Under pressure, each chunk is half the size of the previous one, down to MinBatchSize, and grows back to the requested size once the pressure is gone.
+// This keeps large reads, imports, and migrations from running out of memory on low-memory devices, where the wasm heap can't grow.
+type MemoryLimits struct {
+	// HeapLimit is the size of the Go heap, in bytes, above which it's under pressure. Defaults to the soft memory limit set with debug.SetMemoryLimit, if any.
+	HeapLimit uint64
+	// MinBatchSize is the smallest size a chunk shrinks to. Defaults to 1.
+	MinBatchSize uint
+	// CollectGarbage runs the garbage collector between chunks while under pressure, before deciding whether to shrink the next chunk.
+	CollectGarbage bool
+}
+
+// SetMemoryLimits enables heap pressure handling for chunked operations started afterward. Pass nil to disable it.
+func SetMemoryLimits(limits *MemoryLimits) {
+	memoryLimits.Store(limits)
+}
+
+// MemoryStats counts how chunked operations reacted to heap pressure since the program started.
+type MemoryStats struct {
+	// Pressured is the number of times the heap was found under pressure between chunks.
+	Pressured uint64
+	// Shrinks is the number of times a chunk was made smaller.
+	Shrinks uint64
+	// Collections is the number of garbage collections run because of pressure.
+	Collections uint64
+}
+
+// ReadMemoryStats returns the counts of heap pressure handling so far.
+func ReadMemoryStats() MemoryStats {
+	return MemoryStats{
+		Pressured:   memoryStats.pressured.Load(),
+		Shrinks:     memoryStats.shrinks.Load(),
+		Collections: memoryStats.collection.Load(),
+	}
+}
+
+func (l *MemoryLimits) heapLimit() uint64 {
+	if l.HeapLimit > 0 {
+		return l.HeapLimit
+	}
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		return uint64(limit)
+	}
+	return 0
+}
+
+func (l *MemoryLimits) underPressure() bool {
+	limit := l.heapLimit()
+	if limit == 0 {
+		return false
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc > limit
+}
+
+// batchSizer picks the size of each chunk of a chunked operation, shrinking chunks under heap pressure.
+type batchSizer struct {
+	max, size uint
+}
+
+func newBatchSizer(size uint) *batchSizer {
+	return &batchSizer{max: size, size: size}
+}
+
+// next returns the size of the next chunk.
+func (b *batchSizer) next() uint {
+	return b.nextWithin(memoryLimits.Load())
+}
+
+// nextWithin returns the size of the next chunk, shrinking it if the heap is under pressure by limits. limits may be nil.
+func (b *batchSizer) nextWithin(limits *MemoryLimits) uint {
+	if limits == nil {
+		return b.max
+	}
+	pressured := limits.underPressure()
+	if pressured {
+		memoryStats.pressured.Add(1)
+		if limits.CollectGarbage {
+			runtime.GC()
+			memoryStats.collection.Add(1)
+			pressured = limits.underPressure()
+		}
+	}
+	minSize := limits.MinBatchSize
+	if minSize == 0 {
+		minSize = 1
+	}
+	switch {
+	case pressured:
+		if b.size > minSize {
+			b.size = max(b.size/2, minSize)
+			memoryStats.shrinks.Add(1)
+		}
+	case b.size < b.max:
+		b.size = min(b.size*2, b.max)
+	}
+	return b.size
+}
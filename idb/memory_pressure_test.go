@@ -0,0 +1,36 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestBatchSizer(t *testing.T) {
+	t.Parallel()
+	sizer := newBatchSizer(100)
+	assert.Equal(t, uint(100), sizer.nextWithin(nil))
+
+	before := ReadMemoryStats()
+	pressured := &MemoryLimits{HeapLimit: 1, MinBatchSize: 10, CollectGarbage: true}
+	var sizes []uint
+	for i := 0; i < 5; i++ {
+		sizes = append(sizes, sizer.nextWithin(pressured))
+	}
+	assert.Equal(t, []uint{50, 25, 12, 10, 10}, sizes)
+	after := ReadMemoryStats()
+	assert.Equal(t, true, after.Pressured-before.Pressured >= 5)
+	assert.Equal(t, true, after.Shrinks-before.Shrinks >= 4)
+	assert.Equal(t, true, after.Collections-before.Collections >= 5)
+
+	relieved := &MemoryLimits{HeapLimit: math.MaxUint64}
+	sizes = nil
+	for i := 0; i < 5; i++ {
+		sizes = append(sizes, sizer.nextWithin(relieved))
+	}
+	assert.Equal(t, []uint{20, 40, 80, 100, 100}, sizes)
+}
@@ -0,0 +1,251 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hack-pad/safejs"
+)
+
+// rangeBounds is a KeyRange decoded into Go, where undefined bounds are unbounded.
+type rangeBounds struct {
+	lower, upper         safejs.Value
+	hasLower, hasUpper   bool
+	lowerOpen, upperOpen bool
+}
+
+func parseRangeBounds(keyRange *KeyRange) (rangeBounds, error) {
+	if keyRange == nil {
+		return rangeBounds{}, nil
+	}
+	var b rangeBounds
+	var err error
+	if b.lower, err = keyRange.Lower(); err != nil {
+		return b, err
+	}
+	if b.upper, err = keyRange.Upper(); err != nil {
+		return b, err
+	}
+	if b.lowerOpen, err = keyRange.LowerOpen(); err != nil {
+		return b, err
+	}
+	if b.upperOpen, err = keyRange.UpperOpen(); err != nil {
+		return b, err
+	}
+	b.hasLower = !b.lower.IsUndefined()
+	b.hasUpper = !b.upper.IsUndefined()
+	return b, nil
+}
+
+func (b rangeBounds) keyRange() (*KeyRange, error) {
+	switch {
+	case b.hasLower && b.hasUpper:
+		return NewKeyRangeBound(b.lower, b.upper, b.lowerOpen, b.upperOpen)
+	case b.hasLower:
+		return NewKeyRangeLowerBound(b.lower, b.lowerOpen)
+	case b.hasUpper:
+		return NewKeyRangeUpperBound(b.upper, b.upperOpen)
+	default:
+		return nil, nil
+	}
+}
+
+// compareLower orders ranges by their lower bounds, with unbounded and closed bounds first.
+func compareLower(a, b rangeBounds) (int, error) {
+	switch {
+	case !a.hasLower && !b.hasLower:
+		return 0, nil
+	case !a.hasLower:
+		return -1, nil
+	case !b.hasLower:
+		return 1, nil
+	}
+	cmp, err := compareKeys(a.lower, b.lower)
+	if err != nil || cmp != 0 {
+		return cmp, err
+	}
+	switch {
+	case a.lowerOpen == b.lowerOpen:
+		return 0, nil
+	case a.lowerOpen:
+		return 1, nil
+	default:
+		return -1, nil
+	}
+}
+
+// MergeKeyRanges returns the union of ranges as a sorted list of disjoint key ranges. A nil range includes all keys.
+// Reading each returned range in order visits every matching key once, in ascending order.
+//
+// If the union includes all keys, returns a single nil range.
+func MergeKeyRanges(ranges ...*KeyRange) ([]*KeyRange, error) {
+	bounds := make([]rangeBounds, 0, len(ranges))
+	for _, keyRange := range ranges {
+		b, err := parseRangeBounds(keyRange)
+		if err != nil {
+			return nil, err
+		}
+		bounds = append(bounds, b)
+	}
+	var sortErr error
+	sort.SliceStable(bounds, func(i, j int) bool {
+		cmp, err := compareLower(bounds[i], bounds[j])
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return cmp < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	var merged []rangeBounds
+	for _, b := range bounds {
+		if len(merged) == 0 {
+			merged = append(merged, b)
+			continue
+		}
+		last := &merged[len(merged)-1]
+		overlaps := !last.hasUpper || !b.hasLower
+		if !overlaps {
+			cmp, err := compareKeys(b.lower, last.upper)
+			if err != nil {
+				return nil, err
+			}
+			overlaps = cmp < 0 || (cmp == 0 && !(b.lowerOpen && last.upperOpen))
+		}
+		if !overlaps {
+			merged = append(merged, b)
+			continue
+		}
+		switch {
+		case !last.hasUpper:
+		case !b.hasUpper:
+			last.hasUpper, last.upper, last.upperOpen = false, safejs.Undefined(), false
+		default:
+			cmp, err := compareKeys(b.upper, last.upper)
+			if err != nil {
+				return nil, err
+			}
+			if cmp > 0 || (cmp == 0 && !b.upperOpen) {
+				last.upper, last.upperOpen = b.upper, b.upperOpen
+			}
+		}
+	}
+
+	keyRanges := make([]*KeyRange, 0, len(merged))
+	for _, b := range merged {
+		keyRange, err := b.keyRange()
+		if err != nil {
+			return nil, err
+		}
+		keyRanges = append(keyRanges, keyRange)
+	}
+	return keyRanges, nil
+}
+
+// getAllMulti issues a request per merged range at once, then concatenates the results in key order.
+func getAllMulti(ctx context.Context, ranges []*KeyRange, getAll func() (*ArrayRequest, error), getAllRange func(*KeyRange, uint) (*ArrayRequest, error)) ([]safejs.Value, error) {
+	merged, err := MergeKeyRanges(ranges...)
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]*ArrayRequest, 0, len(merged))
+	for _, keyRange := range merged {
+		var req *ArrayRequest
+		if keyRange == nil {
+			req, err = getAll()
+		} else {
+			req, err = getAllRange(keyRange, 0)
+		}
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	var results []safejs.Value
+	for _, req := range requests {
+		values, err := req.Await(ctx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, values...)
+	}
+	return results, nil
+}
+
+// openCursorMulti iterates over each merged range with a cursor, in direction order.
+func openCursorMulti(ctx context.Context, ranges []*KeyRange, direction CursorDirection, openCursor func(*KeyRange) (*CursorWithValueRequest, error), iter func(*CursorWithValue) error) error {
+	merged, err := MergeKeyRanges(ranges...)
+	if err != nil {
+		return err
+	}
+	if direction == CursorPrevious || direction == CursorPreviousUnique {
+		for i, j := 0, len(merged)-1; i < j; i, j = i+1, j-1 {
+			merged[i], merged[j] = merged[j], merged[i]
+		}
+	}
+	stopped := false
+	for _, keyRange := range merged {
+		req, err := openCursor(keyRange)
+		if err != nil {
+			return err
+		}
+		err = req.Iter(ctx, func(cursor *CursorWithValue) error {
+			err := iter(cursor)
+			if err == ErrCursorStopIter {
+				stopped = true
+			}
+			return err
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAllMulti retrieves all objects in the object store matching any of ranges, in ascending key order.
+// IndexedDB can't express a union of disjoint ranges, so a request is made for each range after merging overlapping ranges. A nil range matches all keys.
+func (o *ObjectStore) GetAllMulti(ctx context.Context, ranges []*KeyRange) ([]safejs.Value, error) {
+	return getAllMulti(ctx, ranges, o.GetAll, o.GetAllRange)
+}
+
+// GetAllKeysMulti retrieves record keys for all objects in the object store matching any of ranges, in ascending order. See GetAllMulti.
+func (o *ObjectStore) GetAllKeysMulti(ctx context.Context, ranges []*KeyRange) ([]safejs.Value, error) {
+	return getAllMulti(ctx, ranges, o.GetAllKeys, o.GetAllKeysRange)
+}
+
+// OpenCursorMulti iterates over the records matching any of ranges with a cursor, calling iter for each record in direction order.
+// A cursor is opened for each range after merging overlapping ranges. A nil range matches all keys. Return ErrCursorStopIter from iter to stop early.
+func (o *ObjectStore) OpenCursorMulti(ctx context.Context, ranges []*KeyRange, direction CursorDirection, iter func(*CursorWithValue) error) error {
+	return openCursorMulti(ctx, ranges, direction, func(keyRange *KeyRange) (*CursorWithValueRequest, error) {
+		if keyRange == nil {
+			return o.OpenCursor(direction)
+		}
+		return o.OpenCursorRange(keyRange, direction)
+	}, iter)
+}
+
+// GetAllMulti retrieves all objects in the index matching any of ranges, in ascending index key order. See ObjectStore.GetAllMulti.
+func (i *Index) GetAllMulti(ctx context.Context, ranges []*KeyRange) ([]safejs.Value, error) {
+	return getAllMulti(ctx, ranges, i.GetAll, i.GetAllRange)
+}
+
+// GetAllKeysMulti retrieves the primary keys of all objects in the index matching any of ranges, in ascending index key order. See ObjectStore.GetAllMulti.
+func (i *Index) GetAllKeysMulti(ctx context.Context, ranges []*KeyRange) ([]safejs.Value, error) {
+	return getAllMulti(ctx, ranges, i.GetAllKeys, i.GetAllKeysRange)
+}
+
+// OpenCursorMulti iterates over the records matching any of ranges with a cursor. See ObjectStore.OpenCursorMulti.
+func (i *Index) OpenCursorMulti(ctx context.Context, ranges []*KeyRange, direction CursorDirection, iter func(*CursorWithValue) error) error {
+	return openCursorMulti(ctx, ranges, direction, func(keyRange *KeyRange) (*CursorWithValueRequest, error) {
+		if keyRange == nil {
+			return i.OpenCursor(direction)
+		}
+		return i.OpenCursorRange(keyRange, direction)
+	}, iter)
+}
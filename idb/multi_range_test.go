@@ -0,0 +1,180 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+// keyRangeString formats keyRange in interval notation, like "[1, 3)" or "(-∞, 5]"
+func keyRangeString(tb testing.TB, keyRange *KeyRange) string {
+	tb.Helper()
+	if keyRange == nil {
+		return "all"
+	}
+	b, err := parseRangeBounds(keyRange)
+	assert.NoError(tb, err)
+	lower, upper := "(-∞", "∞)"
+	if b.hasLower {
+		n, err := b.lower.Int()
+		assert.NoError(tb, err)
+		lower = fmt.Sprintf("[%d", n)
+		if b.lowerOpen {
+			lower = fmt.Sprintf("(%d", n)
+		}
+	}
+	if b.hasUpper {
+		n, err := b.upper.Int()
+		assert.NoError(tb, err)
+		upper = fmt.Sprintf("%d]", n)
+		if b.upperOpen {
+			upper = fmt.Sprintf("%d)", n)
+		}
+	}
+	return lower + ", " + upper
+}
+
+func TestMergeKeyRanges(t *testing.T) {
+	t.Parallel()
+	bound := func(lower, upper int, lowerOpen, upperOpen bool) *KeyRange {
+		keyRange, err := NewKeyRangeBoundOf(lower, upper, lowerOpen, upperOpen)
+		assert.NoError(t, err)
+		return keyRange
+	}
+	lowerBound := func(lower int, open bool) *KeyRange {
+		keyRange, err := NewKeyRangeLowerBoundOf(lower, open)
+		assert.NoError(t, err)
+		return keyRange
+	}
+	upperBound := func(upper int, open bool) *KeyRange {
+		keyRange, err := NewKeyRangeUpperBoundOf(upper, open)
+		assert.NoError(t, err)
+		return keyRange
+	}
+
+	for _, tc := range []struct {
+		name   string
+		ranges []*KeyRange
+		expect []string
+	}{
+		{name: "none", ranges: nil, expect: []string{}},
+		{name: "disjoint", ranges: []*KeyRange{bound(5, 6, false, false), bound(1, 2, false, false)}, expect: []string{"[1, 2]", "[5, 6]"}},
+		{name: "overlapping", ranges: []*KeyRange{bound(1, 4, false, true), bound(3, 6, true, false)}, expect: []string{"[1, 6]"}},
+		{name: "contained", ranges: []*KeyRange{bound(1, 9, true, true), bound(3, 4, false, false)}, expect: []string{"(1, 9)"}},
+		{name: "touching closed", ranges: []*KeyRange{bound(1, 3, false, false), bound(3, 5, true, false)}, expect: []string{"[1, 5]"}},
+		{name: "touching open", ranges: []*KeyRange{bound(1, 3, false, true), bound(3, 5, true, false)}, expect: []string{"[1, 3)", "(3, 5]"}},
+		{name: "same upper", ranges: []*KeyRange{bound(1, 3, false, true), bound(2, 3, false, false)}, expect: []string{"[1, 3]"}},
+		{name: "unbounded", ranges: []*KeyRange{lowerBound(5, false), upperBound(2, true), bound(3, 4, false, false), bound(6, 7, false, false)}, expect: []string{"(-∞, 2)", "[3, 4]", "[5, ∞)"}},
+		{name: "all", ranges: []*KeyRange{bound(3, 4, false, false), nil}, expect: []string{"all"}},
+		{name: "lower and upper cover all", ranges: []*KeyRange{upperBound(5, false), lowerBound(2, false)}, expect: []string{"all"}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := MergeKeyRanges(tc.ranges...)
+			assert.NoError(t, err)
+			got := []string{}
+			for _, keyRange := range merged {
+				got = append(got, keyRangeString(t, keyRange))
+			}
+			assert.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestMultiRange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("myindex", NewKeyPath("group"), IndexOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := store.PutKeyValue(i, map[string]interface{}{"n": i, "group": i % 3})
+		assert.NoError(t, err)
+	}
+	index, err := store.Index("myindex")
+	assert.NoError(t, err)
+
+	ranges := func(bounds ...[2]int) []*KeyRange {
+		var keyRanges []*KeyRange
+		for _, b := range bounds {
+			keyRange, err := NewKeyRangeBoundOf(b[0], b[1], false, false)
+			assert.NoError(t, err)
+			keyRanges = append(keyRanges, keyRange)
+		}
+		return keyRanges
+	}
+	ints := func(values []safejs.Value, property string) []int {
+		var result []int
+		for _, value := range values {
+			if property != "" {
+				var err error
+				value, err = value.Get(property)
+				assert.NoError(t, err)
+			}
+			n, err := value.Int()
+			assert.NoError(t, err)
+			result = append(result, n)
+		}
+		return result
+	}
+
+	values, err := store.GetAllMulti(ctx, ranges([2]int{7, 8}, [2]int{1, 2}, [2]int{2, 3}))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 7, 8}, ints(values, "n"))
+
+	keys, err := store.GetAllKeysMulti(ctx, ranges([2]int{8, 9}, [2]int{0, 0}))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 8, 9}, ints(keys, ""))
+
+	var visited []int
+	err = store.OpenCursorMulti(ctx, ranges([2]int{1, 2}, [2]int{5, 6}, [2]int{8, 9}), CursorPrevious, func(cursor *CursorWithValue) error {
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		n, err := key.Int()
+		if err != nil {
+			return err
+		}
+		visited = append(visited, n)
+		if n == 5 {
+			return ErrCursorStopIter
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{9, 8, 6, 5}, visited)
+
+	values, err = index.GetAllMulti(ctx, ranges([2]int{2, 2}, [2]int{0, 0}))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 3, 6, 9, 2, 5, 8}, ints(values, "n"))
+	keys, err = index.GetAllKeysMulti(ctx, ranges([2]int{1, 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 7}, ints(keys, ""))
+
+	visited = nil
+	err = index.OpenCursorMulti(ctx, ranges([2]int{1, 1}, [2]int{2, 2}), CursorNextUnique, func(cursor *CursorWithValue) error {
+		primaryKey, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		n, err := primaryKey.Int()
+		visited = append(visited, n)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, visited)
+}
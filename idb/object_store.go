@@ -4,17 +4,57 @@
 package idb
 
 import (
-	"syscall/js"
+	"errors"
+	"time"
 
 	"github.com/hack-pad/safejs"
 )
 
 // ObjectStoreOptions contains all available options for creating an ObjectStore
 type ObjectStoreOptions struct {
-	KeyPath       js.Value
+	// KeyPath is the key path used to extract keys from stored values. Leave unset to provide keys with each modification operation instead.
+	KeyPath KeyPath
+	// AutoIncrement generates keys for new records from a key generator. Not allowed with an empty or compound KeyPath.
 	AutoIncrement bool
 }
 
+// Validate returns an error if the options can't be used to create an object store.
+func (o ObjectStoreOptions) Validate() error {
+	if err := o.KeyPath.Validate(); err != nil {
+		return err
+	}
+	if o.AutoIncrement {
+		switch {
+		case o.KeyPath.Kind() == KeyPathCompound:
+			return errors.New("auto-incrementing object store can't use a compound key path")
+		case o.KeyPath.Kind() == KeyPathSingle && o.KeyPath.Path() == "":
+			return errors.New("auto-incrementing object store can't use an empty key path")
+		}
+	}
+	return nil
+}
+
+// jsValue validates the options and converts them to a JS IDBObjectStoreParameters object.
+func (o ObjectStoreOptions) jsValue() (safejs.Value, error) {
+	if err := o.Validate(); err != nil {
+		return safejs.Undefined(), err
+	}
+	keyPath, err := o.KeyPath.jsValue()
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	jsOptions, err := safejs.ValueOf(map[string]interface{}{
+		"autoIncrement": o.AutoIncrement,
+	})
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	if err := jsOptions.Set("keyPath", keyPath); err != nil {
+		return safejs.Undefined(), err
+	}
+	return jsOptions, nil
+}
+
 // ObjectStore represents an object store in a database. Records within an object store are sorted according to their keys. This sorting enables fast insertion, look-up, and ordered retrieval.
 type ObjectStore struct {
 	base *baseObjectStore // don't embed to avoid generated docs with the wrong receiver type (ObjectStore vs *ObjectStore)
@@ -38,6 +78,15 @@ func (o *ObjectStore) KeyPath() (safejs.Value, error) {
 	return o.base.jsObjectStore.Get("keyPath")
 }
 
+// TypedKeyPath is the same as KeyPath, but decodes the key path into a KeyPath.
+func (o *ObjectStore) TypedKeyPath() (KeyPath, error) {
+	keyPath, err := o.KeyPath()
+	if err != nil {
+		return KeyPath{}, err
+	}
+	return parseKeyPath(keyPath)
+}
+
 // Name returns the name of this object store.
 func (o *ObjectStore) Name() (string, error) {
 	name, err := o.base.jsObjectStore.Get("name")
@@ -47,6 +96,11 @@ func (o *ObjectStore) Name() (string, error) {
 	return name.String()
 }
 
+// SetName renames this object store. Only allowed during a version upgrade.
+func (o *ObjectStore) SetName(name string) error {
+	return o.base.setName("object store", name)
+}
+
 // Transaction returns the Transaction object to which this object store belongs.
 func (o *ObjectStore) Transaction() (*Transaction, error) {
 	if o.base.txn == (*Transaction)(nil) {
@@ -68,9 +122,9 @@ func (o *ObjectStore) AutoIncrement() (bool, error) {
 func (o *ObjectStore) Add(value safejs.Value) (*AckRequest, error) {
 	reqValue, err := o.base.jsObjectStore.Call("add", value)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, o.base.opError("add", safejs.Undefined(), err)
 	}
-	req := wrapRequest(o.base.txn, reqValue)
+	req := wrapWriteRequest(o.base.txn, "add", reqValue)
 	return newAckRequest(req), nil
 }
 
@@ -78,9 +132,9 @@ func (o *ObjectStore) Add(value safejs.Value) (*AckRequest, error) {
 func (o *ObjectStore) AddKey(key, value safejs.Value) (*AckRequest, error) {
 	reqValue, err := o.base.jsObjectStore.Call("add", value, key)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, o.base.opError("add", key, err)
 	}
-	req := wrapRequest(o.base.txn, reqValue)
+	req := wrapWriteRequest(o.base.txn, "add", reqValue).withKey(key)
 	return newAckRequest(req), nil
 }
 
@@ -88,9 +142,9 @@ func (o *ObjectStore) AddKey(key, value safejs.Value) (*AckRequest, error) {
 func (o *ObjectStore) Clear() (*AckRequest, error) {
 	reqValue, err := o.base.jsObjectStore.Call("clear")
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, o.base.opError("clear", safejs.Undefined(), err)
 	}
-	req := wrapRequest(o.base.txn, reqValue)
+	req := wrapWriteRequest(o.base.txn, "clear", reqValue)
 	return newAckRequest(req), nil
 }
 
@@ -110,13 +164,22 @@ func (o *ObjectStore) CountRange(keyRange *KeyRange) (*UintRequest, error) {
 }
 
 // CreateIndex creates a new index during a version upgrade, returning a new Index object in the connected database.
-func (o *ObjectStore) CreateIndex(name string, keyPath safejs.Value, options IndexOptions) (*Index, error) {
-	jsIndex, err := o.base.jsObjectStore.Call("createIndex", name, keyPath, map[string]interface{}{
-		"unique":     options.Unique,
-		"multiEntry": options.MultiEntry,
-	})
+// Use NewCompoundKeyPath to index on multiple properties at once.
+func (o *ObjectStore) CreateIndex(name string, keyPath KeyPath, options IndexOptions) (*Index, error) {
+	if keyPath.IsZero() {
+		return nil, errors.New("index key path must be set")
+	}
+	jsKeyPath, err := keyPath.jsValue()
+	if err != nil {
+		return nil, err
+	}
+	jsOptions, err := options.jsValue(keyPath)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, err
+	}
+	jsIndex, err := o.base.jsObjectStore.Call("createIndex", name, jsKeyPath, jsOptions)
+	if err != nil {
+		return nil, o.base.indexOpError("createIndex", name, err)
 	}
 	return wrapIndex(o.base.txn, jsIndex), nil
 }
@@ -125,16 +188,26 @@ func (o *ObjectStore) CreateIndex(name string, keyPath safejs.Value, options Ind
 func (o *ObjectStore) Delete(key safejs.Value) (*AckRequest, error) {
 	reqValue, err := o.base.jsObjectStore.Call("delete", key)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, o.base.opError("delete", key, err)
 	}
-	req := wrapRequest(o.base.txn, reqValue)
+	req := wrapWriteRequest(o.base.txn, "delete", reqValue).withKey(key)
 	return newAckRequest(req), nil
 }
 
 // DeleteIndex destroys the specified index in the connected database, used during a version upgrade.
 func (o *ObjectStore) DeleteIndex(name string) error {
 	_, err := o.base.jsObjectStore.Call("deleteIndex", name)
-	return tryAsDOMException(err)
+	return o.base.indexOpError("deleteIndex", name, err)
+}
+
+// GetAll returns an ArrayRequest that retrieves all objects in the object store.
+func (o *ObjectStore) GetAll() (*ArrayRequest, error) {
+	return o.base.GetAll()
+}
+
+// GetAllRange returns an ArrayRequest that retrieves all objects in the object store matching the specified query. If maxCount is 0, retrieves all objects matching the query.
+func (o *ObjectStore) GetAllRange(query *KeyRange, maxCount uint) (*ArrayRequest, error) {
+	return o.base.GetAllRange(query, maxCount)
 }
 
 // GetAllKeys returns an ArrayRequest that retrieves record keys for all objects in the object store.
@@ -161,7 +234,7 @@ func (o *ObjectStore) GetKey(value safejs.Value) (*Request, error) {
 func (o *ObjectStore) Index(name string) (*Index, error) {
 	jsIndex, err := o.base.jsObjectStore.Call("index", name)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, o.base.indexOpError("index", name, err)
 	}
 	return wrapIndex(o.base.txn, jsIndex), nil
 }
@@ -170,18 +243,18 @@ func (o *ObjectStore) Index(name string) (*Index, error) {
 func (o *ObjectStore) Put(value safejs.Value) (*Request, error) {
 	reqValue, err := o.base.jsObjectStore.Call("put", value)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, o.base.opError("put", safejs.Undefined(), err)
 	}
-	return wrapRequest(o.base.txn, reqValue), nil
+	return wrapWriteRequest(o.base.txn, "put", reqValue), nil
 }
 
 // PutKey is the same as Put, but includes the key to use to identify the record.
 func (o *ObjectStore) PutKey(key, value safejs.Value) (*Request, error) {
 	reqValue, err := o.base.jsObjectStore.Call("put", value, key)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, o.base.opError("put", key, err)
 	}
-	return wrapRequest(o.base.txn, reqValue), nil
+	return wrapWriteRequest(o.base.txn, "put", reqValue).withKey(key), nil
 }
 
 // OpenCursor returns a CursorWithValueRequest, and, in a separate thread, returns a new CursorWithValue. Used for iterating through an object store by primary key with a cursor.
@@ -213,3 +286,156 @@ func (o *ObjectStore) OpenKeyCursorKey(key safejs.Value, direction CursorDirecti
 func (o *ObjectStore) OpenKeyCursorRange(keyRange *KeyRange, direction CursorDirection) (*CursorRequest, error) {
 	return o.base.OpenKeyCursorRange(keyRange, direction)
 }
+
+// AddValue is the same as Add, but converts a native Go value with the database's Codec first.
+func (o *ObjectStore) AddValue(value interface{}) (*AckRequest, error) {
+	jsValue, err := o.base.codec().ValueOf(value)
+	if err != nil {
+		return nil, err
+	}
+	return o.Add(jsValue)
+}
+
+// AddKeyValue is the same as AddKey, but converts a native Go key and value with the database's Codec first.
+func (o *ObjectStore) AddKeyValue(key, value interface{}) (*AckRequest, error) {
+	jsKey, jsValue, err := keyValueOf(o.base.codec(), key, value)
+	if err != nil {
+		return nil, err
+	}
+	return o.AddKey(jsKey, jsValue)
+}
+
+// PutValue is the same as Put, but converts a native Go value with the database's Codec first.
+func (o *ObjectStore) PutValue(value interface{}) (*Request, error) {
+	jsValue, err := o.base.codec().ValueOf(value)
+	if err != nil {
+		return nil, err
+	}
+	return o.Put(jsValue)
+}
+
+// PutKeyValue is the same as PutKey, but converts a native Go key and value with the database's Codec first.
+func (o *ObjectStore) PutKeyValue(key, value interface{}) (*Request, error) {
+	jsKey, jsValue, err := keyValueOf(o.base.codec(), key, value)
+	if err != nil {
+		return nil, err
+	}
+	return o.PutKey(jsKey, jsValue)
+}
+
+// GetValue is the same as Get, but converts a native Go key with the database's Codec first.
+func (o *ObjectStore) GetValue(key interface{}) (*Request, error) {
+	jsKey, err := o.base.codec().ValueOf(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.Get(jsKey)
+}
+
+// DeleteValue is the same as Delete, but converts a native Go key with the database's Codec first.
+func (o *ObjectStore) DeleteValue(key interface{}) (*AckRequest, error) {
+	jsKey, err := o.base.codec().ValueOf(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.Delete(jsKey)
+}
+
+func keyValueOf(codec *Codec, key, value interface{}) (jsKey, jsValue safejs.Value, err error) {
+	jsKey, err = codec.ValueOf(key)
+	if err != nil {
+		return safejs.Undefined(), safejs.Undefined(), err
+	}
+	jsValue, err = codec.ValueOf(value)
+	return jsKey, jsValue, err
+}
+
+// PutBytes is the same as PutKey, but stores b as a Uint8Array.
+func (o *ObjectStore) PutBytes(key safejs.Value, b []byte) (*Request, error) {
+	value, err := BytesValue(b)
+	if err != nil {
+		return nil, err
+	}
+	return o.PutKey(key, value)
+}
+
+// GetBytes is the same as Get, but returns a BytesRequest that reads an ArrayBuffer or typed array value into a byte slice.
+func (o *ObjectStore) GetBytes(key safejs.Value) (*BytesRequest, error) {
+	req, err := o.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return newBytesRequest(req), nil
+}
+
+// AddBinaryKey is the same as AddKey, but converts key into a binary key with BinaryKey.
+func (o *ObjectStore) AddBinaryKey(key []byte, value safejs.Value) (*AckRequest, error) {
+	jsKey, err := BinaryKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.AddKey(jsKey, value)
+}
+
+// PutBinaryKey is the same as PutKey, but converts key into a binary key with BinaryKey.
+func (o *ObjectStore) PutBinaryKey(key []byte, value safejs.Value) (*Request, error) {
+	jsKey, err := BinaryKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.PutKey(jsKey, value)
+}
+
+// GetBinaryKey is the same as Get, but converts key into a binary key with BinaryKey.
+func (o *ObjectStore) GetBinaryKey(key []byte) (*Request, error) {
+	jsKey, err := BinaryKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.Get(jsKey)
+}
+
+// DeleteBinaryKey is the same as Delete, but converts key into a binary key with BinaryKey.
+func (o *ObjectStore) DeleteBinaryKey(key []byte) (*AckRequest, error) {
+	jsKey, err := BinaryKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.Delete(jsKey)
+}
+
+// AddTimeKey is the same as AddKey, but converts key into a Date key with TimeKey.
+func (o *ObjectStore) AddTimeKey(key time.Time, value safejs.Value) (*AckRequest, error) {
+	jsKey, err := TimeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.AddKey(jsKey, value)
+}
+
+// PutTimeKey is the same as PutKey, but converts key into a Date key with TimeKey.
+func (o *ObjectStore) PutTimeKey(key time.Time, value safejs.Value) (*Request, error) {
+	jsKey, err := TimeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.PutKey(jsKey, value)
+}
+
+// GetTimeKey is the same as Get, but converts key into a Date key with TimeKey.
+func (o *ObjectStore) GetTimeKey(key time.Time) (*Request, error) {
+	jsKey, err := TimeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.Get(jsKey)
+}
+
+// DeleteTimeKey is the same as Delete, but converts key into a Date key with TimeKey.
+func (o *ObjectStore) DeleteTimeKey(key time.Time) (*AckRequest, error) {
+	jsKey, err := TimeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return o.Delete(jsKey)
+}
@@ -17,7 +17,7 @@ func TestObjectStoreIndexNames(t *testing.T) {
 	db := testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("indexKey")), IndexOptions{})
+		_, err = store.CreateIndex("myindex", NewKeyPath("indexKey"), IndexOptions{})
 		assert.NoError(t, err)
 	})
 	txn, err := db.Transaction(TransactionReadOnly, "mystore")
@@ -34,7 +34,7 @@ func TestObjectStoreKeyPath(t *testing.T) {
 	t.Parallel()
 	db := testDB(t, func(db *Database) {
 		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{
-			KeyPath: js.ValueOf("primary"),
+			KeyPath: NewKeyPath("primary"),
 		})
 		assert.NoError(t, err)
 	})
@@ -86,7 +86,7 @@ func TestObjectStoreTransaction(t *testing.T) {
 	t.Parallel()
 	db := testDB(t, func(db *Database) {
 		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{
-			KeyPath: js.ValueOf("primary"),
+			KeyPath: NewKeyPath("primary"),
 		})
 		assert.NoError(t, err)
 	})
@@ -105,7 +105,7 @@ func TestObjectStoreAdd(t *testing.T) {
 	t.Parallel()
 	db := testDB(t, func(db *Database) {
 		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{
-			KeyPath: js.ValueOf("id"),
+			KeyPath: NewKeyPath("id"),
 		})
 		assert.NoError(t, err)
 	})
@@ -224,7 +224,7 @@ func TestObjectStoreCreateIndex(t *testing.T) {
 	testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		index, err := store.CreateIndex("myindex", safejs.Safe(js.ValueOf("primary")), IndexOptions{
+		index, err := store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{
 			Unique:     true,
 			MultiEntry: true,
 		})
@@ -236,6 +236,9 @@ func TestObjectStoreCreateIndex(t *testing.T) {
 		multiEntry, err := index.MultiEntry()
 		assert.NoError(t, err)
 		assert.Equal(t, true, multiEntry)
+
+		_, err = store.CreateIndex("compound", NewCompoundKeyPath("a", "b"), IndexOptions{MultiEntry: true})
+		assert.Error(t, err)
 	})
 }
 
@@ -268,7 +271,7 @@ func TestObjectStoreDeleteIndex(t *testing.T) {
 	testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("primary")), IndexOptions{})
+		_, err = store.CreateIndex("myindex", NewKeyPath("primary"), IndexOptions{})
 		assert.NoError(t, err)
 		err = store.DeleteIndex("myindex")
 		assert.NoError(t, err)
@@ -378,7 +381,7 @@ func TestObjectStoreIndex(t *testing.T) {
 	db := testDB(t, func(db *Database) {
 		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
 		assert.NoError(t, err)
-		_, err = store.CreateIndex("myindex", safejs.Safe(js.ValueOf("indexKey")), IndexOptions{})
+		_, err = store.CreateIndex("myindex", NewKeyPath("indexKey"), IndexOptions{})
 		assert.NoError(t, err)
 	})
 	txn, err := db.Transaction(TransactionReadWrite, "mystore")
@@ -395,7 +398,7 @@ func TestObjectStorePut(t *testing.T) {
 	t.Parallel()
 	db := testDB(t, func(db *Database) {
 		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{
-			KeyPath: js.ValueOf("id"),
+			KeyPath: NewKeyPath("id"),
 		})
 		assert.NoError(t, err)
 	})
@@ -591,3 +594,26 @@ func TestObjectStoreOpenCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestObjectStoreSetName(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, store.SetName("renamed"))
+		name, err := store.Name()
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed", name)
+	})
+	names, err := db.ObjectStoreNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"renamed"}, names)
+
+	txn, err := db.Transaction(TransactionReadWrite, "renamed")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("renamed")
+	assert.NoError(t, err)
+	err = store.SetName("other")
+	assert.ErrorIs(t, err, NewDOMException("InvalidStateError"))
+	assert.Contains(t, err.Error(), "version upgrade")
+}
@@ -0,0 +1,103 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hack-pad/safejs"
+)
+
+// maxOpErrorKeyLen is the longest key summary kept in an OpError. Longer keys are truncated.
+const maxOpErrorKeyLen = 64
+
+// OpError is an error from an operation on an object store, index, or cursor, annotated with where it happened so logs show which store and key failed.
+// It wraps the underlying error, so errors.Is and errors.As still match DOMExceptions like ErrConstraint.
+type OpError struct {
+	// Op is the IndexedDB method which failed, like "put" or "openCursor".
+	Op string
+	// Store is the name of the object store.
+	Store string
+	// Index is the name of the index, if the operation was made on one.
+	Index string
+	// Key summarizes the key the operation was made on, if it was given one. Long keys are truncated.
+	Key string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *OpError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if e.Index != "" {
+		fmt.Fprintf(&b, " on index %q of store %q", e.Index, e.Store)
+	} else if e.Store != "" {
+		fmt.Fprintf(&b, " on store %q", e.Store)
+	}
+	if e.Key != "" {
+		b.WriteString(" with key ")
+		b.WriteString(e.Key)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// newOpError annotates err from op with the names of its source, an IDBObjectStore or IDBIndex, and key, which may be undefined. Returns nil if err is nil.
+func newOpError(op string, source, key safejs.Value, err error) error {
+	if err == nil {
+		return nil
+	}
+	opErr := &OpError{Op: op, Err: err}
+	opErr.Store, opErr.Index = sourceNames(source)
+	if !key.IsUndefined() {
+		opErr.Key = keySummary(key)
+	}
+	return opErr
+}
+
+// sourceNames returns the names of the object store and index source is on. The index is empty if source is an object store.
+func sourceNames(source safejs.Value) (store, index string) {
+	if isIndex, err := source.InstanceOf(jsIDBIndex()); err == nil && isIndex {
+		properties, err := jsGetNested(source, "objectStore", "name")
+		if err != nil {
+			return "", ""
+		}
+		store, _ = properties[2].String()
+		index, _ = jsGetString(source, "name")
+		return store, index
+	}
+	if isStore, err := source.InstanceOf(jsIDBObjectStore()); err == nil && isStore {
+		store, _ = jsGetString(source, "name")
+	}
+	return store, ""
+}
+
+func jsGetString(value safejs.Value, property string) (string, error) {
+	propertyValue, err := value.Get(property)
+	if err != nil {
+		return "", err
+	}
+	return propertyValue.String()
+}
+
+// keySummary returns a short description of key for error messages.
+func keySummary(key safejs.Value) string {
+	summary := keyString(key)
+	if len(summary) > maxOpErrorKeyLen {
+		cut := maxOpErrorKeyLen
+		for cut > 0 && !utf8.RuneStart(summary[cut]) {
+			cut-- // don't split a multibyte character
+		}
+		summary = summary[:cut] + "..."
+	}
+	return summary
+}
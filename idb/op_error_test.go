@@ -0,0 +1,71 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestOpError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("request error", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		key, err := ValueOf("some key")
+		assert.NoError(t, err)
+		value, err := ValueOf("some value")
+		assert.NoError(t, err)
+		_, err = store.AddKey(key, value)
+		assert.NoError(t, err)
+		req, err := store.AddKey(key, value)
+		assert.NoError(t, err)
+
+		err = req.Await(ctx)
+		assert.ErrorIs(t, err, ErrConstraint)
+		var opErr *OpError
+		assert.Equal(t, true, errors.As(err, &opErr))
+		assert.Equal(t, "add", opErr.Op)
+		assert.Equal(t, "mystore", opErr.Store)
+		assert.Equal(t, "", opErr.Index)
+		assert.Equal(t, `"some key"`, opErr.Key)
+	})
+
+	t.Run("sync error", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadOnly, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		_, err = store.Index("missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+		var opErr *OpError
+		assert.Equal(t, true, errors.As(err, &opErr))
+		assert.Equal(t, "index", opErr.Op)
+		assert.Equal(t, "mystore", opErr.Store)
+		assert.Equal(t, "missing", opErr.Index)
+		assert.Equal(t, `index on index "missing" of store "mystore": `+opErr.Err.Error(), err.Error())
+	})
+}
+
+func TestKeySummary(t *testing.T) {
+	t.Parallel()
+	key, err := ValueOf(strings.Repeat("é", 40))
+	assert.NoError(t, err)
+	summary := keySummary(key)
+	assert.Equal(t, true, utf8.ValidString(summary))
+	assert.Equal(t, `"`+strings.Repeat("é", 31)+"...", summary)
+}
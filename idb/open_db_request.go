@@ -5,36 +5,140 @@ package idb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
 
 	"github.com/hack-pad/safejs"
 )
 
+// ErrOpenCanceled is returned by OpenDBRequest.Await when its context is done before the database opens.
+// The request is abandoned: a connection which opens later is closed right away, and an upgrade which starts later is aborted.
+var ErrOpenCanceled = errors.New("open database canceled")
+
 // OpenDBRequest provides access to the results of requests to open or delete databases (performed using Factory.open and Factory.DeleteDatabase).
 type OpenDBRequest struct {
 	*Request
+	progress *upgradeProgress
+	state    *openState
+}
+
+// openState tracks whether an open request settled or was abandoned, so a connection nobody receives is closed instead of leaking.
+type openState struct {
+	cancel    context.CancelFunc
+	settled   atomic.Bool
+	abandoned atomic.Bool
 }
 
 // Upgrader is a function that can upgrade the given database from an old version to a new one.
 type Upgrader func(db *Database, oldVersion, newVersion uint) error
 
-func newOpenDBRequest(ctx context.Context, req *Request, upgrader Upgrader) (*OpenDBRequest, error) {
+// Upgrade is an upgrade of a database from an old version to a new one, in progress.
+type Upgrade struct {
+	Database *Database
+	// Transaction is the versionchange transaction the upgrade runs in, which includes every object store.
+	// Use it to read and write records during data migrations, like copying records from an old object store into a new one.
+	Transaction *Transaction
+	OldVersion  uint
+	NewVersion  uint
+	// Event is the upgradeneeded event.
+	Event safejs.Value
+
+	progress *upgradeProgress
+}
+
+// UpgradeProgress describes how far a long upgrade got, for showing progress while data is migrated. See Upgrade.ReportProgress.
+type UpgradeProgress struct {
+	// Step names the part of the upgrade in progress, like "copying messages".
+	Step string
+	// Migrated is the number of records the step migrated so far.
+	Migrated uint
+	// Total is the number of records the step will migrate, or 0 if unknown.
+	Total uint
+}
+
+// ReportProgress sends progress to the open request's Progress channel.
+// Only the latest progress is kept until it's received, so reporting never blocks the upgrade.
+func (u Upgrade) ReportProgress(progress UpgradeProgress) {
+	u.progress.send(progress)
+}
+
+// upgradeProgress delivers the latest UpgradeProgress of an open request.
+type upgradeProgress struct {
+	mu     sync.Mutex
+	ch     chan UpgradeProgress
+	closed bool
+}
+
+func newUpgradeProgress() *upgradeProgress {
+	return &upgradeProgress{ch: make(chan UpgradeProgress, 1)}
+}
+
+func (p *upgradeProgress) send(progress UpgradeProgress) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	select {
+	case <-p.ch: // replace unreceived progress
+	default:
+	}
+	p.ch <- progress
+}
+
+func (p *upgradeProgress) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.ch)
+	}
+}
+
+func (u Upgrader) upgradeFunc() func(Upgrade) error {
+	if u == nil {
+		return nil
+	}
+	return func(upgrade Upgrade) error {
+		return u(upgrade.Database, upgrade.OldVersion, upgrade.NewVersion)
+	}
+}
+
+// newOpenDBRequest wraps req, which opens a database. If the open fails or is abandoned, reservation is released. It may be nil.
+func newOpenDBRequest(ctx context.Context, req *Request, upgrader func(Upgrade) error, onBlocked func(VersionChange), caller string, reservation *openReservation) (*OpenDBRequest, error) {
 	ctx, cancel := context.WithCancel(ctx)
+	progress := newUpgradeProgress()
+	state := &openState{cancel: cancel}
 
-	err := req.ListenSuccess(ctx, func() {
+	err := req.Listen(ctx, func() {
 		defer cancel()
-		err := openDBListenSuccess(req)
+		state.settled.Store(true)
+		if state.abandoned.Load() {
+			reservation.release()
+			closeOpenResult(req)
+			return
+		}
+		err := openDBListenSuccess(req, caller, reservation)
 		if err != nil {
 			panic(err)
 		}
+	}, func() {
+		state.settled.Store(true)
+		reservation.release()
+		cancel()
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	upgrade, err := safejs.FuncOf(func(this safejs.Value, args []safejs.Value) interface{} {
-		err := openDBUpgradeNeeded(req, upgrader, args)
+		err := openDBUpgradeNeeded(req, upgrader, progress, args)
 		if err != nil {
 			panic(err)
 		}
@@ -47,24 +151,155 @@ func newOpenDBRequest(ctx context.Context, req *Request, upgrader Upgrader) (*Op
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
+	if onBlocked != nil {
+		if err := listenBlocked(ctx, req, onBlocked); err != nil {
+			return nil, err
+		}
+	}
 	go func() {
 		<-ctx.Done()
+		progress.close()
 		_, err := req.jsRequest.Call(removeEventListener, "upgradeneeded", upgrade)
 		if err != nil {
 			panic(err)
 		}
 		upgrade.Release()
+		if !state.settled.Load() {
+			state.abandoned.Store(true)
+			// the abandoned request's connection is closed as soon as it opens
+			reservation.release()
+			if err := listenAbandoned(req); err != nil {
+				log.Println("Failed cleaning up canceled open request:", err)
+			}
+		}
 	}()
-	return &OpenDBRequest{req}, nil
+	return &OpenDBRequest{Request: req, progress: progress, state: state}, nil
 }
 
-func openDBListenSuccess(req *Request) error {
+// abandon gives up on the request, closing the connection it opened, if any.
+func (o *OpenDBRequest) abandon() {
+	o.state.cancel()
+	if !o.state.abandoned.Swap(true) && o.state.settled.Load() {
+		closeOpenResult(o.Request)
+	}
+}
+
+// closeOpenResult closes the connection opened by req, if it succeeded.
+func closeOpenResult(req *Request) {
 	jsDB, err := req.Result()
+	if err != nil || jsDB.IsNull() || jsDB.IsUndefined() {
+		return
+	}
+	unregisterOpenConn(jsDB)
+	_, _ = jsDB.Call("close")
+}
+
+// listenAbandoned cleans up after an abandoned open request once it settles: an upgrade is aborted, so it can't leave the database at a new version with a partial schema, and an opened connection is closed.
+func listenAbandoned(req *Request) error {
+	var upgradeNeeded, settled safejs.Func
+	var releaseOnce sync.Once
+	release := func() {
+		releaseOnce.Do(func() {
+			_, _ = req.jsRequest.Call(removeEventListener, "upgradeneeded", upgradeNeeded)
+			_, _ = req.jsRequest.Call(removeEventListener, "success", settled)
+			_, _ = req.jsRequest.Call(removeEventListener, "error", settled)
+			upgradeNeeded.Release()
+			settled.Release()
+		})
+	}
+	upgradeNeeded, err := safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		txn, err := req.jsRequest.Get("transaction")
+		if err == nil {
+			_, _ = txn.Call("abort")
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
+	settled, err = safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		defer release()
+		closeOpenResult(req)
+		return nil
+	})
+	if err != nil {
+		upgradeNeeded.Release()
+		return err
+	}
+	for _, eventName := range []string{"upgradeneeded", "success", "error"} {
+		listener := settled
+		if eventName == "upgradeneeded" {
+			listener = upgradeNeeded
+		}
+		if _, err := req.jsRequest.Call(addEventListener, eventName, listener); err != nil {
+			release()
+			return tryAsDOMException(err)
+		}
+	}
+	return nil
+}
+
+// listenBlocked calls onBlocked each time req is blocked by connections which don't close for a version change, until ctx is done.
+func listenBlocked(ctx context.Context, req *Request, onBlocked func(VersionChange)) error {
+	blocked, err := safejs.FuncOf(func(this safejs.Value, args []safejs.Value) interface{} {
+		change, err := versionChangeOf(args[0])
+		if err != nil {
+			panic(err)
+		}
+		onBlocked(change)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = req.jsRequest.Call(addEventListener, "blocked", blocked)
+	if err != nil {
+		blocked.Release()
+		return tryAsDOMException(err)
+	}
+	go func() {
+		<-ctx.Done()
+		_, err := req.jsRequest.Call(removeEventListener, "blocked", blocked)
+		if err != nil {
+			panic(err)
+		}
+		blocked.Release()
+	}()
+	return nil
+}
+
+// versionChangeOf returns the versions of an IDBVersionChangeEvent.
+func versionChangeOf(event safejs.Value) (VersionChange, error) {
+	properties, err := getProperties(event, "oldVersion", "newVersion")
+	if err != nil {
+		return VersionChange{}, err
+	}
+	oldVersion, err := properties[0].Int()
+	if err != nil {
+		return VersionChange{}, err
+	}
+	var newVersion int
+	if !properties[1].IsNull() {
+		newVersion, err = properties[1].Int()
+		if err != nil {
+			return VersionChange{}, err
+		}
+	}
+	if oldVersion < 0 || newVersion < 0 {
+		return VersionChange{}, fmt.Errorf("Unexpected negative oldVersion or newVersion: %d, %d", oldVersion, newVersion)
+	}
+	return VersionChange{OldVersion: uint(oldVersion), NewVersion: uint(newVersion)}, nil
+}
+
+func openDBListenSuccess(req *Request, caller string, reservation *openReservation) error {
+	jsDB, err := req.Result()
+	if err != nil {
+		reservation.release()
+		return err
+	}
 	versionChange, err := safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
 		log.Println("Version change detected, closing DB...")
+		unregisterOpenConn(jsDB)
 		_, closeErr := jsDB.Call("close")
 		if closeErr != nil {
 			log.Println("Error closing DB:", closeErr)
@@ -75,36 +310,53 @@ func openDBListenSuccess(req *Request) error {
 		return err
 	}
 	_, err = jsDB.Call(addEventListener, "versionchange", versionChange)
-	return tryAsDOMException(err)
-}
-
-func openDBUpgradeNeeded(req *Request, upgrader Upgrader, args []safejs.Value) error {
-	event := args[0]
-	jsDatabase, err := req.Result()
 	if err != nil {
-		return err
+		return tryAsDOMException(err)
 	}
-	db := wrapDatabase(jsDatabase)
-	oldVersionValue, err := event.Get("oldVersion")
+	closed, err := safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		// the browser closed the connection, like when the database was deleted through developer tools
+		unregisterOpenConn(jsDB)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	oldVersion, err := oldVersionValue.Int()
+	_, err = jsDB.Call(addEventListener, "close", closed)
 	if err != nil {
-		return err
+		return tryAsDOMException(err)
 	}
-	newVersionValue, err := event.Get("newVersion")
+	return registerOpenConn(jsDB, caller, reservation)
+}
+
+func openDBUpgradeNeeded(req *Request, upgrader func(Upgrade) error, progress *upgradeProgress, args []safejs.Value) error {
+	event := args[0]
+	jsDatabase, err := req.Result()
 	if err != nil {
 		return err
 	}
-	newVersion, err := newVersionValue.Int()
-	if err != nil {
+	db := wrapDatabase(jsDatabase)
+	change, err := versionChangeOf(event)
+	if err != nil || upgrader == nil {
 		return err
 	}
-	if oldVersion < 0 || newVersion < 0 {
-		return fmt.Errorf("Unexpected negative oldVersion or newVersion: %d, %d", oldVersion, newVersion)
+	jsTransaction, err := req.jsRequest.Get("transaction")
+	if err != nil {
+		return err
 	}
-	return upgrader(db, uint(oldVersion), uint(newVersion))
+	return upgrader(Upgrade{
+		Database:    db,
+		Transaction: wrapTransaction(db, jsTransaction),
+		OldVersion:  change.OldVersion,
+		NewVersion:  change.NewVersion,
+		Event:       event,
+		progress:    progress,
+	})
+}
+
+// Progress returns a channel receiving the latest progress reported with Upgrade.ReportProgress during the upgrade, like for an "upgrading your data" screen.
+// The channel is closed once the request succeeds or fails.
+func (o *OpenDBRequest) Progress() <-chan UpgradeProgress {
+	return o.progress.ch
 }
 
 // Result returns the result of the request. If the request failed and the result is not available, an error is returned.
@@ -117,8 +369,19 @@ func (o *OpenDBRequest) Result() (*Database, error) {
 }
 
 // Await waits for success or failure, then returns the results.
+// If ctx, or the context passed to Factory.Open, is done first, the request is abandoned and the returned error wraps ErrOpenCanceled.
 func (o *OpenDBRequest) Await(ctx context.Context) (*Database, error) {
 	db, err := o.Request.Await(ctx)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil && errors.Is(err, ctxErr) {
+		o.abandon()
+	}
+	if o.state.abandoned.Load() {
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrOpenCanceled, err)
+		}
+		closeOpenResult(o.Request)
+		return nil, ErrOpenCanceled
+	}
 	if err != nil {
 		return nil, err
 	}
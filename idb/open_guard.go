@@ -0,0 +1,162 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/hack-pad/safejs"
+)
+
+// ErrConflictingOpen is returned by Factory.Open when this program already has an open connection to the database at a different version.
+// Opening another version would either fail or close the existing connection in a version change, leaving the code using it with a closed database.
+var ErrConflictingOpen = errors.New("database is already open at a different version")
+
+// openConn is an open connection to a database made by this program, or a reservation for one being opened.
+type openConn struct {
+	jsDB        safejs.Value // undefined while reserved
+	version     uint
+	caller      string           // where the connection was opened
+	reservation *openReservation // set until the open succeeds
+}
+
+// openReservation holds a database's name and version for an open in progress, so concurrent opens at other versions conflict before either reaches IndexedDB.
+type openReservation struct {
+	name string
+}
+
+var openConns = struct {
+	sync.Mutex
+	byName map[string][]openConn
+}{byName: make(map[string][]openConn)}
+
+// reserveOpen returns an error if name is open, or being opened, at a version other than version.
+// Otherwise it reserves name at version until registerOpenConn replaces the reservation with the opened connection, or the reservation is released because the open failed.
+// A version of 0 opens the current version, so it never conflicts and reserves nothing.
+func reserveOpen(name string, version uint, caller string) (*openReservation, error) {
+	if version == 0 {
+		return nil, nil
+	}
+	openConns.Lock()
+	defer openConns.Unlock()
+	for _, conn := range openConns.byName[name] {
+		if conn.version != version {
+			state := "open"
+			if conn.reservation != nil {
+				state = "being opened"
+			}
+			return nil, fmt.Errorf("%w: opening %q at version %d from %s, but it is %s at version %d from %s", ErrConflictingOpen, name, version, caller, state, conn.version, conn.caller)
+		}
+	}
+	reservation := &openReservation{name: name}
+	openConns.byName[name] = append(openConns.byName[name], openConn{jsDB: safejs.Undefined(), version: version, caller: caller, reservation: reservation})
+	return reservation, nil
+}
+
+// release gives up the reservation, after its open fails. Does nothing if r is nil or the reservation was already replaced or released.
+func (r *openReservation) release() {
+	if r == nil {
+		return
+	}
+	openConns.Lock()
+	defer openConns.Unlock()
+	removeOpenConn(r.name, func(conn openConn) bool {
+		return conn.reservation == r
+	})
+}
+
+// registerOpenConn records the connection opened from reservation, which may be nil.
+func registerOpenConn(jsDB safejs.Value, caller string, reservation *openReservation) error {
+	name, version, err := connNameVersion(jsDB)
+	if err != nil {
+		reservation.release()
+		return err
+	}
+	openConns.Lock()
+	defer openConns.Unlock()
+	if reservation != nil {
+		removeOpenConn(reservation.name, func(conn openConn) bool {
+			return conn.reservation == reservation
+		})
+	}
+	openConns.byName[name] = append(openConns.byName[name], openConn{jsDB: jsDB, version: version, caller: caller})
+	return nil
+}
+
+func unregisterOpenConn(jsDB safejs.Value) {
+	name, _, err := connNameVersion(jsDB)
+	if err != nil {
+		return
+	}
+	openConns.Lock()
+	defer openConns.Unlock()
+	removeOpenConn(name, func(conn openConn) bool {
+		return conn.reservation == nil && conn.jsDB.Equal(jsDB)
+	})
+}
+
+// removeOpenConn removes the first of name's connections matching match. Must be called with openConns locked.
+func removeOpenConn(name string, match func(openConn) bool) {
+	conns := openConns.byName[name]
+	for i, conn := range conns {
+		if match(conn) {
+			conns = append(conns[:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(conns) == 0 {
+		delete(openConns.byName, name)
+	} else {
+		openConns.byName[name] = conns
+	}
+}
+
+// closeOpenConns closes every connection to name opened by this program. Reservations for opens in progress are kept.
+func closeOpenConns(name string) {
+	openConns.Lock()
+	var conns, reserved []openConn
+	for _, conn := range openConns.byName[name] {
+		if conn.reservation != nil {
+			reserved = append(reserved, conn)
+		} else {
+			conns = append(conns, conn)
+		}
+	}
+	if len(reserved) == 0 {
+		delete(openConns.byName, name)
+	} else {
+		openConns.byName[name] = reserved
+	}
+	openConns.Unlock()
+	for _, conn := range conns {
+		_, _ = conn.jsDB.Call("close")
+	}
+}
+
+func connNameVersion(jsDB safejs.Value) (string, uint, error) {
+	db := wrapDatabase(jsDB)
+	name, err := db.Name()
+	if err != nil {
+		return "", 0, err
+	}
+	version, err := db.Version()
+	return name, version, err
+}
+
+// callerOutsidePackage returns the file and line of the first caller outside of this package, for error messages.
+func callerOutsidePackage() string {
+	const packagePrefix = "github.com/aperturerobotics/go-indexeddb/idb."
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go") || !more {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestOpenConflictingVersions(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+	open := func(version uint) (*Database, error) {
+		req, err := dbFactory.Open(ctx, name, version, func(*Database, uint, uint) error { return nil })
+		if err != nil {
+			return nil, err
+		}
+		return req.Await(ctx)
+	}
+
+	db1, err := open(1)
+	assert.NoError(t, err)
+	db2, err := open(1)
+	assert.NoError(t, err)
+	db3, err := open(0)
+	assert.NoError(t, err)
+
+	_, err = open(2)
+	assert.ErrorIs(t, err, ErrConflictingOpen)
+	assert.Equal(t, 2, strings.Count(err.Error(), "open_guard_test.go:"))
+
+	assert.NoError(t, db1.Close())
+	assert.NoError(t, db2.Close())
+	_, err = open(2)
+	assert.ErrorIs(t, err, ErrConflictingOpen)
+	assert.NoError(t, db3.Close())
+
+	db, err := open(2)
+	assert.NoError(t, err)
+	version, err := db.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), version)
+	assert.NoError(t, db.Close())
+
+	// an open in progress reserves its version
+	req, err := dbFactory.Open(ctx, name, 3, func(*Database, uint, uint) error { return nil })
+	assert.NoError(t, err)
+	_, err = open(4)
+	assert.ErrorIs(t, err, ErrConflictingOpen)
+	assert.Contains(t, err.Error(), "being opened")
+	db, err = req.Await(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	// a failed open releases its reservation
+	_, err = open(1)
+	assert.ErrorIs(t, err, ErrVersion)
+	db, err = open(3)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+}
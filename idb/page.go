@@ -0,0 +1,122 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hack-pad/safejs"
+)
+
+// Page is one page of records from ObjectStore.GetPage, in the direction it was read.
+type Page struct {
+	Keys   []safejs.Value
+	Values []safejs.Value
+	// NextKey is the afterKey to pass to GetPage for the next page. Undefined if this is the last page.
+	NextKey safejs.Value
+	// Token is NextKey encoded as an opaque string, for passing through URLs or storing across page loads. Empty if this is the last page. Decode it with PageTokenKey.
+	Token string
+}
+
+// GetPage returns up to limit records after afterKey, in direction order. If afterKey is undefined, starts at the first record in direction.
+// Pages are bounded by key ranges rather than offsets, so records added or deleted between pages don't shift later pages.
+func (o *ObjectStore) GetPage(ctx context.Context, afterKey safejs.Value, limit uint, direction CursorDirection) (Page, error) {
+	if limit == 0 {
+		return Page{}, errors.New("page limit must be at least 1")
+	}
+	ascending := direction == CursorNext || direction == CursorNextUnique
+	var keyRange *KeyRange
+	var err error
+	switch {
+	case afterKey.IsUndefined():
+	case ascending:
+		keyRange, err = NewKeyRangeLowerBound(afterKey, true)
+	default:
+		keyRange, err = NewKeyRangeUpperBound(afterKey, true)
+	}
+	if err != nil {
+		return Page{}, err
+	}
+
+	var page Page
+	if ascending {
+		// fetch one extra record to find out if there's another page
+		keysReq, err := o.base.getAllCount("getAllKeys", keyRange, limit+1)
+		if err != nil {
+			return Page{}, err
+		}
+		valuesReq, err := o.base.getAllCount("getAll", keyRange, limit)
+		if err != nil {
+			return Page{}, err
+		}
+		if page.Keys, err = keysReq.Await(ctx); err != nil {
+			return Page{}, err
+		}
+		if page.Values, err = valuesReq.Await(ctx); err != nil {
+			return Page{}, err
+		}
+	} else {
+		var req *CursorWithValueRequest
+		if keyRange == nil {
+			req, err = o.OpenCursor(direction)
+		} else {
+			req, err = o.OpenCursorRange(keyRange, direction)
+		}
+		if err != nil {
+			return Page{}, err
+		}
+		err = req.Iter(ctx, func(cursor *CursorWithValue) error {
+			key, err := cursor.Key()
+			if err != nil {
+				return err
+			}
+			page.Keys = append(page.Keys, key)
+			if uint(len(page.Keys)) > limit {
+				return ErrCursorStopIter
+			}
+			value, err := cursor.Value()
+			page.Values = append(page.Values, value)
+			return err
+		})
+		if err != nil {
+			return Page{}, err
+		}
+	}
+
+	page.NextKey = safejs.Undefined()
+	if uint(len(page.Keys)) > limit {
+		page.Keys = page.Keys[:limit]
+		page.NextKey = page.Keys[limit-1]
+		nextKey, err := encodeTokenKey(page.NextKey)
+		if err != nil {
+			return Page{}, err
+		}
+		b, err := json.Marshal(nextKey)
+		if err != nil {
+			return Page{}, err
+		}
+		page.Token = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return page, nil
+}
+
+// PageTokenKey decodes a Page's Token into the afterKey for the next page. An empty token decodes to undefined, which starts from the first record.
+func PageTokenKey(token string) (safejs.Value, error) {
+	if token == "" {
+		return safejs.Undefined(), nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return safejs.Undefined(), fmt.Errorf("%w: %v", ErrInvalidCursorToken, err)
+	}
+	var key tokenKey
+	if err := json.Unmarshal(b, &key); err != nil {
+		return safejs.Undefined(), fmt.Errorf("%w: %v", ErrInvalidCursorToken, err)
+	}
+	return key.decode()
+}
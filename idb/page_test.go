@@ -0,0 +1,79 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestObjectStoreGetPage(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := store.PutKeyValue(i, i*10)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, txn.Await(ctx))
+
+	for _, tc := range []struct {
+		name      string
+		direction CursorDirection
+		expect    [][]int
+	}{
+		{name: "next", direction: CursorNext, expect: [][]int{{0, 1}, {2, 3}, {4}}},
+		{name: "prev", direction: CursorPrevious, expect: [][]int{{4, 3}, {2, 1}, {0}}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var pages [][]int
+			token := ""
+			for {
+				txn, err := db.Transaction(TransactionReadOnly, "mystore")
+				assert.NoError(t, err)
+				store, err := txn.ObjectStore("mystore")
+				assert.NoError(t, err)
+				afterKey, err := PageTokenKey(token)
+				assert.NoError(t, err)
+				page, err := store.GetPage(ctx, afterKey, 2, tc.direction)
+				assert.NoError(t, err)
+				assert.Equal(t, len(page.Keys), len(page.Values))
+				var keys []int
+				for i, key := range page.Keys {
+					n, err := key.Int()
+					assert.NoError(t, err)
+					keys = append(keys, n)
+					value, err := page.Values[i].Int()
+					assert.NoError(t, err)
+					assert.Equal(t, n*10, value)
+				}
+				pages = append(pages, keys)
+				if page.Token == "" {
+					assert.Equal(t, true, page.NextKey.IsUndefined())
+					break
+				}
+				token = page.Token
+			}
+			assert.Equal(t, tc.expect, pages)
+		})
+	}
+
+	txn, err = db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.GetPage(ctx, safejs.Undefined(), 0, CursorNext)
+	assert.Error(t, err)
+}
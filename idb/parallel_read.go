@@ -0,0 +1,83 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ReadOp is a read-only operation run by ParallelRead in its own transaction.
+type ReadOp[T any] struct {
+	// ObjectStores are the names of the object stores in the transaction's scope. At least one is required.
+	ObjectStores []string
+	// Read performs the operation's requests on txn and returns its result.
+	// Read may be called again with a new transaction if the previous one finished prematurely, see RetryTxn.
+	Read func(ctx context.Context, txn *Transaction) (T, error)
+}
+
+// ParallelRead runs each operation in a separate read-only transaction, running at most concurrency operations at a time.
+// If concurrency is 0, runs all operations at once. Read-only transactions with overlapping scopes can run in parallel, so this can speed up loading independent data sets.
+//
+// Returns the results in the same order as ops. If any operation fails, ctx passed to the other operations is canceled and the first error is returned.
+func ParallelRead[T any](ctx context.Context, db *Database, ops []ReadOp[T], concurrency int) ([]T, error) {
+	for _, op := range ops {
+		if len(op.ObjectStores) == 0 {
+			return nil, errors.New("read operation must include at least one object store")
+		}
+		if op.Read == nil {
+			return nil, errors.New("read operation must set Read")
+		}
+	}
+	if concurrency <= 0 || concurrency > len(ops) {
+		concurrency = len(ops)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	results := make([]T, len(ops))
+	sem := make(chan struct{}, concurrency)
+	for i := range ops {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, op ReadOp[T]) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := RetryTxn(ctx, db, TransactionReadOnly, func(txn *Transaction) error {
+				result, err := op.Read(ctx, txn)
+				if err != nil {
+					return err
+				}
+				results[i] = result
+				return nil
+			}, op.ObjectStores[0], op.ObjectStores[1:]...)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i, ops[i])
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,79 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestParallelRead(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	storeNames := []string{"a", "b", "c"}
+	db := testDB(t, func(db *Database) {
+		for _, name := range storeNames {
+			_, err := db.CreateObjectStore(name, ObjectStoreOptions{})
+			assert.NoError(t, err)
+		}
+	})
+	txn, err := db.Transaction(TransactionReadWrite, storeNames[0], storeNames[1:]...)
+	assert.NoError(t, err)
+	for i, name := range storeNames {
+		store, err := txn.ObjectStore(name)
+		assert.NoError(t, err)
+		for j := 0; j <= i; j++ {
+			_, err := store.AddKey(safejs.Safe(js.ValueOf(j)), safejs.Safe(js.ValueOf(name)))
+			assert.NoError(t, err)
+		}
+	}
+	assert.NoError(t, txn.Await(ctx))
+
+	countOp := func(name string) ReadOp[uint] {
+		return ReadOp[uint]{
+			ObjectStores: []string{name},
+			Read: func(ctx context.Context, txn *Transaction) (uint, error) {
+				store, err := txn.ObjectStore(name)
+				if err != nil {
+					return 0, err
+				}
+				req, err := store.Count()
+				if err != nil {
+					return 0, err
+				}
+				return req.Await(ctx)
+			},
+		}
+	}
+
+	t.Run("results in order", func(t *testing.T) {
+		for _, concurrency := range []int{0, 1, 2} {
+			counts, err := ParallelRead(ctx, db, []ReadOp[uint]{countOp("c"), countOp("a"), countOp("b")}, concurrency)
+			assert.NoError(t, err)
+			assert.Equal(t, []uint{3, 1, 2}, counts)
+		}
+	})
+
+	t.Run("first error", func(t *testing.T) {
+		someErr := errors.New("some error")
+		_, err := ParallelRead(ctx, db, []ReadOp[uint]{
+			countOp("a"),
+			{
+				ObjectStores: []string{"b"},
+				Read: func(context.Context, *Transaction) (uint, error) {
+					return 0, someErr
+				},
+			},
+		}, 1)
+		assert.Equal(t, someErr, err)
+
+		_, err = ParallelRead(ctx, db, []ReadOp[uint]{{Read: countOp("a").Read}}, 1)
+		assert.Error(t, err)
+	})
+}
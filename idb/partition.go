@@ -0,0 +1,185 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+
+	"github.com/hack-pad/safejs"
+)
+
+// StorePartition is a separate keyspace within an object store, created with ObjectStore.Partition.
+// Keys are stored as [prefix, key] arrays, and every read or write is bounded to the partition's prefix, so many logical collections can share one store without a version change per collection.
+type StorePartition struct {
+	store  *ObjectStore
+	prefix string
+}
+
+// Partition returns the keyspace of this store whose keys are prefixed by prefix. Keys passed to and returned from the partition are never prefixed.
+// The store must use out-of-line keys. Partitions don't overlap, even when one prefix starts with another.
+func (o *ObjectStore) Partition(prefix string) *StorePartition {
+	return &StorePartition{store: o, prefix: prefix}
+}
+
+// Prefix returns the partition's key prefix.
+func (p *StorePartition) Prefix() string {
+	return p.prefix
+}
+
+func (p *StorePartition) key(key safejs.Value) (safejs.Value, error) {
+	return jsArray.Call("of", p.prefix, key)
+}
+
+func (p *StorePartition) unprefixKey(key safejs.Value) (safejs.Value, error) {
+	return key.Index(1)
+}
+
+// keyRange returns the store key range for keyRange within the partition. A nil range is the whole partition.
+func (p *StorePartition) keyRange(keyRange *KeyRange) (*KeyRange, error) {
+	b, err := parseRangeBounds(keyRange)
+	if err != nil {
+		return nil, err
+	}
+	if b.hasLower {
+		b.lower, err = p.key(b.lower)
+	} else {
+		// [prefix] sorts before every [prefix, key]
+		b.lower, err = jsArray.Call("of", p.prefix)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b.hasUpper {
+		b.upper, err = p.key(b.upper)
+	} else {
+		// [prefix + "\x00"] sorts after every [prefix, key], and before the next prefix starting with prefix
+		b.upper, err = jsArray.Call("of", p.prefix+"\x00")
+		b.upperOpen = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.hasLower, b.hasUpper = true, true
+	return b.keyRange()
+}
+
+// Get returns a Request which retrieves the value at key.
+func (p *StorePartition) Get(key safejs.Value) (*Request, error) {
+	storeKey, err := p.key(key)
+	if err != nil {
+		return nil, err
+	}
+	return p.store.Get(storeKey)
+}
+
+// AddKey returns an AckRequest which stores value at key. Fails if a record already exists at key.
+func (p *StorePartition) AddKey(key, value safejs.Value) (*AckRequest, error) {
+	storeKey, err := p.key(key)
+	if err != nil {
+		return nil, err
+	}
+	return p.store.AddKey(storeKey, value)
+}
+
+// PutKey returns an AckRequest which stores value at key, replacing any existing record.
+func (p *StorePartition) PutKey(key, value safejs.Value) (*AckRequest, error) {
+	storeKey, err := p.key(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := p.store.PutKey(storeKey, value)
+	if err != nil {
+		return nil, err
+	}
+	return newAckRequest(req), nil
+}
+
+// Delete returns an AckRequest which deletes the record at key.
+func (p *StorePartition) Delete(key safejs.Value) (*AckRequest, error) {
+	storeKey, err := p.key(key)
+	if err != nil {
+		return nil, err
+	}
+	return p.store.Delete(storeKey)
+}
+
+// DeleteRange returns an AckRequest which deletes the partition's records in keyRange. If keyRange is nil, deletes every record in the partition.
+func (p *StorePartition) DeleteRange(keyRange *KeyRange) (*AckRequest, error) {
+	storeRange, err := p.keyRange(keyRange)
+	if err != nil {
+		return nil, err
+	}
+	return p.store.Delete(storeRange.jsKeyRange)
+}
+
+// CountRange returns a UintRequest which counts the partition's records in keyRange. If keyRange is nil, counts every record in the partition.
+func (p *StorePartition) CountRange(keyRange *KeyRange) (*UintRequest, error) {
+	storeRange, err := p.keyRange(keyRange)
+	if err != nil {
+		return nil, err
+	}
+	return p.store.CountRange(storeRange)
+}
+
+// GetAllRange returns an ArrayRequest which retrieves the values of the partition's records in keyRange. If keyRange is nil, retrieves every record in the partition. If maxCount is 0, retrieves all matching records.
+func (p *StorePartition) GetAllRange(keyRange *KeyRange, maxCount uint) (*ArrayRequest, error) {
+	storeRange, err := p.keyRange(keyRange)
+	if err != nil {
+		return nil, err
+	}
+	return p.store.GetAllRange(storeRange, maxCount)
+}
+
+// GetAllKeysRange returns the keys of the partition's records in keyRange. If keyRange is nil, returns every key in the partition. If maxCount is 0, returns all matching keys.
+func (p *StorePartition) GetAllKeysRange(ctx context.Context, keyRange *KeyRange, maxCount uint) ([]safejs.Value, error) {
+	storeRange, err := p.keyRange(keyRange)
+	if err != nil {
+		return nil, err
+	}
+	req, err := p.store.GetAllKeysRange(storeRange, maxCount)
+	if err != nil {
+		return nil, err
+	}
+	storeKeys, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]safejs.Value, 0, len(storeKeys))
+	for _, storeKey := range storeKeys {
+		key, err := p.unprefixKey(storeKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Iter calls iter with the key and value of each of the partition's records in keyRange, in direction order. If keyRange is nil, iterates over every record in the partition.
+// Return ErrCursorStopIter to stop early.
+func (p *StorePartition) Iter(ctx context.Context, keyRange *KeyRange, direction CursorDirection, iter func(key, value safejs.Value) error) error {
+	storeRange, err := p.keyRange(keyRange)
+	if err != nil {
+		return err
+	}
+	req, err := p.store.OpenCursorRange(storeRange, direction)
+	if err != nil {
+		return err
+	}
+	return req.Iter(ctx, func(cursor *CursorWithValue) error {
+		storeKey, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		key, err := p.unprefixKey(storeKey)
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		return iter(key, value)
+	})
+}
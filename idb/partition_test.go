@@ -0,0 +1,97 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestStorePartition(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	jsValue := func(value interface{}) safejs.Value {
+		jsValue, err := ValueOf(value)
+		assert.NoError(t, err)
+		return jsValue
+	}
+	ints := func(values []safejs.Value) []int {
+		var result []int
+		for _, value := range values {
+			n, err := value.Int()
+			assert.NoError(t, err)
+			result = append(result, n)
+		}
+		return result
+	}
+
+	// "users2" starts with "users", but must not overlap with it
+	users := store.Partition("users")
+	users2 := store.Partition("users2")
+	_, err = store.PutKeyValue("unpartitioned", 100)
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		_, err := users.PutKey(jsValue(i), jsValue(i*10))
+		assert.NoError(t, err)
+		_, err = users2.AddKey(jsValue(i), jsValue(i*20))
+		assert.NoError(t, err)
+	}
+
+	req, err := users.Get(jsValue(2))
+	assert.NoError(t, err)
+	value, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{20}, ints([]safejs.Value{value}))
+
+	keys, err := users.GetAllKeysRange(ctx, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, ints(keys))
+
+	keyRange, err := NewKeyRangeLowerBoundOf(1, true)
+	assert.NoError(t, err)
+	valuesReq, err := users2.GetAllRange(keyRange, 0)
+	assert.NoError(t, err)
+	values, err := valuesReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{40, 60}, ints(values))
+
+	var visited []int
+	err = users.Iter(ctx, nil, CursorPrevious, func(key, value safejs.Value) error {
+		n, err := key.Int()
+		visited = append(visited, n)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 2, 1, 0}, visited)
+
+	deleteReq, err := users.Delete(jsValue(0))
+	assert.NoError(t, err)
+	assert.NoError(t, deleteReq.Await(ctx))
+	countReq, err := users.CountRange(nil)
+	assert.NoError(t, err)
+	count, err := countReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), count)
+
+	deleteReq, err = users.DeleteRange(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, deleteReq.Await(ctx))
+	countReq, err = store.Count()
+	assert.NoError(t, err)
+	count, err = countReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), count) // users2 and the unpartitioned record remain
+	assert.NoError(t, txn.Await(ctx))
+}
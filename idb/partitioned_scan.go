@@ -0,0 +1,141 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hack-pad/safejs"
+)
+
+// PartitionKeyRanges splits the keys of the object store named storeName into at most n key ranges with about the same number of records each, in ascending order.
+// Partition boundaries are sampled by advancing a key cursor, so the store's values aren't read.
+//
+// Returns a single nil range, matching all keys, if the store has too few records to split.
+func PartitionKeyRanges(ctx context.Context, db *Database, storeName string, n int) ([]*KeyRange, error) {
+	if n < 1 {
+		return nil, errors.New("number of partitions must be at least 1")
+	}
+	var boundaries []safejs.Value
+	err := RetryTxn(ctx, db, TransactionReadOnly, func(txn *Transaction) error {
+		boundaries = nil
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return err
+		}
+		countReq, err := store.Count()
+		if err != nil {
+			return err
+		}
+		count, err := countReq.Await(ctx)
+		if err != nil {
+			return err
+		}
+		step := count / uint(n)
+		if n == 1 || step == 0 {
+			return nil
+		}
+		req, err := store.OpenKeyCursor(CursorNext)
+		if err != nil {
+			return err
+		}
+		cursor, err := req.Await(ctx)
+		for i := 1; i < n; i++ {
+			if err != nil || cursor == nil {
+				return err
+			}
+			if err := cursor.Advance(step); err != nil {
+				return err
+			}
+			cursor, err = req.Await(ctx)
+			if err != nil || cursor == nil {
+				return err
+			}
+			key, err := cursor.Key()
+			if err != nil {
+				return err
+			}
+			boundaries = append(boundaries, key)
+		}
+		return err
+	}, storeName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(boundaries) == 0 {
+		return []*KeyRange{nil}, nil
+	}
+	keyRanges := make([]*KeyRange, 0, len(boundaries)+1)
+	keyRange, err := NewKeyRangeUpperBound(boundaries[0], true)
+	if err != nil {
+		return nil, err
+	}
+	keyRanges = append(keyRanges, keyRange)
+	for i := 1; i < len(boundaries); i++ {
+		keyRange, err := NewKeyRangeBound(boundaries[i-1], boundaries[i], false, true)
+		if err != nil {
+			return nil, err
+		}
+		keyRanges = append(keyRanges, keyRange)
+	}
+	keyRange, err = NewKeyRangeLowerBound(boundaries[len(boundaries)-1], false)
+	if err != nil {
+		return nil, err
+	}
+	return append(keyRanges, keyRange), nil
+}
+
+// ParallelScan reads every record of the object store named storeName, splitting its keys into partitions which are read concurrently in separate read-only transactions.
+// Use it to speed up full scans of large stores. See PartitionKeyRanges and ParallelRead.
+//
+// fn is called with each record's key and value, and its results are returned in key order. Records for which fn returns false are left out.
+// fn is called from several goroutines at once, and may be called again for the records of a partition whose transaction finished prematurely.
+func ParallelScan[T any](ctx context.Context, db *Database, storeName string, partitions int, fn func(key, value safejs.Value) (T, bool, error)) ([]T, error) {
+	keyRanges, err := PartitionKeyRanges(ctx, db, storeName, partitions)
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]ReadOp[[]T], 0, len(keyRanges))
+	for _, keyRange := range keyRanges {
+		keyRange := keyRange
+		ops = append(ops, ReadOp[[]T]{
+			ObjectStores: []string{storeName},
+			Read: func(ctx context.Context, txn *Transaction) ([]T, error) {
+				store, err := txn.ObjectStore(storeName)
+				if err != nil {
+					return nil, err
+				}
+				var req *CursorWithValueRequest
+				if keyRange == nil {
+					req, err = store.OpenCursor(CursorNext)
+				} else {
+					req, err = store.OpenCursorRange(keyRange, CursorNext)
+				}
+				if err != nil {
+					return nil, err
+				}
+				var results []T
+				err = req.IterBatch(ctx, defaultBatchSize, func(key, value safejs.Value) error {
+					result, include, err := fn(key, value)
+					if include && err == nil {
+						results = append(results, result)
+					}
+					return err
+				})
+				return results, err
+			},
+		})
+	}
+	partitionResults, err := ParallelRead(ctx, db, ops, 0)
+	if err != nil {
+		return nil, err
+	}
+	var results []T
+	for _, partition := range partitionResults {
+		results = append(results, partition...)
+	}
+	return results, nil
+}
@@ -0,0 +1,66 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestParallelScan(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("empty", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		_, err := store.PutKeyValue(i, i*2)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, txn.Await(ctx))
+
+	t.Run("partition key ranges", func(t *testing.T) {
+		keyRanges, err := PartitionKeyRanges(ctx, db, "mystore", 4)
+		assert.NoError(t, err)
+		var intervals []string
+		for _, keyRange := range keyRanges {
+			intervals = append(intervals, keyRangeString(t, keyRange))
+		}
+		assert.Equal(t, []string{"(-∞, 25)", "[25, 50)", "[50, 75)", "[75, ∞)"}, intervals)
+
+		keyRanges, err = PartitionKeyRanges(ctx, db, "empty", 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []*KeyRange{nil}, keyRanges)
+
+		_, err = PartitionKeyRanges(ctx, db, "mystore", 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		for _, partitions := range []int{1, 3, 7, 200} {
+			results, err := ParallelScan(ctx, db, "mystore", partitions, func(key, value safejs.Value) (int, bool, error) {
+				n, err := value.Int()
+				return n, n%3 == 0, err
+			})
+			assert.NoError(t, err)
+			var expect []int
+			for i := 0; i < 100; i++ {
+				if i*2%3 == 0 {
+					expect = append(expect, i*2)
+				}
+			}
+			assert.Equal(t, expect, results)
+		}
+	})
+}
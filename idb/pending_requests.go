@@ -0,0 +1,265 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// RequestInfo describes a request made in a transaction.
+type RequestInfo struct {
+	// Op is the IndexedDB method which made the request, like "put" or "openCursor".
+	Op string
+	// Source is the name of the object store or index the request was made on.
+	Source string
+	// Started is when the request was made.
+	Started time.Time
+}
+
+func (r RequestInfo) String() string {
+	return fmt.Sprintf("%s on %q for %s", r.Op, r.Source, time.Since(r.Started).Round(time.Millisecond))
+}
+
+// requestRegistry tracks the requests made in a transaction through this package, for PendingRequests, Stats, and diagnostics.
+// Requests are dropped once they settle, leaving only counters, so their results don't stay reachable for the life of the transaction.
+type requestRegistry struct {
+	mu sync.Mutex
+	// pending are the requests awaiting a result, which hold on to their JS requests only until they settle
+	pending []*trackedRequest
+	// cursors are the settled cursor requests, which are pending again each time their cursor moves
+	cursors         []*trackedRequest
+	nextID          int
+	issued          int
+	succeeded       int
+	lastSucceeded   RequestInfo
+	lastSucceededID int
+	lastActivity    time.Time
+	listening       bool        // settledListener is set once the first request is tracked
+	settledListener safejs.Func // released once the transaction finishes
+	finished        bool
+}
+
+type trackedRequest struct {
+	id        int
+	info      RequestInfo
+	jsRequest safejs.Value
+	succeeded bool
+}
+
+// trackRequest records jsRequest, made by the IndexedDB method op, until it settles. If diagnostics are enabled, warns if it was made too long after the transaction's last activity.
+func (t *Transaction) trackRequest(op string, jsRequest safejs.Value) {
+	if t == nil {
+		return
+	}
+	r := &t.requests
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.finished {
+		return
+	}
+	if t.diagnostics != nil && len(r.pending) == 0 {
+		t.diagnostics.checkRequestGap(time.Since(r.lastActivity))
+	}
+	r.lastActivity = time.Now()
+	if !r.listening && !t.listenSettled() {
+		return
+	}
+
+	req := &trackedRequest{
+		id:        r.nextID,
+		info:      RequestInfo{Op: op, Started: time.Now()},
+		jsRequest: jsRequest,
+	}
+	if properties, err := jsGetNested(jsRequest, "source", "name"); err == nil {
+		req.info.Source, _ = properties[2].String()
+	}
+	for _, event := range []string{"success", "error"} {
+		if _, err := jsRequest.Call(addEventListener, event, r.settledListener); err != nil {
+			return
+		}
+	}
+	r.nextID++
+	r.issued++
+	r.pending = append(r.pending, req)
+}
+
+// listenSettled creates the listener shared by every tracked request, and releases it when the transaction finishes. Must be called with requests.mu held.
+func (t *Transaction) listenSettled() bool {
+	r := &t.requests
+	settled, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		if len(args) > 0 {
+			r.settle(args[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return false
+	}
+	var finished safejs.Func
+	finished, err = safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		for _, event := range []string{"complete", "abort"} {
+			_, _ = t.jsTransaction.Call(removeEventListener, event, finished)
+		}
+		r.mu.Lock()
+		r.finished = true
+		r.pending, r.cursors = nil, nil
+		r.mu.Unlock()
+		// requests of a finished transaction don't fire events, so the listener is no longer needed
+		settled.Release()
+		go finished.Release()
+		return nil
+	})
+	if err != nil {
+		settled.Release()
+		return false
+	}
+	for _, event := range []string{"complete", "abort"} {
+		if _, err := t.jsTransaction.Call(addEventListener, event, finished); err != nil {
+			settled.Release()
+			finished.Release()
+			return false
+		}
+	}
+	r.settledListener = settled
+	r.listening = true
+	return true
+}
+
+// settle records the result of the request which fired event.
+func (r *requestRegistry) settle(event safejs.Value) {
+	target, err := event.Get("target")
+	if err != nil {
+		return
+	}
+	eventType, err := event.Get("type")
+	if err != nil {
+		return
+	}
+	succeeded, _ := eventType.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastActivity = time.Now()
+	req := removeRequest(&r.pending, target)
+	if req == nil {
+		return
+	}
+	if succeeded != "success" {
+		return
+	}
+	if !req.succeeded {
+		req.succeeded = true
+		r.succeeded++
+		// requests run in the order they're made, but a moved cursor's request settles again later
+		if r.lastSucceeded.Op == "" || req.id > r.lastSucceededID {
+			r.lastSucceeded, r.lastSucceededID = req.info, req.id
+		}
+	}
+	if isCursorOp(req.info.Op) {
+		if result, err := target.Get("result"); err == nil && !result.IsNull() {
+			r.cursors = append(r.cursors, req)
+		}
+	}
+}
+
+// resumeCursor marks the request of jsCursor as pending again, after the cursor moves.
+func (t *Transaction) resumeCursor(jsCursor safejs.Value) {
+	if t == nil {
+		return
+	}
+	jsRequest, err := jsCursor.Get("request")
+	if err != nil {
+		return
+	}
+	r := &t.requests
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastActivity = time.Now()
+	if req := removeRequest(&r.cursors, jsRequest); req != nil {
+		r.pending = append(r.pending, req)
+		sort.Slice(r.pending, func(i, j int) bool {
+			return r.pending[i].id < r.pending[j].id
+		})
+	}
+}
+
+// removeRequest removes and returns the request in requests for jsRequest, or returns nil if there is none.
+func removeRequest(requests *[]*trackedRequest, jsRequest safejs.Value) *trackedRequest {
+	for i, req := range *requests {
+		if req.jsRequest.Equal(jsRequest) {
+			*requests = append((*requests)[:i], (*requests)[i+1:]...)
+			return req
+		}
+	}
+	return nil
+}
+
+func isCursorOp(op string) bool {
+	return op == "openCursor" || op == "openKeyCursor"
+}
+
+// PendingRequests returns the requests made in the transaction through this package which haven't finished yet, oldest first.
+// A cursor's request is pending again each time the cursor moves, until its next position is available.
+func (t *Transaction) PendingRequests() []RequestInfo {
+	r := &t.requests
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []RequestInfo
+	for _, req := range r.pending {
+		pending = append(pending, req.info)
+	}
+	return pending
+}
+
+// TransactionStats summarizes the requests made in a transaction through this package.
+type TransactionStats struct {
+	// Issued is the number of requests made.
+	Issued int
+	// Succeeded is the number of requests which finished without an error.
+	Succeeded int
+	// LastSucceeded is the last request to succeed. Its Op is empty if none did.
+	LastSucceeded RequestInfo
+}
+
+func (s TransactionStats) String() string {
+	summary := fmt.Sprintf("%d of %d requests succeeded", s.Succeeded, s.Issued)
+	if s.LastSucceeded.Op != "" {
+		summary += fmt.Sprintf(", last %s on %q", s.LastSucceeded.Op, s.LastSucceeded.Source)
+	}
+	return summary
+}
+
+// Stats returns a summary of the requests made in the transaction through this package, for finding how much of a partially applied batch went through.
+// Requests run in the order they're made, so every request before LastSucceeded has finished too.
+func (t *Transaction) Stats() TransactionStats {
+	r := &t.requests
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return TransactionStats{Issued: r.issued, Succeeded: r.succeeded, LastSucceeded: r.lastSucceeded}
+}
+
+// withStats adds the transaction's stats to err. Returns err unchanged if it's nil or no requests were made through this package.
+func (t *Transaction) withStats(err error) error {
+	if t == nil || err == nil {
+		return err
+	}
+	stats := t.Stats()
+	if stats.Issued == 0 {
+		return err
+	}
+	return fmt.Errorf("%s: %w", stats, err)
+}
+
+func describeRequests(requests []RequestInfo) string {
+	descriptions := make([]string, 0, len(requests))
+	for _, req := range requests {
+		descriptions = append(descriptions, req.String())
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,112 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestTransactionPendingRequests(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(txn.PendingRequests()))
+
+	putReq, err := store.PutKeyValue("key", "value")
+	assert.NoError(t, err)
+	getReq, err := store.GetValue("key")
+	assert.NoError(t, err)
+	pending := txn.PendingRequests()
+	assert.Equal(t, 2, len(pending))
+	assert.Equal(t, "put", pending[0].Op)
+	assert.Equal(t, "mystore", pending[0].Source)
+	assert.Equal(t, "get", pending[1].Op)
+
+	assert.NoError(t, AwaitAll(ctx, putReq, getReq))
+	assert.Equal(t, 0, len(txn.PendingRequests()))
+	assert.NoError(t, txn.Await(ctx))
+}
+
+func TestTransactionStats(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionStats{}, txn.Stats())
+
+	key, err := ValueOf("key")
+	assert.NoError(t, err)
+	value, err := ValueOf("value")
+	assert.NoError(t, err)
+	_, err = store.PutKey(key, value)
+	assert.NoError(t, err)
+	req, err := store.AddKey(key, value)
+	assert.NoError(t, err)
+	err = req.Await(ctx)
+	assert.ErrorIs(t, err, NewDOMException("ConstraintError"))
+	assert.Contains(t, err.Error(), `1 of 2 requests succeeded, last put on "mystore": `)
+
+	stats := txn.Stats()
+	assert.Equal(t, 2, stats.Issued)
+	assert.Equal(t, 1, stats.Succeeded)
+	assert.Equal(t, "put", stats.LastSucceeded.Op)
+	assert.Equal(t, "mystore", stats.LastSucceeded.Source)
+}
+
+func TestTransactionPendingCursorRequests(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := store.PutKeyValue(i, i)
+		assert.NoError(t, err)
+	}
+	req, err := store.OpenCursor(CursorNext)
+	assert.NoError(t, err)
+	cursor, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(txn.PendingRequests()))
+
+	assert.NoError(t, cursor.Continue())
+	pending := txn.PendingRequests()
+	assert.Equal(t, 1, len(pending))
+	assert.Equal(t, "openCursor", pending[0].Op)
+	_, err = req.Await(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, cursor.Continue())
+	cursor, err = req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, true, cursor.Unwrap().IsNull())
+
+	// settled requests aren't kept, so their results can be garbage collected
+	txn.requests.mu.Lock()
+	tracked := len(txn.requests.pending) + len(txn.requests.cursors)
+	txn.requests.mu.Unlock()
+	assert.Equal(t, 0, tracked)
+	assert.Equal(t, 3, txn.Stats().Issued)
+	assert.NoError(t, txn.Await(ctx))
+}
@@ -0,0 +1,82 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// PreloadSpec describes the records read by Database.Preload.
+type PreloadSpec struct {
+	// Stores are the names of the object stores to read.
+	Stores []string
+	// Ranges optionally limits the records read from each store, keyed by store name. Stores without a range are read entirely.
+	Ranges map[string]*KeyRange
+	// OnRecord is called with each record read, for example to populate an in-memory cache. Optional.
+	// If a store's transaction finishes prematurely, its records are read again from the start.
+	OnRecord func(storeName string, key, value safejs.Value) error
+}
+
+// PreloadResult contains timing results for a store read by Database.Preload.
+type PreloadResult struct {
+	// Store is the name of the object store.
+	Store string
+	// Records is the number of records read.
+	Records uint
+	// Duration is the time from the start of Preload until the store finished loading.
+	Duration time.Duration
+}
+
+// Preload reads the records in spec, reading each store in parallel with its own read-only transaction.
+// Call it right after opening the database to overlap IndexedDB's disk latency with the rest of the app's initialization.
+//
+// Returns a result for each store, in the same order as spec.Stores.
+func (db *Database) Preload(ctx context.Context, spec PreloadSpec) ([]PreloadResult, error) {
+	start := time.Now()
+	ops := make([]ReadOp[PreloadResult], 0, len(spec.Stores))
+	for _, storeName := range spec.Stores {
+		storeName := storeName
+		keyRange := spec.Ranges[storeName]
+		ops = append(ops, ReadOp[PreloadResult]{
+			ObjectStores: []string{storeName},
+			Read: func(ctx context.Context, txn *Transaction) (PreloadResult, error) {
+				result := PreloadResult{Store: storeName}
+				store, err := txn.ObjectStore(storeName)
+				if err != nil {
+					return result, err
+				}
+				var req *CursorWithValueRequest
+				if keyRange == nil {
+					req, err = store.OpenCursor(CursorNext)
+				} else {
+					req, err = store.OpenCursorRange(keyRange, CursorNext)
+				}
+				if err != nil {
+					return result, err
+				}
+				err = req.Iter(ctx, func(cursor *CursorWithValue) error {
+					result.Records++
+					if spec.OnRecord == nil {
+						return nil
+					}
+					key, err := cursor.Key()
+					if err != nil {
+						return err
+					}
+					value, err := cursor.Value()
+					if err != nil {
+						return err
+					}
+					return spec.OnRecord(storeName, key, value)
+				})
+				result.Duration = time.Since(start)
+				return result, err
+			},
+		})
+	}
+	return ParallelRead(ctx, db, ops, 0)
+}
@@ -0,0 +1,56 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestDatabasePreload(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		for _, name := range []string{"settings", "items"} {
+			_, err := db.CreateObjectStore(name, ObjectStoreOptions{})
+			assert.NoError(t, err)
+		}
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "settings", "items")
+	assert.NoError(t, err)
+	settings, err := txn.ObjectStore("settings")
+	assert.NoError(t, err)
+	_, err = settings.PutKeyValue("theme", "dark")
+	assert.NoError(t, err)
+	items, err := txn.ObjectStore("items")
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := items.PutKeyValue(i, i*10)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, txn.Await(ctx))
+
+	keyRange, err := NewKeyRangeLowerBound(safejs.Safe(js.ValueOf(3)), false)
+	assert.NoError(t, err)
+	cache := make(map[string]int)
+	results, err := db.Preload(ctx, PreloadSpec{
+		Stores: []string{"settings", "items"},
+		Ranges: map[string]*KeyRange{"items": keyRange},
+		OnRecord: func(storeName string, key, value safejs.Value) error {
+			cache[storeName]++
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"settings": 1, "items": 2}, cache)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "settings", results[0].Store)
+	assert.Equal(t, uint(1), results[0].Records)
+	assert.Equal(t, "items", results[1].Store)
+	assert.Equal(t, uint(2), results[1].Records)
+}
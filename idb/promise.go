@@ -0,0 +1,53 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+// awaitPromise waits for promise to resolve or reject, then returns its result.
+func awaitPromise(ctx context.Context, promise safejs.Value) (safejs.Value, error) {
+	resultCh := make(chan safejs.Value, 1)
+	errCh := make(chan error, 1)
+	var resolve, reject safejs.Func
+	release := func() {
+		// released only after the promise settles, since it may settle after ctx is done
+		resolve.Release()
+		reject.Release()
+	}
+	resolve, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		defer release()
+		resultCh <- args[0]
+		return nil
+	})
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	reject, err = safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		defer release()
+		errCh <- tryAsDOMException(js.Error{Value: safejs.Unsafe(args[0])})
+		return nil
+	})
+	if err != nil {
+		resolve.Release()
+		return safejs.Undefined(), err
+	}
+	if _, err := promise.Call("then", resolve, reject); err != nil {
+		release()
+		return safejs.Undefined(), err
+	}
+
+	select {
+	case result := <-resultCh:
+		return result, nil
+	case err := <-errCh:
+		return safejs.Undefined(), err
+	case <-ctx.Done():
+		return safejs.Undefined(), ctx.Err()
+	}
+}
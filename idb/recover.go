@@ -0,0 +1,68 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// RecoveryFunc repairs state left behind by an interrupted run of the program, like work which was started but not finished before a tab closed.
+// Returns a description of each action taken, or none if there was nothing to recover.
+type RecoveryFunc func(ctx context.Context, db *Database) (actions []string, err error)
+
+type recoveryRoutine struct {
+	name  string
+	order int
+	fn    RecoveryFunc
+}
+
+var recoveryRoutines = struct {
+	sync.Mutex
+	routines []recoveryRoutine
+}{}
+
+// RegisterRecovery registers a recovery routine for Recover to run. Routines run in ascending order, then in the order they were registered.
+// Packages which keep durable state across runs register their routines in init, so applications recover all of them with a single call to Recover.
+func RegisterRecovery(name string, order int, fn RecoveryFunc) {
+	recoveryRoutines.Lock()
+	defer recoveryRoutines.Unlock()
+	recoveryRoutines.routines = append(recoveryRoutines.routines, recoveryRoutine{name: name, order: order, fn: fn})
+	sort.SliceStable(recoveryRoutines.routines, func(i, j int) bool {
+		return recoveryRoutines.routines[i].order < recoveryRoutines.routines[j].order
+	})
+}
+
+// RecoveryAction is an action taken by a recovery routine.
+type RecoveryAction struct {
+	Routine string
+	Action  string
+}
+
+// RecoveryReport lists the actions taken by Recover.
+type RecoveryReport struct {
+	Actions []RecoveryAction
+}
+
+// Recover runs every registered recovery routine on db, in order. Call it when the program starts, before using db.
+// Stops at the first routine which fails, returning the actions taken so far.
+func Recover(ctx context.Context, db *Database) (RecoveryReport, error) {
+	recoveryRoutines.Lock()
+	routines := append([]recoveryRoutine(nil), recoveryRoutines.routines...)
+	recoveryRoutines.Unlock()
+
+	var report RecoveryReport
+	for _, routine := range routines {
+		actions, err := routine.fn(ctx, db)
+		for _, action := range actions {
+			report.Actions = append(report.Actions, RecoveryAction{Routine: routine.name, Action: action})
+		}
+		if err != nil {
+			return report, fmt.Errorf("recovering %s: %w", routine.name, err)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,56 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestRecover(t *testing.T) {
+	// not parallel, since it changes the registered routines
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {})
+	recoveryRoutines.Lock()
+	saved := recoveryRoutines.routines
+	recoveryRoutines.routines = nil
+	recoveryRoutines.Unlock()
+	t.Cleanup(func() {
+		recoveryRoutines.Lock()
+		recoveryRoutines.routines = saved
+		recoveryRoutines.Unlock()
+	})
+
+	var ran []string
+	routine := func(name string, actions []string, err error) RecoveryFunc {
+		return func(ctx context.Context, recoverDB *Database) ([]string, error) {
+			assert.Equal(t, db, recoverDB)
+			ran = append(ran, name)
+			return actions, err
+		}
+	}
+	RegisterRecovery("journal", 2, routine("journal", []string{"rolled forward 1 write"}, nil))
+	RegisterRecovery("upgrade", 1, routine("upgrade", nil, nil))
+	RegisterRecovery("outbox", 2, routine("outbox", []string{"redelivered a", "redelivered b"}, nil))
+
+	report, err := Recover(ctx, db)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"upgrade", "journal", "outbox"}, ran)
+	assert.Equal(t, RecoveryReport{Actions: []RecoveryAction{
+		{Routine: "journal", Action: "rolled forward 1 write"},
+		{Routine: "outbox", Action: "redelivered a"},
+		{Routine: "outbox", Action: "redelivered b"},
+	}}, report)
+
+	failure := errors.New("failed")
+	RegisterRecovery("broken", 1, routine("broken", []string{"partial"}, failure))
+	ran = nil
+	report, err = Recover(ctx, db)
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"upgrade", "broken"}, ran)
+	assert.Equal(t, []RecoveryAction{{Routine: "broken", Action: "partial"}}, report.Actions)
+}
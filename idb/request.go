@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"syscall/js"
 
 	"github.com/hack-pad/safejs"
@@ -18,18 +19,12 @@ var (
 	ErrCursorStopIter = errors.New("stop cursor iteration")
 )
 
-var (
-	jsIDBRequest safejs.Value
-	jsIDBIndex   safejs.Value
-)
+// jsOnceListener is the addEventListener options for a listener which is removed after it's invoked
+var jsOnceListener safejs.Value
 
 func init() {
 	var err error
-	jsIDBRequest, err = safejs.Global().Get("IDBRequest")
-	if err != nil {
-		panic(err)
-	}
-	jsIDBIndex, err = safejs.Global().Get("IDBIndex")
+	jsOnceListener, err = safejs.ValueOf(map[string]interface{}{"once": true})
 	if err != nil {
 		panic(err)
 	}
@@ -40,30 +35,49 @@ func init() {
 type Request struct {
 	txn       *Transaction
 	jsRequest safejs.Value
+	op        string       // the IndexedDB method which made the request, if known
+	key       safejs.Value // the key the request was made on, or undefined
 }
 
 func wrapRequest(txn *Transaction, jsRequest safejs.Value) *Request {
-	if isInstance, err := jsRequest.InstanceOf(jsIDBRequest); !isInstance || err != nil {
+	if isInstance, err := jsRequest.InstanceOf(jsIDBRequest()); !isInstance || err != nil {
 		panic("Invalid JS request type")
 	}
-	if txn == nil {
-		txn = (*Transaction)(nil)
-	}
 	return &Request{
 		txn:       txn,
 		jsRequest: jsRequest,
 	}
 }
 
+// wrapRequestOp wraps a request made in txn by the IndexedDB method op, tracking it for Transaction.PendingRequests, Stats, and diagnostics.
+func wrapRequestOp(txn *Transaction, op string, jsRequest safejs.Value) *Request {
+	txn.trackRequest(op, jsRequest)
+	req := wrapRequest(txn, jsRequest)
+	req.op = op
+	return req
+}
+
+// withKey records the key the request was made on, for annotating its errors.
+func (r *Request) withKey(key safejs.Value) *Request {
+	r.key = key
+	return r
+}
+
+// wrapWriteRequest is like wrapRequestOp for a request which writes to the database, also tracking its transaction's outcome for AwaitComplete.
+func wrapWriteRequest(txn *Transaction, op string, jsRequest safejs.Value) *Request {
+	txn.trackFinished()
+	return wrapRequestOp(txn, op, jsRequest)
+}
+
 // Source returns the source of the request, such as an Index or an ObjectStore. If no source exists (such as when calling Factory.Open), it returns nil for both.
 func (r *Request) Source() (objectStore *ObjectStore, index *Index, err error) {
 	jsSource, err := r.jsRequest.Get("source")
 	if err != nil {
 		return
 	}
-	if isInstance, _ := jsSource.InstanceOf(jsObjectStore); isInstance {
+	if isInstance, _ := jsSource.InstanceOf(jsIDBObjectStore()); isInstance {
 		objectStore = wrapObjectStore(r.txn, jsSource)
-	} else if isInstance, _ := jsSource.InstanceOf(jsIDBIndex); isInstance {
+	} else if isInstance, _ := jsSource.InstanceOf(jsIDBIndex()); isInstance {
 		index = wrapIndex(r.txn, jsSource)
 	}
 	return
@@ -75,12 +89,21 @@ func (r *Request) Result() (safejs.Value, error) {
 }
 
 // Err returns an error in the event of an unsuccessful request, indicating what went wrong.
+// Errors from requests made on object stores, indexes, and cursors are *OpErrors, describing the operation which failed.
 func (r *Request) Err() (err error) {
 	jsErr, err := r.jsRequest.Get("error")
 	if err != nil {
 		return err
 	}
-	return domExceptionAsError(jsErr)
+	err = domExceptionAsError(jsErr)
+	if err == nil || r.op == "" {
+		return err
+	}
+	source, sourceErr := r.jsRequest.Get("source")
+	if sourceErr != nil {
+		return err
+	}
+	return newOpError(r.op, source, r.key, err)
 }
 
 // AwaitCursor awaits the iterator cursor and returns the value.
@@ -102,22 +125,51 @@ func (r *Request) Await(ctx context.Context) (safejs.Value, error) {
 	resultCh := make(chan safejs.Value, 1)
 	errCh := make(chan error, 1)
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	err := r.Listen(ctx, func() {
+	var success, failed safejs.Func
+	var releaseOnce sync.Once
+	// once listeners only remove themselves, so also remove the listener which didn't fire
+	release := func() {
+		releaseOnce.Do(func() {
+			_, _ = r.jsRequest.Call(removeEventListener, "success", success)
+			_, _ = r.jsRequest.Call(removeEventListener, "error", failed)
+			success.Release()
+			failed.Release()
+		})
+	}
+	success, err := safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		defer release()
 		result, err := r.Result()
 		if err != nil {
 			errCh <- err
 		} else {
 			resultCh <- result
 		}
-	}, func() {
-		errCh <- r.Err()
+		return nil
+	})
+	if err != nil {
+		return safejs.Null(), err
+	}
+	failed, err = safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		defer release()
+		errCh <- r.txn.withStats(r.txn.withAbortCause(r.Err()))
+		return nil
 	})
 	if err != nil {
+		success.Release()
 		return safejs.Null(), err
 	}
+	for _, listener := range []struct {
+		eventName string
+		fn        safejs.Func
+	}{
+		{"success", success},
+		{"error", failed},
+	} {
+		if _, err := r.jsRequest.Call(addEventListener, listener.eventName, listener.fn, jsOnceListener); err != nil {
+			release()
+			return safejs.Null(), tryAsDOMException(err)
+		}
+	}
 
 	select {
 	case result := <-resultCh:
@@ -125,6 +177,7 @@ func (r *Request) Await(ctx context.Context) (safejs.Value, error) {
 	case err := <-errCh:
 		return safejs.Null(), err
 	case <-ctx.Done():
+		release()
 		return safejs.Null(), ctx.Err()
 	}
 }
@@ -263,68 +316,30 @@ func ignorePanic(fn func()) {
 
 // UintRequest is a Request that retrieves a uint result
 type UintRequest struct {
-	*Request
+	*TypedRequest[uint]
 }
 
 func newUintRequest(req *Request) *UintRequest {
-	return &UintRequest{req}
-}
-
-// Result returns the result of the request. If the request failed and the result is not available, an error is returned.
-func (u *UintRequest) Result() (uint, error) {
-	result, err := u.Request.Result()
-	if err != nil {
-		return 0, err
-	}
-	value, err := result.Int()
-	return uint(value), err
-}
-
-// Await waits for success or failure, then returns the results.
-func (u *UintRequest) Await(ctx context.Context) (uint, error) {
-	result, err := u.Request.Await(ctx)
-	if err != nil {
-		return 0, err
-	}
-	value, err := result.Int()
-	return uint(value), err
+	return &UintRequest{RequestOf(req, func(result safejs.Value) (uint, error) {
+		value, err := result.Int()
+		return uint(value), err
+	})}
 }
 
 // ArrayRequest is a Request that retrieves an array of js.Values
 type ArrayRequest struct {
-	*Request
+	*TypedRequest[[]safejs.Value]
 }
 
 func newArrayRequest(req *Request) *ArrayRequest {
-	return &ArrayRequest{req}
-}
-
-// Result returns the result of the request. If the request failed and the result is not available, an error is returned.
-func (a *ArrayRequest) Result() ([]safejs.Value, error) {
-	result, err := a.Request.Result()
-	if err != nil {
-		return nil, err
-	}
-	var values []safejs.Value
-	err = iterArray(result, func(i int, value safejs.Value) (bool, error) {
-		values = append(values, value)
-		return true, nil
-	})
-	return values, err
-}
-
-// Await waits for success or failure, then returns the results.
-func (a *ArrayRequest) Await(ctx context.Context) ([]safejs.Value, error) {
-	result, err := a.Request.Await(ctx)
-	if err != nil {
-		return nil, err
-	}
-	var values []safejs.Value
-	err = iterArray(result, func(i int, value safejs.Value) (bool, error) {
-		values = append(values, value)
-		return true, nil
-	})
-	return values, err
+	return &ArrayRequest{RequestOf(req, func(result safejs.Value) ([]safejs.Value, error) {
+		var values []safejs.Value
+		err := iterArray(result, func(i int, value safejs.Value) (bool, error) {
+			values = append(values, value)
+			return true, nil
+		})
+		return values, err
+	})}
 }
 
 // AckRequest is a Request that doesn't retrieve a value, only used to detect errors.
@@ -406,10 +421,24 @@ func (c *CursorRequest) Unwrap() safejs.Value {
 // CursorWithValueRequest is a Request that retrieves a CursorWithValue
 type CursorWithValueRequest struct {
 	*Request
+	batch *cursorBatch // nil unless IterBatch can read the cursor's records with getAll
+}
+
+// cursorBatch is the range and direction of a cursor, for reading its records in batches.
+type cursorBatch struct {
+	keyRange  *KeyRange // nil for all keys
+	direction CursorDirection
 }
 
 func newCursorWithValueRequest(req *Request) *CursorWithValueRequest {
-	return &CursorWithValueRequest{req}
+	return &CursorWithValueRequest{Request: req}
+}
+
+func newBatchableCursorWithValueRequest(req *Request, keyRange *KeyRange, direction CursorDirection) *CursorWithValueRequest {
+	return &CursorWithValueRequest{
+		Request: req,
+		batch:   &cursorBatch{keyRange: keyRange, direction: direction},
+	}
 }
 
 // Iter invokes the callback when the request succeeds for each cursor iteration
@@ -419,6 +448,46 @@ func (c *CursorWithValueRequest) Iter(ctx context.Context, iter func(*CursorWith
 	})
 }
 
+// IterBatch is like Iter, but prefetches up to batchSize records at a time with getAll requests over the rest of the cursor's range, instead of waiting on one cursor step per record.
+// iter is called with each record's primary key and value. Return ErrCursorStopIter to stop early.
+//
+// Only ascending cursors from OpenCursor or OpenCursorRange are read in batches. Other cursors step through records one at a time, like Iter.
+func (c *CursorWithValueRequest) IterBatch(ctx context.Context, batchSize uint, iter func(primaryKey, value safejs.Value) error) error {
+	objectStore, index, err := c.Request.Source()
+	if err != nil {
+		return err
+	}
+	var source *baseObjectStore
+	switch {
+	case c.batch == nil:
+	case objectStore != nil && (c.batch.direction == CursorNext || c.batch.direction == CursorNextUnique):
+		source = objectStore.base
+	case index != nil && c.batch.direction == CursorNext:
+		source = index.base
+	}
+	if source == nil {
+		return c.Iter(ctx, func(cursor *CursorWithValue) error {
+			primaryKey, err := cursor.PrimaryKey()
+			if err != nil {
+				return err
+			}
+			value, err := cursor.Value()
+			if err != nil {
+				return err
+			}
+			return iter(primaryKey, value)
+		})
+	}
+	return source.batchScan(ctx, c.batch.keyRange, batchSize, index != nil, func(primaryKeys, values []safejs.Value) error {
+		for i := range primaryKeys {
+			if err := iter(primaryKeys[i], values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // Result returns the result of the request. If the request failed and the result is not available, an error is returned.
 func (c *CursorWithValueRequest) Result() (*CursorWithValue, error) {
 	result, err := c.Request.Result()
@@ -0,0 +1,88 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+)
+
+// RequestGroup makes several requests in a transaction and waits for all of them, like an errgroup for requests.
+// The first failure aborts the transaction, so none of the group's writes are committed.
+type RequestGroup struct {
+	txn  *Transaction
+	reqs []*Request
+	errs []error
+}
+
+// NewRequestGroup returns an empty RequestGroup for requests made in txn.
+func NewRequestGroup(txn *Transaction) *RequestGroup {
+	return &RequestGroup{txn: txn}
+}
+
+// Go makes a request with fn and adds it to the group. fn runs immediately, since requests can only be made while the transaction is active.
+// If fn returns an error, Wait aborts the transaction and returns it.
+func (g *RequestGroup) Go(fn func() (*Request, error)) {
+	req, err := fn()
+	if err != nil {
+		g.errs = append(g.errs, err)
+		return
+	}
+	g.reqs = append(g.reqs, req)
+}
+
+// Wait waits for every request in the group to finish, then returns the errors of those which failed, joined together.
+// Aborts the transaction on the first failure. Requests which fail only because of the abort aren't included in the returned error.
+// Like AwaitAll, call Wait before yielding to the event loop after making the requests.
+func (g *RequestGroup) Wait(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := g.errs
+	aborted := false
+	abort := func() {
+		if !aborted {
+			aborted = true
+			_ = g.txn.Abort()
+		}
+	}
+	if len(errs) > 0 {
+		abort()
+	}
+
+	finished := make(chan error, len(g.reqs))
+	for _, req := range g.reqs {
+		req := req
+		err := req.Listen(ctx, func() {
+			finished <- nil
+		}, func() {
+			err := req.Err()
+			if err == nil {
+				err = errors.New("request failed")
+			}
+			finished <- err
+		})
+		if err != nil {
+			abort()
+			return errors.Join(append(errs, err)...)
+		}
+	}
+
+	for range g.reqs {
+		select {
+		case err := <-finished:
+			if err == nil {
+				continue
+			}
+			if aborted && errors.Is(err, ErrAbort) {
+				continue
+			}
+			errs = append(errs, err)
+			abort()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestRequestGroup(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	count := func() uint {
+		txn, err := db.Transaction(TransactionReadOnly, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		req, err := store.Count()
+		assert.NoError(t, err)
+		n, err := req.Await(ctx)
+		assert.NoError(t, err)
+		return n
+	}
+
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	group := NewRequestGroup(txn)
+	for i := 0; i < 3; i++ {
+		i := i
+		group.Go(func() (*Request, error) {
+			return store.PutKeyValue(i, i)
+		})
+	}
+	assert.NoError(t, group.Wait(ctx))
+	assert.NoError(t, txn.Await(ctx))
+	assert.Equal(t, uint(3), count())
+
+	// a failed request aborts the transaction, undoing the other writes
+	txn, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	group = NewRequestGroup(txn)
+	group.Go(func() (*Request, error) {
+		return store.PutKeyValue(10, 10)
+	})
+	group.Go(func() (*Request, error) {
+		req, err := store.AddKeyValue(0, "duplicate")
+		if err != nil {
+			return nil, err
+		}
+		return req.Request, nil
+	})
+	group.Go(func() (*Request, error) {
+		return store.PutKeyValue(11, 11)
+	})
+	err = group.Wait(ctx)
+	assert.ErrorIs(t, err, NewDOMException("ConstraintError"))
+	assert.Equal(t, uint(3), count())
+
+	// an error making a request aborts the transaction too
+	txn, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	group = NewRequestGroup(txn)
+	group.Go(func() (*Request, error) {
+		return store.PutKeyValue(20, 20)
+	})
+	failure := errors.New("failed")
+	group.Go(func() (*Request, error) {
+		return nil, failure
+	})
+	assert.ErrorIs(t, group.Wait(ctx), failure)
+	assert.Equal(t, uint(3), count())
+}
@@ -108,3 +108,47 @@ func TestListen(t *testing.T) {
 		return atomic.LoadInt64(&successCount) > 0
 	}, time.Second, 50*time.Millisecond)
 }
+
+func TestCursorWithValueRequestIterBatch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, index := someKeyStore(t)
+	keyRange, err := NewKeyRangeLowerBoundOf("some id 2", false)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		open    func() (*CursorWithValueRequest, error)
+		stopAt  int
+		expect  []string
+		batched bool
+	}{
+		{name: "store", open: func() (*CursorWithValueRequest, error) { return store.OpenCursor(CursorNext) }, expect: []string{"some id 1", "some id 2", "some id 3", "some id 4", "some id 5"}, batched: true},
+		{name: "store range", open: func() (*CursorWithValueRequest, error) { return store.OpenCursorRange(keyRange, CursorNext) }, expect: []string{"some id 2", "some id 3", "some id 4", "some id 5"}, batched: true},
+		{name: "index stop", open: func() (*CursorWithValueRequest, error) { return index.OpenCursor(CursorNext) }, stopAt: 3, expect: []string{"some id 1", "some id 2", "some id 3"}, batched: true},
+		{name: "previous", open: func() (*CursorWithValueRequest, error) { return store.OpenCursor(CursorPrevious) }, expect: []string{"some id 5", "some id 4", "some id 3", "some id 2", "some id 1"}},
+		{name: "key", open: func() (*CursorWithValueRequest, error) {
+			return store.OpenCursorKey(safejs.Safe(js.ValueOf("some id 3")), CursorNext)
+		}, expect: []string{"some id 3"}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := tc.open()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.batched, req.batch != nil && req.batch.direction == CursorNext)
+			var keys []string
+			assert.NoError(t, req.IterBatch(ctx, 2, func(primaryKey, value safejs.Value) error {
+				key, err := primaryKey.String()
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+				if len(keys) == tc.stopAt {
+					return ErrCursorStopIter
+				}
+				return nil
+			}))
+			assert.Equal(t, tc.expect, keys)
+		})
+	}
+}
@@ -5,7 +5,10 @@ package idb
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 )
 
 /*
@@ -31,46 +34,149 @@ func RetryTxn(
 	objectStoreName string,
 	objectStoreNames ...string,
 ) error {
-	for {
-		txn, err := db.Transaction(txnMode, objectStoreName, objectStoreNames...)
-		if err != nil {
-			if IsTxnFinishedErr(err) {
-				continue
-			}
-			return err
+	return RetryTxnWithOptions(ctx, db, txnMode, RetryOptions{}, fn, objectStoreName, objectStoreNames...)
+}
+
+// RetryTxnValue is like RetryTxn, but returns the result of fn's successful attempt.
+func RetryTxnValue[T any](
+	ctx context.Context,
+	db *Database,
+	txnMode TransactionMode,
+	fn func(txn *Transaction) (T, error),
+	objectStoreName string,
+	objectStoreNames ...string,
+) (T, error) {
+	var result T
+	err := RetryTxn(ctx, db, txnMode, func(txn *Transaction) error {
+		value, err := fn(txn)
+		if err == nil {
+			result = value
 		}
+		return err
+	}, objectStoreName, objectStoreNames...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
 
-		// call the fn
-		err = fn(txn)
+// ErrRetriesExhausted is returned by RetryTxnWithOptions when every one of RetryOptions.MaxAttempts attempts failed with a retryable error.
+var ErrRetriesExhausted = errors.New("transaction retries exhausted")
 
-		// if the fn returns txn finished, retry.
-		if IsTxnFinishedErr(err) {
-			continue
-		}
+// RetryOptions contains options for RetryTxnWithOptions.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of times fn is called. Zero means no limit.
+	MaxAttempts int
+	// Backoff returns how long to wait before the given attempt, starting with attempt 2 for the first retry. If nil, retries start right away.
+	Backoff func(attempt int) time.Duration
+	// OnRetry is called before waiting for each retry, with the attempt about to start and the error which failed the previous one. Use it to log or count retries.
+	OnRetry func(attempt int, err error)
+	// Retryable reports whether an attempt which failed with err should be retried. Defaults to IsTxnFinishedErr.
+	// Extend it to retry other transient errors, like an AbortError from a race with another tab. Attempts which fail with a retryable error are aborted before retrying.
+	Retryable func(err error) bool
+}
 
-		// check for error performing the operation
-		if err != nil {
-			_ = txn.Abort()
+// RetryTxnWithOptions is like RetryTxn, but retries are limited, delayed, and observed as configured by options.
+// It stops retrying once ctx is done, returning ctx.Err().
+// If the last of options.MaxAttempts attempts fails with a retryable error, returns an error wrapping ErrRetriesExhausted and the attempt's error.
+func RetryTxnWithOptions(
+	ctx context.Context,
+	db *Database,
+	txnMode TransactionMode,
+	options RetryOptions,
+	fn func(txn *Transaction) error,
+	objectStoreName string,
+	objectStoreNames ...string,
+) error {
+	retryable := options.Retryable
+	if retryable == nil {
+		retryable = IsTxnFinishedErr
+	}
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
 			return err
 		}
-
-		// commit the txn
-		err = txn.Commit()
-		if IsTxnFinishedErr(err) {
-			// txn committed automatically already
-			err = nil
+		err := retryTxnAttempt(db, txnMode, fn, objectStoreName, objectStoreNames...)
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if options.MaxAttempts > 0 && attempt >= options.MaxAttempts {
+			return fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempt, err)
 		}
+		if options.OnRetry != nil {
+			options.OnRetry(attempt+1, err)
+		}
+		if err := waitBackoff(ctx, options.Backoff, attempt+1); err != nil {
+			return err
+		}
+	}
+}
 
+// retryTxnAttempt runs fn once in a new transaction, then commits it. The transaction is aborted if fn fails.
+func retryTxnAttempt(
+	db *Database,
+	txnMode TransactionMode,
+	fn func(txn *Transaction) error,
+	objectStoreName string,
+	objectStoreNames ...string,
+) error {
+	txn, err := db.Transaction(txnMode, objectStoreName, objectStoreNames...)
+	if err != nil {
+		return err
+	}
+
+	// call the fn
+	err = fn(txn)
+
+	// check for error performing the operation. aborting a finished txn is a no-op
+	if err != nil {
+		_ = txn.Abort()
 		return err
 	}
+
+	// commit the txn
+	err = txn.Commit()
+	if IsTxnFinishedErr(err) {
+		// txn committed automatically already
+		err = nil
+	}
+
+	return err
+}
+
+// waitBackoff waits for the delay before attempt, if any, or until ctx is done.
+func waitBackoff(ctx context.Context, backoff func(attempt int) time.Duration, attempt int) error {
+	var delay time.Duration
+	if backoff != nil {
+		delay = backoff(attempt)
+	}
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // IsTxnFinishedErr checks if an error corresponds to a transaction finishing.
 // see RetryTxn for details
+//
+// Errors are classified by DOMException name, so localized or reworded browser messages still match: a TransactionInactiveError, or an InvalidStateError from starting a transaction, getting an object store, committing, or aborting.
+// Other InvalidStateErrors, like from a cursor which is already iterating, don't match by name.
+// As a fallback, like for errors from other wrappers, errors match by the English messages Chrome uses.
 func IsTxnFinishedErr(err error) bool {
+	var finished txnFinishedError
 	switch {
 	case err == nil:
 		return false
+	case errors.Is(err, ErrTransactionInactive), errors.As(err, &finished):
+		return true
 	case strings.HasSuffix(err.Error(), "The transaction has finished."):
 		return true
 	case strings.HasSuffix(err.Error(), "The database connection is closing."):
@@ -79,3 +185,24 @@ func IsTxnFinishedErr(err error) bool {
 		return false
 	}
 }
+
+// txnFinishedError marks an InvalidStateError thrown because its transaction or database connection finished, as opposed to other invalid states.
+type txnFinishedError struct {
+	err error
+}
+
+// asTxnFinished marks err as a txnFinishedError if it's an InvalidStateError. Only use it for calls which are in an invalid state only once their transaction or connection finished.
+func asTxnFinished(err error) error {
+	if errors.Is(err, ErrInvalidState) {
+		return txnFinishedError{err: err}
+	}
+	return err
+}
+
+func (e txnFinishedError) Error() string {
+	return e.err.Error()
+}
+
+func (e txnFinishedError) Unwrap() error {
+	return e.err
+}
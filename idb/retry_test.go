@@ -6,8 +6,10 @@ package idb
 import (
 	"context"
 	"errors"
+	"fmt"
 	"syscall/js"
 	"testing"
+	"time"
 
 	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
 	"github.com/hack-pad/safejs"
@@ -64,9 +66,161 @@ func TestRetryTxn(t *testing.T) {
 	})
 }
 
+func TestRetryTxnValue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	const storeName = "mystore"
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore(storeName, ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = store.PutKey(safejs.Safe(js.ValueOf("key")), safejs.Safe(js.ValueOf("some value")))
+		assert.NoError(t, err)
+	})
+
+	var callCount int
+	value, err := RetryTxnValue(ctx, db, TransactionReadOnly, func(txn *Transaction) (string, error) {
+		callCount++
+		if callCount == 1 {
+			return "partial", errors.New("The transaction has finished.")
+		}
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return "", err
+		}
+		req, err := store.Get(safejs.Safe(js.ValueOf("key")))
+		if err != nil {
+			return "", err
+		}
+		result, err := req.Await(ctx)
+		if err != nil {
+			return "", err
+		}
+		return result.String()
+	}, storeName)
+	assert.NoError(t, err)
+	assert.Equal(t, "some value", value)
+	assert.Equal(t, 2, callCount)
+
+	value, err = RetryTxnValue(ctx, db, TransactionReadOnly, func(txn *Transaction) (string, error) {
+		return "partial", errors.New("some error")
+	}, storeName)
+	assert.Error(t, err)
+	assert.Equal(t, "", value)
+}
+
+func TestRetryTxnWithOptions(t *testing.T) {
+	t.Parallel()
+
+	const storeName = "mystore"
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore(storeName, ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	errFinished := errors.New("The transaction has finished.")
+
+	t.Run("max attempts", func(t *testing.T) {
+		t.Parallel()
+		var callCount int
+		var retries, backoffs []int
+		err := RetryTxnWithOptions(context.Background(), db, TransactionReadOnly, RetryOptions{
+			MaxAttempts: 3,
+			Backoff: func(attempt int) time.Duration {
+				backoffs = append(backoffs, attempt)
+				return time.Millisecond
+			},
+			OnRetry: func(attempt int, err error) {
+				assert.Equal(t, errFinished, err)
+				retries = append(retries, attempt)
+			},
+		}, func(txn *Transaction) error {
+			callCount++
+			return errFinished
+		}, storeName)
+		assert.ErrorIs(t, err, ErrRetriesExhausted)
+		assert.ErrorIs(t, err, errFinished)
+		assert.Equal(t, 3, callCount)
+		assert.Equal(t, []int{2, 3}, retries)
+		assert.Equal(t, []int{2, 3}, backoffs)
+	})
+
+	t.Run("retryable", func(t *testing.T) {
+		t.Parallel()
+		errTransient := errors.New("transient")
+		var callCount int
+		err := RetryTxnWithOptions(context.Background(), db, TransactionReadWrite, RetryOptions{
+			Retryable: func(err error) bool {
+				return errors.Is(err, errTransient) || IsTxnFinishedErr(err)
+			},
+		}, func(txn *Transaction) error {
+			callCount++
+			store, err := txn.ObjectStore(storeName)
+			if err != nil {
+				return err
+			}
+			_, err = store.PutKey(safejs.Safe(js.ValueOf("retryable")), safejs.Safe(js.ValueOf(callCount)))
+			if err != nil {
+				return err
+			}
+			if callCount == 1 {
+				return errTransient
+			}
+			return nil
+		}, storeName)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, callCount)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var callCount int
+		err := RetryTxnWithOptions(ctx, db, TransactionReadOnly, RetryOptions{
+			Backoff: func(int) time.Duration { return time.Hour },
+			OnRetry: func(int, error) { cancel() },
+		}, func(txn *Transaction) error {
+			callCount++
+			return errFinished
+		}, storeName)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, callCount)
+	})
+
+	t.Run("canceled before attempting", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var callCount int
+		err := RetryTxnWithOptions(ctx, db, TransactionReadOnly, RetryOptions{}, func(txn *Transaction) error {
+			callCount++
+			return nil
+		}, storeName)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, callCount)
+	})
+}
+
 func TestIsTxnFinishedErr(t *testing.T) {
 	t.Parallel()
 	assert.Equal(t, false, IsTxnFinishedErr(nil))
 	assert.Equal(t, false, IsTxnFinishedErr(errors.New("some error")))
 	assert.Equal(t, true, IsTxnFinishedErr(errors.New("The transaction has finished.")))
+
+	// classified by name, whatever the message
+	assert.Equal(t, true, IsTxnFinishedErr(fmt.Errorf("put: %w", DOMException{name: "TransactionInactiveError", message: "La transaction n'est pas active."})))
+	assert.Equal(t, true, IsTxnFinishedErr(asTxnFinished(DOMException{name: "InvalidStateError", message: "Die Transaktion ist beendet."})))
+	assert.Equal(t, false, IsTxnFinishedErr(DOMException{name: "InvalidStateError", message: "The cursor is being iterated."}))
+	assert.Equal(t, false, IsTxnFinishedErr(asTxnFinished(DOMException{name: "DataError"})))
+
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(context.Background()))
+	_, err = txn.ObjectStore("mystore")
+	assert.ErrorIs(t, err, ErrInvalidState)
+	assert.Equal(t, true, IsTxnFinishedErr(err))
 }
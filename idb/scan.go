@@ -0,0 +1,166 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+
+	"github.com/hack-pad/safejs"
+)
+
+const defaultScanChunkSize = 1000
+
+// chunkedScan iterates over the records of an object store or index, processing at most chunkSize records per transaction.
+//
+// Each chunk resumes just after the last record processed successfully, including when a transaction finishes prematurely.
+type chunkedScan struct {
+	db          *Database
+	storeName   string
+	extraStores []string // additional object stores in each transaction's scope
+	indexName   string
+	keyRange    *KeyRange
+	direction   CursorDirection
+	mode        TransactionMode
+	chunkSize   uint
+	chunkDone   func() error // optional, called after each chunk's transaction completes
+}
+
+// run calls fn for each record with the chunk's transaction. Return ErrCursorStopIter from fn to stop early.
+func (s chunkedScan) run(ctx context.Context, fn func(txn *Transaction, cursor *CursorWithValue) error) error {
+	chunkSize := s.chunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultScanChunkSize
+	}
+	unique := s.direction == CursorNextUnique || s.direction == CursorPreviousUnique
+	// index keys may repeat, so resume on the last key and skip ahead with its primary key
+	skipByPrimaryKey := s.indexName != "" && !unique
+
+	var lastKey, lastPrimaryKey safejs.Value
+	started := false
+	sizer := newBatchSizer(chunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		chunkLimit := sizer.next()
+		keyRange := s.keyRange
+		if started {
+			var more bool
+			var err error
+			keyRange, more, err = ResumeKeyRange(s.keyRange, lastKey, !skipByPrimaryKey, s.direction)
+			if err != nil || !more {
+				return err
+			}
+		}
+
+		txn, err := s.db.Transaction(s.mode, s.storeName, s.extraStores...)
+		if err != nil {
+			return err
+		}
+		req, err := s.openCursor(txn, keyRange)
+		if err != nil {
+			if IsTxnFinishedErr(err) {
+				continue
+			}
+			return err
+		}
+
+		var processed uint
+		chunkFull := false
+		err = req.Iter(ctx, func(cursor *CursorWithValue) error {
+			key, err := cursor.Key()
+			if err != nil {
+				return err
+			}
+			primaryKey, err := cursor.PrimaryKey()
+			if err != nil {
+				return err
+			}
+			if started && skipByPrimaryKey {
+				cmp, err := s.comparePosition(key, primaryKey, lastKey, lastPrimaryKey)
+				if err != nil {
+					return err
+				}
+				switch {
+				case cmp == 0:
+					return cursor.Continue()
+				case cmp < 0:
+					return cursor.ContinuePrimaryKey(lastKey, lastPrimaryKey)
+				}
+			}
+
+			if err := fn(txn, cursor); err != nil {
+				return err
+			}
+			lastKey, lastPrimaryKey, started = key, primaryKey, true
+			processed++
+			if processed >= chunkLimit {
+				chunkFull = true
+				return ErrCursorStopIter
+			}
+			return nil
+		})
+		if IsTxnFinishedErr(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = txn.Commit()
+		switch {
+		case err == nil && s.chunkDone != nil:
+			if err := txn.Await(ctx); err != nil {
+				return err
+			}
+			if err := s.chunkDone(); err != nil {
+				return err
+			}
+		case err != nil && !IsTxnFinishedErr(err):
+			return err
+		}
+		if !chunkFull {
+			return nil
+		}
+	}
+}
+
+func (s chunkedScan) openCursor(txn *Transaction, keyRange *KeyRange) (*CursorWithValueRequest, error) {
+	store, err := txn.ObjectStore(s.storeName)
+	if err != nil {
+		return nil, err
+	}
+	if s.indexName == "" {
+		if keyRange == nil {
+			return store.OpenCursor(s.direction)
+		}
+		return store.OpenCursorRange(keyRange, s.direction)
+	}
+	index, err := store.Index(s.indexName)
+	if err != nil {
+		return nil, err
+	}
+	if keyRange == nil {
+		return index.OpenCursor(s.direction)
+	}
+	return index.OpenCursorRange(keyRange, s.direction)
+}
+
+// comparePosition compares the index record at key and primaryKey to the last processed record, in iteration order.
+// Returns a negative number if the record comes first, 0 if they are the same record, or a positive number if the record comes after it.
+func (s chunkedScan) comparePosition(key, primaryKey, lastKey, lastPrimaryKey safejs.Value) (int, error) {
+	cmp, err := compareKeys(key, lastKey)
+	if err != nil {
+		return 0, err
+	}
+	if cmp == 0 {
+		cmp, err = compareKeys(primaryKey, lastPrimaryKey)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if s.direction == CursorPrevious {
+		cmp = -cmp
+	}
+	return cmp, nil
+}
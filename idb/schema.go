@@ -0,0 +1,352 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrSchemaIncompatible is returned by Factory.OpenSchema when an existing object store's key path or auto-increment option differs from the schema. Those can't change without recreating the store and losing its records.
+var ErrSchemaIncompatible = errors.New("object store options differ from the schema")
+
+// Schema declares the object stores of a database and their indexes.
+type Schema struct {
+	Stores []StoreSchema
+}
+
+// String describes the schema with one line per object store and index, like:
+//
+//	store "users" key path "id" auto increment
+//	  index "email" key path "email" unique
+func (s Schema) String() string {
+	var sb strings.Builder
+	for _, store := range s.Stores {
+		fmt.Fprintf(&sb, "store %q", store.Name)
+		if !store.Options.KeyPath.IsZero() {
+			fmt.Fprintf(&sb, " key path %s", store.Options.KeyPath)
+		}
+		if store.Options.AutoIncrement {
+			sb.WriteString(" auto increment")
+		}
+		sb.WriteByte('\n')
+		for _, index := range store.Indexes {
+			fmt.Fprintf(&sb, "  index %q key path %s", index.Name, index.KeyPath)
+			if index.Options.Unique {
+				sb.WriteString(" unique")
+			}
+			if index.Options.MultiEntry {
+				sb.WriteString(" multi entry")
+			}
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// StoreSchema declares an object store and its indexes.
+type StoreSchema struct {
+	Name    string
+	Options ObjectStoreOptions
+	Indexes []IndexSchema
+}
+
+// IndexSchema declares an index.
+type IndexSchema struct {
+	Name    string
+	KeyPath KeyPath
+	Options IndexOptions
+}
+
+// Validate returns an error if the schema has duplicate names or options which can't be used to create its object stores or indexes.
+func (s Schema) Validate() error {
+	storeNames := make(map[string]bool, len(s.Stores))
+	for _, store := range s.Stores {
+		if storeNames[store.Name] {
+			return fmt.Errorf("duplicate object store %q", store.Name)
+		}
+		storeNames[store.Name] = true
+		if err := store.Options.Validate(); err != nil {
+			return fmt.Errorf("object store %q: %w", store.Name, err)
+		}
+		indexNames := make(map[string]bool, len(store.Indexes))
+		for _, index := range store.Indexes {
+			if indexNames[index.Name] {
+				return fmt.Errorf("object store %q: duplicate index %q", store.Name, index.Name)
+			}
+			indexNames[index.Name] = true
+			if err := index.KeyPath.Validate(); err != nil {
+				return fmt.Errorf("object store %q: index %q: %w", store.Name, index.Name, err)
+			}
+			if err := index.Options.Validate(index.KeyPath); err != nil {
+				return fmt.Errorf("object store %q: index %q: %w", store.Name, index.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Schema returns the object stores of the database, their key paths and auto-increment options, and their indexes, sorted by name.
+// Use it to debug, plan migrations, or compare against a snapshot in tests.
+func (db *Database) Schema(ctx context.Context) (Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return Schema{}, err
+	}
+	storeNames, err := db.ObjectStoreNames()
+	if err != nil || len(storeNames) == 0 {
+		return Schema{}, err
+	}
+	txn, err := db.Transaction(TransactionReadOnly, storeNames[0], storeNames[1:]...)
+	if err != nil {
+		return Schema{}, err
+	}
+	return readSchema(txn, storeNames)
+}
+
+func readSchema(txn *Transaction, storeNames []string) (Schema, error) {
+	var schema Schema
+	for _, storeName := range storeNames {
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return Schema{}, err
+		}
+		keyPath, err := store.TypedKeyPath()
+		if err != nil {
+			return Schema{}, err
+		}
+		autoIncrement, err := store.AutoIncrement()
+		if err != nil {
+			return Schema{}, err
+		}
+		storeSchema := StoreSchema{Name: storeName, Options: ObjectStoreOptions{KeyPath: keyPath, AutoIncrement: autoIncrement}}
+		indexNames, err := store.IndexNames()
+		if err != nil {
+			return Schema{}, err
+		}
+		for _, indexName := range indexNames {
+			index, err := store.Index(indexName)
+			if err != nil {
+				return Schema{}, err
+			}
+			indexSchema, err := readIndexSchema(index)
+			if err != nil {
+				return Schema{}, err
+			}
+			storeSchema.Indexes = append(storeSchema.Indexes, indexSchema)
+		}
+		schema.Stores = append(schema.Stores, storeSchema)
+	}
+	return schema, nil
+}
+
+func readIndexSchema(index *Index) (IndexSchema, error) {
+	name, err := index.Name()
+	if err != nil {
+		return IndexSchema{}, err
+	}
+	keyPath, err := index.TypedKeyPath()
+	if err != nil {
+		return IndexSchema{}, err
+	}
+	unique, err := index.Unique()
+	if err != nil {
+		return IndexSchema{}, err
+	}
+	multiEntry, err := index.MultiEntry()
+	if err != nil {
+		return IndexSchema{}, err
+	}
+	return IndexSchema{Name: name, KeyPath: keyPath, Options: IndexOptions{Unique: unique, MultiEntry: multiEntry}}, nil
+}
+
+// schemaDiff lists the changes upgrading a database to a schema.
+type schemaDiff struct {
+	deleteStores  []string
+	createStores  []StoreSchema
+	deleteIndexes map[string][]string      // by object store name
+	createIndexes map[string][]IndexSchema // by object store name
+}
+
+func (d schemaDiff) empty() bool {
+	return len(d.deleteStores) == 0 && len(d.createStores) == 0 && len(d.deleteIndexes) == 0 && len(d.createIndexes) == 0
+}
+
+// diffSchema returns the changes upgrading a database with the current schema to the declared one. Indexes whose key path or options changed are deleted and created again.
+func diffSchema(current, declared Schema) (schemaDiff, error) {
+	diff := schemaDiff{deleteIndexes: make(map[string][]string), createIndexes: make(map[string][]IndexSchema)}
+	currentStores := make(map[string]StoreSchema, len(current.Stores))
+	for _, store := range current.Stores {
+		currentStores[store.Name] = store
+	}
+	declaredStores := make(map[string]bool, len(declared.Stores))
+	for _, store := range declared.Stores {
+		declaredStores[store.Name] = true
+		currentStore, exists := currentStores[store.Name]
+		if !exists {
+			diff.createStores = append(diff.createStores, store)
+			continue
+		}
+		if !keyPathsEqual(currentStore.Options.KeyPath, store.Options.KeyPath) || currentStore.Options.AutoIncrement != store.Options.AutoIncrement {
+			return schemaDiff{}, fmt.Errorf("%w: %q", ErrSchemaIncompatible, store.Name)
+		}
+
+		currentIndexes := make(map[string]IndexSchema, len(currentStore.Indexes))
+		for _, index := range currentStore.Indexes {
+			currentIndexes[index.Name] = index
+		}
+		declaredIndexes := make(map[string]bool, len(store.Indexes))
+		for _, index := range store.Indexes {
+			declaredIndexes[index.Name] = true
+			currentIndex, exists := currentIndexes[index.Name]
+			if exists && keyPathsEqual(currentIndex.KeyPath, index.KeyPath) && currentIndex.Options == index.Options {
+				continue
+			}
+			if exists {
+				diff.deleteIndexes[store.Name] = append(diff.deleteIndexes[store.Name], index.Name)
+			}
+			diff.createIndexes[store.Name] = append(diff.createIndexes[store.Name], index)
+		}
+		for _, index := range currentStore.Indexes {
+			if !declaredIndexes[index.Name] {
+				diff.deleteIndexes[store.Name] = append(diff.deleteIndexes[store.Name], index.Name)
+			}
+		}
+	}
+	for _, store := range current.Stores {
+		if !declaredStores[store.Name] {
+			diff.deleteStores = append(diff.deleteStores, store.Name)
+		}
+	}
+	return diff, nil
+}
+
+func keyPathsEqual(a, b KeyPath) bool {
+	return a.Kind() == b.Kind() && reflect.DeepEqual(a.Paths(), b.Paths())
+}
+
+// apply makes the changes during an upgrade.
+func (d schemaDiff) apply(upgrade Upgrade) error {
+	for _, storeName := range d.deleteStores {
+		if err := upgrade.Database.DeleteObjectStore(storeName); err != nil {
+			return err
+		}
+	}
+	for _, storeSchema := range d.createStores {
+		store, err := upgrade.Database.CreateObjectStore(storeSchema.Name, storeSchema.Options)
+		if err != nil {
+			return err
+		}
+		for _, index := range storeSchema.Indexes {
+			if _, err := store.CreateIndex(index.Name, index.KeyPath, index.Options); err != nil {
+				return err
+			}
+		}
+	}
+	for storeName, indexNames := range d.deleteIndexes {
+		store, err := upgrade.Transaction.ObjectStore(storeName)
+		if err != nil {
+			return err
+		}
+		for _, indexName := range indexNames {
+			if err := store.DeleteIndex(indexName); err != nil {
+				return err
+			}
+		}
+	}
+	for storeName, indexes := range d.createIndexes {
+		store, err := upgrade.Transaction.ObjectStore(storeName)
+		if err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			if _, err := store.CreateIndex(index.Name, index.KeyPath, index.Options); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// OpenSchema opens a connection to a database, upgrading it to match schema if it doesn't already.
+// Upgrades bump the version by one, create missing object stores and indexes, delete object stores and indexes missing from schema, and recreate indexes whose key path or options changed.
+// Deleting an object store deletes its records, so keep every store in use in the schema.
+//
+// Returns an error wrapping ErrSchemaIncompatible if an existing object store's key path or auto-increment option differs from schema. Migrate those by hand with a new object store.
+func (f *Factory) OpenSchema(ctx context.Context, name string, schema Schema) (*Database, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	var version uint
+	db, err := f.OpenCurrent(ctx, name)
+	switch {
+	case errors.Is(err, ErrDatabaseNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		version, err = checkSchema(ctx, db, schema)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		if version == 0 {
+			return db, nil
+		}
+		if err := db.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	var upgradeErr error
+	req, err := f.OpenWithOptions(ctx, name, OpenOptions{
+		Version: version + 1,
+		OnUpgrade: func(upgrade Upgrade) error {
+			// diff again, in case another connection upgraded the database since it was checked
+			upgradeErr = upgradeSchema(upgrade, schema)
+			if upgradeErr != nil {
+				_ = upgrade.Transaction.Abort()
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	db, err = req.Await(ctx)
+	if upgradeErr != nil {
+		return nil, upgradeErr
+	}
+	return db, err
+}
+
+// checkSchema returns the version of db if it must be upgraded to match schema, or 0 if it already matches.
+func checkSchema(ctx context.Context, db *Database, schema Schema) (uint, error) {
+	current, err := db.Schema(ctx)
+	if err != nil {
+		return 0, err
+	}
+	diff, err := diffSchema(current, schema)
+	if err != nil || diff.empty() {
+		return 0, err
+	}
+	return db.Version()
+}
+
+func upgradeSchema(upgrade Upgrade, schema Schema) error {
+	storeNames, err := upgrade.Database.ObjectStoreNames()
+	if err != nil {
+		return err
+	}
+	current, err := readSchema(upgrade.Transaction, storeNames)
+	if err != nil {
+		return err
+	}
+	diff, err := diffSchema(current, schema)
+	if err != nil {
+		return err
+	}
+	return diff.apply(upgrade)
+}
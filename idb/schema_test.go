@@ -0,0 +1,103 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestFactoryOpenSchema(t *testing.T) { // nolint:paralleltest // Deletes all databases, should not run in parallel.
+	ctx := context.Background()
+	dbFactory := testFactory(t)
+	name := testDBPrefix + "mydb"
+	open := func(schema Schema) (*Database, uint) {
+		t.Helper()
+		db, err := dbFactory.OpenSchema(ctx, name, schema)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		version, err := db.Version()
+		assert.NoError(t, err)
+		actual, err := db.Schema(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, schema, actual)
+		return db, version
+	}
+
+	schema := Schema{Stores: []StoreSchema{
+		{Name: "a", Options: ObjectStoreOptions{KeyPath: NewKeyPath("id")}, Indexes: []IndexSchema{
+			{Name: "byName", KeyPath: NewKeyPath("name")},
+			{Name: "byTag", KeyPath: NewKeyPath("tags"), Options: IndexOptions{MultiEntry: true}},
+		}},
+		{Name: "b", Options: ObjectStoreOptions{AutoIncrement: true}},
+	}}
+	db, version := open(schema)
+	assert.Equal(t, uint(1), version)
+	txn, err := db.Transaction(TransactionReadWrite, "a")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("a")
+	assert.NoError(t, err)
+	_, err = store.PutValue(map[string]interface{}{"id": 1, "name": "x"})
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+	assert.NoError(t, db.Close())
+
+	// the same schema doesn't bump the version
+	db, version = open(schema)
+	assert.Equal(t, uint(1), version)
+	assert.NoError(t, db.Close())
+
+	// change an index's options, drop an index and a store, and add a store
+	schema = Schema{Stores: []StoreSchema{
+		{Name: "a", Options: ObjectStoreOptions{KeyPath: NewKeyPath("id")}, Indexes: []IndexSchema{
+			{Name: "byName", KeyPath: NewKeyPath("name"), Options: IndexOptions{Unique: true}},
+		}},
+		{Name: "c", Options: ObjectStoreOptions{}},
+	}}
+	db, version = open(schema)
+	assert.Equal(t, uint(2), version)
+	txn, err = db.Transaction(TransactionReadOnly, "a")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("a")
+	assert.NoError(t, err)
+	index, err := store.Index("byName")
+	assert.NoError(t, err)
+	countReq, err := index.Count()
+	assert.NoError(t, err)
+	count, err := countReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), count) // the recreated index is populated from the store's records
+	assert.NoError(t, db.Close())
+
+	schema.Stores[0].Options.KeyPath = NewKeyPath("otherID")
+	_, err = dbFactory.OpenSchema(ctx, name, schema)
+	assert.ErrorIs(t, err, ErrSchemaIncompatible)
+
+	_, err = dbFactory.OpenSchema(ctx, name, Schema{Stores: []StoreSchema{{Name: "a"}, {Name: "a"}}})
+	assert.Error(t, err)
+}
+
+func TestDatabaseSchema(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("users", ObjectStoreOptions{KeyPath: NewKeyPath("id"), AutoIncrement: true})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("email", NewKeyPath("email"), IndexOptions{Unique: true})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("tags", NewCompoundKeyPath("kind", "tags"), IndexOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("blobs", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	schema, err := db.Schema(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, `store "blobs"
+store "users" key path "id" auto increment
+  index "email" key path "email" unique
+  index "tags" key path ["kind", "tags"]
+`, schema.String())
+}
@@ -0,0 +1,92 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// BucketOptions contains options for creating a storage bucket. Options only apply when the bucket is created; reopening an existing bucket keeps its original options.
+type BucketOptions struct {
+	// Persisted asks the browser not to evict the bucket under storage pressure. The browser may deny it.
+	Persisted bool
+	// Durability is the durability of the bucket's transactions which don't set their own. DurabilityDefault leaves it to the browser.
+	Durability TransactionDurability
+	// Quota is the maximum number of bytes the bucket may store. Zero means only the origin's quota applies.
+	Quota uint64
+	// Expires is when the browser may delete the bucket and its data. The zero time means it doesn't expire.
+	Expires time.Time
+}
+
+func (o BucketOptions) jsValue() map[string]interface{} {
+	options := make(map[string]interface{})
+	if o.Persisted {
+		options["persisted"] = true
+	}
+	if o.Durability != DurabilityDefault {
+		options["durability"] = o.Durability.String()
+	}
+	if o.Quota > 0 {
+		options["quota"] = o.Quota
+	}
+	if !o.Expires.IsZero() {
+		options["expires"] = o.Expires.UnixMilli()
+	}
+	return options
+}
+
+// storageBuckets returns navigator.storageBuckets, or undefined if the browser doesn't support the Storage Buckets API.
+func storageBuckets() safejs.Value {
+	navigator, err := safejs.Global().Get("navigator")
+	if err != nil || navigator.Type() != safejs.TypeObject {
+		return safejs.Undefined()
+	}
+	buckets, err := navigator.Get("storageBuckets")
+	if err != nil || buckets.Type() != safejs.TypeObject {
+		return safejs.Undefined()
+	}
+	return buckets
+}
+
+// StorageBucketsSupported returns true if the browser supports the Storage Buckets API, so BucketFactory opens databases in their own buckets.
+func StorageBucketsSupported() bool {
+	return !storageBuckets().IsUndefined()
+}
+
+// BucketFactory returns the factory for the databases in the storage bucket named bucketName, creating the bucket with options if it doesn't exist.
+// Buckets have their own quota, durability, and expiration, and the browser evicts each one separately from the others.
+//
+// If the browser doesn't support the Storage Buckets API, returns Global(), so databases are opened in the default bucket instead.
+func BucketFactory(ctx context.Context, bucketName string, options BucketOptions) (*Factory, error) {
+	buckets := storageBuckets()
+	if buckets.IsUndefined() {
+		return Global(), nil
+	}
+	promise, err := buckets.Call("open", bucketName, options.jsValue())
+	if err != nil {
+		return nil, tryAsDOMException(err)
+	}
+	bucket, err := awaitPromise(ctx, promise)
+	if err != nil {
+		return nil, err
+	}
+	jsFactory, err := bucket.Get("indexedDB")
+	if err != nil {
+		return nil, err
+	}
+	return WrapFactory(safejs.Unsafe(jsFactory))
+}
+
+// OpenInBucket requests to open a connection to the database named dbName in the storage bucket named bucketName, like Factory.Open.
+// If the browser doesn't support the Storage Buckets API, opens the database in the default bucket. See BucketFactory.
+func OpenInBucket(ctx context.Context, bucketName string, options BucketOptions, dbName string, version uint, upgrader Upgrader) (*OpenDBRequest, error) {
+	factory, err := BucketFactory(ctx, bucketName, options)
+	if err != nil {
+		return nil, err
+	}
+	return factory.Open(ctx, dbName, version, upgrader)
+}
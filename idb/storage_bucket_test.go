@@ -0,0 +1,49 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestOpenInBucket(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	options := BucketOptions{Persisted: true, Durability: DurabilityRelaxed, Expires: time.Now().Add(time.Hour)}
+	assert.Equal(t, map[string]interface{}{
+		"persisted":  true,
+		"durability": "relaxed",
+		"expires":    options.Expires.UnixMilli(),
+	}, options.jsValue())
+
+	name := fmt.Sprintf("%s%s/%d", testDBPrefix, t.Name(), time.Now().UnixNano())
+	upgraded := false
+	req, err := OpenInBucket(ctx, "test-bucket", options, name, 1, func(db *Database, oldVersion, newVersion uint) error {
+		upgraded = true
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		return err
+	})
+	assert.NoError(t, err)
+	db, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, true, upgraded)
+	names, err := db.ObjectStoreNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mystore"}, names)
+
+	factory, err := BucketFactory(ctx, "test-bucket", options)
+	assert.NoError(t, err)
+	if !StorageBucketsSupported() {
+		assert.Equal(t, Global(), factory)
+	}
+	assert.NoError(t, db.Close())
+	deleteReq, err := factory.DeleteDatabase(name)
+	assert.NoError(t, err)
+	assert.NoError(t, deleteReq.Await(ctx))
+}
@@ -0,0 +1,72 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hack-pad/safejs"
+)
+
+// ErrStorageEstimateUnsupported is returned by EstimateStorage when the browser doesn't support the Storage API.
+var ErrStorageEstimateUnsupported = errors.New("storage estimates are not supported")
+
+// StorageEstimate is the browser's estimate of how much storage the origin uses and may use, in bytes.
+type StorageEstimate struct {
+	// Usage is the number of bytes the origin uses.
+	Usage uint64
+	// Quota is the number of bytes the origin may use.
+	Quota uint64
+}
+
+// EstimateStorage returns the browser's estimate of the origin's storage usage and quota, with navigator.storage.estimate().
+// Estimates are deliberately imprecise, so use them to decide roughly how much to evict, not to predict whether a write fits.
+// Returns ErrStorageEstimateUnsupported if the browser doesn't support the Storage API.
+func EstimateStorage(ctx context.Context) (StorageEstimate, error) {
+	storage := storageManager()
+	if storage.IsUndefined() {
+		return StorageEstimate{}, ErrStorageEstimateUnsupported
+	}
+	promise, err := storage.Call("estimate")
+	if err != nil {
+		return StorageEstimate{}, tryAsDOMException(err)
+	}
+	jsEstimate, err := awaitPromise(ctx, promise)
+	if err != nil {
+		return StorageEstimate{}, err
+	}
+	usage, err := jsGetBytes(jsEstimate, "usage")
+	if err != nil {
+		return StorageEstimate{}, err
+	}
+	quota, err := jsGetBytes(jsEstimate, "quota")
+	if err != nil {
+		return StorageEstimate{}, err
+	}
+	return StorageEstimate{Usage: usage, Quota: quota}, nil
+}
+
+// storageManager returns navigator.storage, or undefined if the browser doesn't support the Storage API.
+func storageManager() safejs.Value {
+	navigator, err := safejs.Global().Get("navigator")
+	if err != nil || navigator.Type() != safejs.TypeObject {
+		return safejs.Undefined()
+	}
+	storage, err := navigator.Get("storage")
+	if err != nil || storage.Type() != safejs.TypeObject {
+		return safejs.Undefined()
+	}
+	return storage
+}
+
+// jsGetBytes returns the byte count in property of value, or 0 if it's missing.
+func jsGetBytes(value safejs.Value, property string) (uint64, error) {
+	bytes, err := value.Get(property)
+	if err != nil || bytes.IsUndefined() {
+		return 0, err
+	}
+	n, err := bytes.Float()
+	return uint64(n), err
+}
@@ -0,0 +1,22 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestEstimateStorage(t *testing.T) {
+	t.Parallel()
+	estimate, err := EstimateStorage(context.Background())
+	if errors.Is(err, ErrStorageEstimateUnsupported) {
+		t.Skip("storage estimates are not supported")
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, true, estimate.Usage <= estimate.Quota)
+}
@@ -0,0 +1,80 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hack-pad/safejs"
+)
+
+// ErrNotSupported is returned when IndexedDB isn't available, like in some private browsing modes or in wasm hosts other than browsers.
+var ErrNotSupported = errors.New("IndexedDB is not supported in this environment")
+
+// idbGlobals are the IndexedDB classes values are checked against, loaded on first use so polyfills installed after the program starts still work.
+var idbGlobals struct {
+	once        sync.Once
+	request     safejs.Value
+	index       safejs.Value
+	objectStore safejs.Value
+	err         error
+}
+
+func loadIDBGlobals() error {
+	idbGlobals.once.Do(func() {
+		for _, global := range []struct {
+			name  string
+			value *safejs.Value
+		}{
+			{"IDBRequest", &idbGlobals.request},
+			{"IDBIndex", &idbGlobals.index},
+			{"IDBObjectStore", &idbGlobals.objectStore},
+		} {
+			*global.value, idbGlobals.err = lookupGlobal(global.name)
+			if idbGlobals.err != nil {
+				return
+			}
+		}
+	})
+	return idbGlobals.err
+}
+
+// lookupGlobal returns the global JS variable name, or an error wrapping ErrNotSupported if it isn't defined.
+func lookupGlobal(name string) (safejs.Value, error) {
+	value, err := safejs.Global().Get(name)
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	truthy, err := value.Truthy()
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	if !truthy {
+		return safejs.Undefined(), fmt.Errorf("%w: global JS variable %q is not defined", ErrNotSupported, name)
+	}
+	return value, nil
+}
+
+// IsSupported returns true if IndexedDB is available, so Global doesn't panic. Check it to fall back to other storage when IndexedDB is disabled or missing.
+func IsSupported() bool {
+	_, err := LoadGlobal()
+	return err == nil
+}
+
+func jsIDBRequest() safejs.Value {
+	_ = loadIDBGlobals()
+	return idbGlobals.request
+}
+
+func jsIDBIndex() safejs.Value {
+	_ = loadIDBGlobals()
+	return idbGlobals.index
+}
+
+func jsIDBObjectStore() safejs.Value {
+	_ = loadIDBGlobals()
+	return idbGlobals.objectStore
+}
@@ -0,0 +1,110 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+var jsDate safejs.Value
+
+func init() {
+	var err error
+	jsDate, err = safejs.Global().Get("Date")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TimeKey converts t into a JS Date for use as a key. Dates have millisecond precision, so t is truncated to the millisecond.
+// Date keys sort after number keys and before string keys.
+func TimeKey(t time.Time) (safejs.Value, error) {
+	return jsDate.New(t.UnixMilli())
+}
+
+// KeyTime converts a Date key, like those returned from a cursor or GetAllKeys, into a time.Time.
+func KeyTime(key safejs.Value) (time.Time, error) {
+	isDate, err := key.InstanceOf(jsDate)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !isDate {
+		return time.Time{}, fmt.Errorf("expected a Date key, got %s", key.Type())
+	}
+	millis, err := key.Call("getTime")
+	if err != nil {
+		return time.Time{}, err
+	}
+	ms, err := millis.Float()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if math.IsNaN(ms) {
+		return time.Time{}, errors.New("invalid Date key")
+	}
+	return time.UnixMilli(int64(ms)), nil
+}
+
+// KeyTime returns the cursor's key as a time.Time. The key must be a Date.
+func (c *Cursor) KeyTime() (time.Time, error) {
+	key, err := c.Key()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return KeyTime(key)
+}
+
+// PrimaryKeyTime returns the cursor's primary key as a time.Time. The primary key must be a Date.
+func (c *Cursor) PrimaryKeyTime() (time.Time, error) {
+	primaryKey, err := c.PrimaryKey()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return KeyTime(primaryKey)
+}
+
+// NewKeyRangeTimeBound is the same as NewKeyRangeBound, but with Date key bounds.
+func NewKeyRangeTimeBound(lower, upper time.Time, lowerOpen, upperOpen bool) (*KeyRange, error) {
+	lowerKey, err := TimeKey(lower)
+	if err != nil {
+		return nil, err
+	}
+	upperKey, err := TimeKey(upper)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeBound(lowerKey, upperKey, lowerOpen, upperOpen)
+}
+
+// NewKeyRangeTimeLowerBound is the same as NewKeyRangeLowerBound, but with a Date key bound.
+func NewKeyRangeTimeLowerBound(lower time.Time, open bool) (*KeyRange, error) {
+	lowerKey, err := TimeKey(lower)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeLowerBound(lowerKey, open)
+}
+
+// NewKeyRangeTimeUpperBound is the same as NewKeyRangeUpperBound, but with a Date key bound.
+func NewKeyRangeTimeUpperBound(upper time.Time, open bool) (*KeyRange, error) {
+	upperKey, err := TimeKey(upper)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeUpperBound(upperKey, open)
+}
+
+// NewKeyRangeTimeOnly is the same as NewKeyRangeOnly, but with a Date key.
+func NewKeyRangeTimeOnly(only time.Time) (*KeyRange, error) {
+	key, err := TimeKey(only)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRangeOnly(key)
+}
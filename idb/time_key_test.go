@@ -0,0 +1,94 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestTimeKey(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	key, err := TimeKey(now)
+	assert.NoError(t, err)
+	keyTime, err := KeyTime(key)
+	assert.NoError(t, err)
+	assert.Equal(t, true, keyTime.Equal(now.Truncate(time.Millisecond)))
+
+	_, err = KeyTime(safejs.Safe(js.ValueOf(1)))
+	assert.Error(t, err)
+	value, err := ValueOf(map[string]interface{}{"created": now})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"created":"2024-05-06T07:08:09.123Z"}`, jsonString(t, value))
+}
+
+func TestObjectStoreTimeKeys(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := func(i int) time.Time {
+		return start.AddDate(0, 0, i)
+	}
+	for i := 0; i < 5; i++ {
+		_, err := store.AddTimeKey(day(i), safejs.Safe(js.ValueOf(i)))
+		assert.NoError(t, err)
+	}
+	_, err = store.PutTimeKey(day(1), safejs.Safe(js.ValueOf("one")))
+	assert.NoError(t, err)
+	_, err = store.DeleteTimeKey(day(4))
+	assert.NoError(t, err)
+	getReq, err := store.GetTimeKey(day(1))
+	assert.NoError(t, err)
+	value, err := getReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, safejs.Safe(js.ValueOf("one")), value)
+
+	keyTimes := func(keyRange *KeyRange) []time.Time {
+		req, err := store.OpenCursorRange(keyRange, CursorNext)
+		assert.NoError(t, err)
+		var times []time.Time
+		assert.NoError(t, req.Iter(ctx, func(cursor *CursorWithValue) error {
+			keyTime, err := cursor.KeyTime()
+			if err != nil {
+				return err
+			}
+			primaryKeyTime, err := cursor.PrimaryKeyTime()
+			if err != nil {
+				return err
+			}
+			assert.Equal(t, true, keyTime.Equal(primaryKeyTime))
+			times = append(times, keyTime.UTC())
+			return nil
+		}))
+		return times
+	}
+
+	boundRange, err := NewKeyRangeTimeBound(day(1), day(3), true, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{day(2), day(3)}, keyTimes(boundRange))
+	lowerRange, err := NewKeyRangeTimeLowerBound(day(3), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{day(3)}, keyTimes(lowerRange))
+	upperRange, err := NewKeyRangeTimeUpperBound(day(1), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{day(0)}, keyTimes(upperRange))
+	onlyRange, err := NewKeyRangeTimeOnly(day(2))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{day(2)}, keyTimes(onlyRange))
+}
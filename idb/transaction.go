@@ -6,6 +6,9 @@ package idb
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/aperturerobotics/go-indexeddb/idb/internal/jscache"
 	"github.com/hack-pad/safejs"
@@ -15,6 +18,9 @@ var (
 	supportsTransactionCommit = checkSupportsTransactionCommit()
 
 	errNotInTransaction = errors.New("Not part of a transaction")
+
+	// ErrTransactionLifetime is returned when a transaction was aborted for exceeding TransactionOptions.MaxLifetime, from its pending requests and from awaiting it.
+	ErrTransactionLifetime = errors.New("transaction exceeded its maximum lifetime")
 )
 
 func checkSupportsTransactionCommit() bool {
@@ -117,6 +123,16 @@ type Transaction struct {
 	db            *Database
 	jsTransaction safejs.Value
 	objectStores  map[string]*ObjectStore
+	diagnostics   *txnDiagnostics // nil unless enabled with SetDiagnostics
+
+	trackOnce sync.Once
+	finished  chan struct{} // closed when the transaction finishes, once tracked
+	finishErr error
+
+	abortCauseMu sync.Mutex
+	abortCause   error // why the wrapper aborted the transaction, if it did
+
+	requests requestRegistry
 }
 
 func wrapTransaction(db *Database, jsTransaction safejs.Value) *Transaction {
@@ -124,7 +140,23 @@ func wrapTransaction(db *Database, jsTransaction safejs.Value) *Transaction {
 		db:            db,
 		jsTransaction: jsTransaction,
 		objectStores:  make(map[string]*ObjectStore, 1),
+		diagnostics:   newTxnDiagnostics(),
+		requests:      requestRegistry{lastActivity: time.Now()},
+	}
+}
+
+// WrapTransaction wraps an IDBTransaction created outside this package. Its Database is wrapped from the transaction's connection, so it doesn't share state like a Codec or frozen stores with other Database values.
+func WrapTransaction(jsTransaction safejs.Value) (*Transaction, error) {
+	jsDB, err := jsTransaction.Get("db")
+	if err != nil {
+		return nil, err
 	}
+	return wrapTransaction(wrapDatabase(jsDB), jsTransaction), nil
+}
+
+// Unwrap returns the underlying JavaScript IDBTransaction object.
+func (t *Transaction) Unwrap() safejs.Value {
+	return t.jsTransaction
 }
 
 // Database returns the database connection with which this transaction is associated.
@@ -157,7 +189,7 @@ func (t *Transaction) Err() error {
 // Abort rolls back all the changes to objects in the database associated with this transaction.
 func (t *Transaction) Abort() error {
 	_, err := t.jsTransaction.Call("abort")
-	return tryAsDOMException(err)
+	return asTxnFinished(tryAsDOMException(err))
 }
 
 // Mode returns the mode for isolating access to data in the object stores that are in the scope of the transaction. The default value is TransactionReadOnly.
@@ -186,7 +218,7 @@ func (t *Transaction) ObjectStore(name string) (*ObjectStore, error) {
 	}
 	jsObjectStore, err := t.jsTransaction.Call("objectStore", name)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, asTxnFinished(tryAsDOMException(err))
 	}
 	store := wrapObjectStore(t, jsObjectStore)
 	t.objectStores[name] = store
@@ -200,7 +232,7 @@ func (t *Transaction) Commit() error {
 	}
 
 	_, err := t.jsTransaction.Call("commit")
-	return tryAsDOMException(err)
+	return asTxnFinished(tryAsDOMException(err))
 }
 
 // Await waits for success or failure, then returns the results.
@@ -208,12 +240,65 @@ func (t *Transaction) Await(ctx context.Context) error {
 	resultErr := t.listenFinished()
 	select {
 	case err := <-resultErr:
-		return tryAsDOMException(err)
+		return t.withStats(t.withAbortCause(tryAsDOMException(err)))
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AwaitComplete waits for the transaction to complete, which confirms every request made in it, then returns the first error of the transaction, if any.
+// Write requests can be made without awaiting each one, then confirmed with a single AwaitComplete. This saves waiting on each result, so there are no gaps for the transaction to commit automatically in.
+//
+// Unlike Await, AwaitComplete returns once the transaction finished even if it did so before the call, as long as a write request was made in it with this package.
+func (t *Transaction) AwaitComplete(ctx context.Context) error {
+	t.trackFinished()
+	select {
+	case <-t.finished:
+		return t.finishErr
 	case <-ctx.Done():
 		return ctx.Err()
 	}
 }
 
+// trackFinished starts recording the outcome of the transaction for AwaitComplete, if it isn't already. Write requests call it so the outcome is recorded before the transaction can finish.
+func (t *Transaction) trackFinished() {
+	if t == nil {
+		return
+	}
+	t.trackOnce.Do(func() {
+		t.finished = make(chan struct{})
+		resultErr := t.listenFinished()
+		go func() {
+			t.finishErr = t.withStats(t.withAbortCause(tryAsDOMException(<-resultErr)))
+			close(t.finished)
+		}()
+	})
+}
+
+func (t *Transaction) setAbortCause(cause error) {
+	t.abortCauseMu.Lock()
+	defer t.abortCauseMu.Unlock()
+	t.abortCause = cause
+}
+
+// withAbortCause adds the reason the wrapper aborted the transaction to err, which failed because of the abort. Returns err unchanged if the wrapper didn't abort it.
+func (t *Transaction) withAbortCause(err error) error {
+	if t == nil {
+		return err
+	}
+	t.abortCauseMu.Lock()
+	cause := t.abortCause
+	t.abortCauseMu.Unlock()
+	switch {
+	case cause == nil:
+		return err
+	case err == nil:
+		return cause
+	default:
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+}
+
 // listenFinished listens to this transaction's completion events which eventually resolves with nil or an error.
 // Resolves with the first IDBRequest's error
 func (t *Transaction) listenFinished() <-chan error {
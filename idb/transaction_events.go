@@ -0,0 +1,146 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// ListenComplete invokes complete once the transaction commits successfully. Stops listening when ctx is done or the transaction finishes.
+func (t *Transaction) ListenComplete(ctx context.Context, complete func()) error {
+	return t.listen(ctx, "complete", func(safejs.Value) {
+		complete()
+	})
+}
+
+// ListenAbort invokes abort once the transaction aborts, with the error which caused it. The error is nil if the transaction was aborted with Transaction.Abort().
+// Stops listening when ctx is done or the transaction finishes.
+func (t *Transaction) ListenAbort(ctx context.Context, abort func(err error)) error {
+	return t.listen(ctx, "abort", func(safejs.Value) {
+		abort(t.Err())
+	})
+}
+
+// ListenError invokes failed with the error of each request in the transaction which fails. Stops listening when ctx is done or the transaction finishes.
+func (t *Transaction) ListenError(ctx context.Context, failed func(err error)) error {
+	return t.listen(ctx, "error", func(event safejs.Value) {
+		// Error event target is always an IDBRequest, which is guaranteed to be a DOMException with a 'name' property.
+		properties, err := jsGetNested(event, "target", "error")
+		if err == nil {
+			err = domExceptionAsError(properties[1])
+		}
+		failed(err)
+	})
+}
+
+// BindContext aborts the transaction if ctx is done before the transaction finishes, so a read-write transaction stops holding its locks once the caller gives up.
+func (t *Transaction) BindContext(ctx context.Context) error {
+	return t.abortWhenDone(ctx, nil, func() {})
+}
+
+// limitLifetime aborts the transaction with ErrTransactionLifetime if it hasn't finished after maxLifetime.
+func (t *Transaction) limitLifetime(maxLifetime time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), maxLifetime)
+	return t.abortWhenDone(ctx, func() error {
+		pending := t.PendingRequests()
+		if len(pending) == 0 {
+			return ErrTransactionLifetime
+		}
+		return fmt.Errorf("%w, with requests pending: %s", ErrTransactionLifetime, describeRequests(pending))
+	}, cancel)
+}
+
+// abortWhenDone aborts the transaction if ctx is done before the transaction finishes, recording the error from cause as the reason for the abort if cause is not nil.
+// Calls cleanup once the transaction finishes or is aborted.
+func (t *Transaction) abortWhenDone(ctx context.Context, cause func() error, cleanup func()) error {
+	finished := make(chan struct{})
+	var finishOnce sync.Once
+	finish := func() {
+		finishOnce.Do(func() { close(finished) })
+	}
+	listenCtx, cancel := context.WithCancel(context.Background())
+	if err := t.ListenComplete(listenCtx, finish); err != nil {
+		cancel()
+		cleanup()
+		return err
+	}
+	if err := t.ListenAbort(listenCtx, func(error) { finish() }); err != nil {
+		cancel()
+		cleanup()
+		return err
+	}
+	go func() {
+		defer cleanup()
+		defer cancel()
+		select {
+		case <-ctx.Done():
+			var causeErr error
+			if cause != nil {
+				causeErr = cause() // before aborting, while the pending requests are still pending
+			}
+			// abort events are dispatched later, so the cause is set before anything observes the abort
+			if t.Abort() == nil && causeErr != nil {
+				t.setAbortCause(causeErr)
+			}
+		case <-finished:
+		}
+	}()
+	return nil
+}
+
+// listen invokes fn for each of the transaction's eventName events until ctx is done or the transaction finishes.
+func (t *Transaction) listen(ctx context.Context, eventName string, fn func(event safejs.Value)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	listener, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		var event safejs.Value
+		if len(args) > 0 {
+			event = args[0]
+		}
+		fn(event)
+		return nil
+	})
+	if err != nil {
+		cancel()
+		return err
+	}
+	// listeners run in the order they're added, so fn runs before finished removes it
+	finished, err := safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		cancel()
+		return nil
+	})
+	if err != nil {
+		cancel()
+		listener.Release()
+		return err
+	}
+	listeners := []struct {
+		eventName string
+		fn        safejs.Func
+	}{
+		{eventName, listener},
+		{"complete", finished},
+		{"abort", finished},
+	}
+	go func() {
+		<-ctx.Done()
+		for _, l := range listeners {
+			_, _ = t.jsTransaction.Call(removeEventListener, l.eventName, l.fn)
+		}
+		listener.Release()
+		finished.Release()
+	}()
+	for _, l := range listeners {
+		_, err := t.jsTransaction.Call(addEventListener, l.eventName, l.fn)
+		if err != nil {
+			cancel()
+			return tryAsDOMException(err)
+		}
+	}
+	return nil
+}
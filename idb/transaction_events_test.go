@@ -0,0 +1,106 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestTransactionListen(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		completed, aborted := make(chan struct{}), make(chan error, 1)
+		assert.NoError(t, txn.ListenComplete(ctx, func() { close(completed) }))
+		assert.NoError(t, txn.ListenAbort(ctx, func(err error) { aborted <- err }))
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		_, err = store.PutKeyValue(1, "a")
+		assert.NoError(t, err)
+		assert.NoError(t, txn.Await(ctx))
+		<-completed
+		select {
+		case err := <-aborted:
+			t.Errorf("unexpected abort: %v", err)
+		default:
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		aborted, failed := make(chan error, 1), make(chan error, 1)
+		assert.NoError(t, txn.ListenAbort(ctx, func(err error) { aborted <- err }))
+		assert.NoError(t, txn.ListenError(ctx, func(err error) { failed <- err }))
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		_, err = store.AddKeyValue(1, "duplicate")
+		assert.NoError(t, err)
+		assert.ErrorIs(t, <-failed, NewDOMException("ConstraintError"))
+		assert.ErrorIs(t, <-aborted, NewDOMException("ConstraintError"))
+	})
+
+	t.Run("explicit abort", func(t *testing.T) {
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		aborted := make(chan error, 1)
+		assert.NoError(t, txn.ListenAbort(ctx, func(err error) { aborted <- err }))
+		assert.NoError(t, txn.Abort())
+		assert.NoError(t, <-aborted)
+	})
+}
+
+func TestTransactionBindContext(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	txnCtx, cancel := context.WithCancel(ctx)
+	assert.NoError(t, txn.BindContext(txnCtx))
+	aborted := make(chan error, 1)
+	assert.NoError(t, txn.ListenAbort(ctx, func(err error) { aborted <- err }))
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue(1, "a")
+	assert.NoError(t, err)
+	cancel()
+	assert.NoError(t, <-aborted)
+
+	txn, err = db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	req, err := store.Count()
+	assert.NoError(t, err)
+	count, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), count)
+
+	// canceling after the transaction completes does nothing
+	txn, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	txnCtx, cancel = context.WithCancel(ctx)
+	assert.NoError(t, txn.BindContext(txnCtx))
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue(1, "a")
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+	cancel()
+}
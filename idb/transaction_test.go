@@ -7,6 +7,7 @@ import (
 	"context"
 	"syscall/js"
 	"testing"
+	"time"
 
 	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
 	"github.com/hack-pad/safejs"
@@ -164,3 +165,83 @@ func TestTransactionCommit(t *testing.T) {
 	err = txn.Commit()
 	assert.Error(t, err)
 }
+
+func TestTransactionAwaitComplete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		t.Parallel()
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			_, err := store.PutKeyValue(i, i)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, txn.AwaitComplete(ctx))
+		// the transaction already finished, so the recorded outcome is returned
+		assert.NoError(t, txn.AwaitComplete(ctx))
+
+		txn, err = db.Transaction(TransactionReadOnly, "mystore")
+		assert.NoError(t, err)
+		store, err = txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		countReq, err := store.Count()
+		assert.NoError(t, err)
+		count, err := countReq.Await(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(10), count)
+	})
+
+	t.Run("failed write", func(t *testing.T) {
+		t.Parallel()
+		txn, err := db.Transaction(TransactionReadWrite, "mystore")
+		assert.NoError(t, err)
+		store, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		_, err = store.AddKeyValue("duplicate", 1)
+		assert.NoError(t, err)
+		_, err = store.AddKeyValue("duplicate", 2)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, txn.AwaitComplete(ctx), NewDOMException("ConstraintError"))
+	})
+}
+
+func TestTransactionMaxLifetime(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+
+	txn, err := db.TransactionWithOptions(TransactionOptions{Mode: TransactionReadWrite, MaxLifetime: 20 * time.Millisecond}, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue("key", "value")
+	assert.NoError(t, err)
+	// simulate a request which never resolves
+	stop, err := txn.KeepAlive(ctx, time.Second)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	stop()
+	assert.ErrorIs(t, txn.AwaitComplete(ctx), ErrTransactionLifetime)
+
+	txn, err = db.TransactionWithOptions(TransactionOptions{Mode: TransactionReadOnly, MaxLifetime: time.Minute}, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	req, err := store.Count()
+	assert.NoError(t, err)
+	count, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), count)
+	assert.NoError(t, txn.Await(ctx))
+}
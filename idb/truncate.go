@@ -0,0 +1,215 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+
+	"github.com/hack-pad/safejs"
+)
+
+// TruncateOptions contains options for Truncate.
+type TruncateOptions struct {
+	// ChunkSize is the maximum number of records deleted per transaction. Defaults to 1000.
+	ChunkSize uint
+	// OnEvent is optionally called when Truncate starts, after each chunk's transaction completes, and when it finishes. Return an error to stop truncating.
+	OnEvent func(TruncateEvent) error
+	// BroadcastChannel is optionally the name of a BroadcastChannel to post each event to, so other tabs can pause writing to the store until the truncation finishes. See WatchTruncate.
+	BroadcastChannel string
+}
+
+// TruncatePhase is the stage of a truncation described by a TruncateEvent.
+type TruncatePhase string
+
+const (
+	// TruncateStarted is sent once the object store is frozen, before any records are deleted.
+	TruncateStarted TruncatePhase = "started"
+	// TruncateRemoved is sent after each chunk of records is deleted.
+	TruncateRemoved TruncatePhase = "removed"
+	// TruncateFinished is sent when the truncation stops, whether or not it succeeded.
+	TruncateFinished TruncatePhase = "finished"
+)
+
+// TruncateEvent describes the progress of Truncate.
+type TruncateEvent struct {
+	StoreName string
+	Phase     TruncatePhase
+	// Lower and Upper are the first and last keys of the records deleted by a TruncateRemoved event. Undefined for other phases.
+	Lower, Upper safejs.Value
+	// Removed is the number of records deleted by a TruncateRemoved event.
+	Removed uint
+}
+
+func (e TruncateEvent) jsValue() (safejs.Value, error) {
+	return ValueOf(map[string]interface{}{
+		"store":   e.StoreName,
+		"phase":   string(e.Phase),
+		"lower":   e.Lower,
+		"upper":   e.Upper,
+		"removed": e.Removed,
+	})
+}
+
+func parseTruncateEvent(value safejs.Value) (TruncateEvent, error) {
+	properties, err := getProperties(value, "store", "phase", "lower", "upper", "removed")
+	if err != nil {
+		return TruncateEvent{}, err
+	}
+	storeName, err := properties[0].String()
+	if err != nil {
+		return TruncateEvent{}, err
+	}
+	phase, err := properties[1].String()
+	if err != nil {
+		return TruncateEvent{}, err
+	}
+	removed, err := properties[4].Int()
+	if err != nil {
+		return TruncateEvent{}, err
+	}
+	return TruncateEvent{
+		StoreName: storeName,
+		Phase:     TruncatePhase(phase),
+		Lower:     properties[2],
+		Upper:     properties[3],
+		Removed:   uint(removed),
+	}, nil
+}
+
+// Truncate deletes every record in the object store named storeName. Unlike ObjectStore.Clear, it suits very large stores and stores other tabs write to.
+//
+// The store is frozen with Database.FreezeStore for the duration, then records are deleted from the start of the store in chunks, each in its own short read-write transaction, so the main thread doesn't stall on one huge deletion.
+// The freeze makes writes to the store fail with ErrStoreFrozen in other tabs which called Database.WatchFreezes. To have them pause writing until the truncation finishes instead, announce it with options.BroadcastChannel and watch it with WatchTruncate.
+// Returns the number of records deleted.
+func Truncate(ctx context.Context, db *Database, storeName string, options TruncateOptions) (removed uint, err error) {
+	chunkSize := options.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultScanChunkSize
+	}
+	var channel safejs.Value
+	if options.BroadcastChannel != "" {
+		jsBroadcastChannel, err := safejs.Global().Get("BroadcastChannel")
+		if err != nil {
+			return 0, err
+		}
+		channel, err = jsBroadcastChannel.New(options.BroadcastChannel)
+		if err != nil {
+			return 0, tryAsDOMException(err)
+		}
+		defer func() {
+			_, _ = channel.Call("close")
+		}()
+	}
+	emit := func(event TruncateEvent) error {
+		event.StoreName = storeName
+		if options.BroadcastChannel != "" {
+			message, err := event.jsValue()
+			if err != nil {
+				return err
+			}
+			if _, err := channel.Call("postMessage", message); err != nil {
+				return tryAsDOMException(err)
+			}
+		}
+		if options.OnEvent != nil {
+			return options.OnEvent(event)
+		}
+		return nil
+	}
+
+	freeze, err := db.FreezeStore(ctx, storeName)
+	if err != nil {
+		return 0, err
+	}
+	defer freeze.Unfreeze()
+	defer func() {
+		finishErr := emit(TruncateEvent{Phase: TruncateFinished, Lower: safejs.Undefined(), Upper: safejs.Undefined()})
+		if err == nil {
+			err = finishErr
+		}
+	}()
+	if err := emit(TruncateEvent{Phase: TruncateStarted, Lower: safejs.Undefined(), Upper: safejs.Undefined()}); err != nil {
+		return 0, err
+	}
+	sizer := newBatchSizer(chunkSize)
+	for {
+		event, err := truncateChunk(ctx, freeze, storeName, sizer.next())
+		if err != nil || event.Removed == 0 {
+			return removed, err
+		}
+		removed += event.Removed
+		if err := emit(event); err != nil {
+			return removed, err
+		}
+	}
+}
+
+// truncateChunk deletes the first chunkSize records of the store and returns the TruncateRemoved event for them. Removed is 0 if the store is empty.
+func truncateChunk(ctx context.Context, freeze *StoreFreeze, storeName string, chunkSize uint) (TruncateEvent, error) {
+	txn, err := freeze.Transaction(TransactionReadWrite, storeName)
+	if err != nil {
+		return TruncateEvent{}, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return TruncateEvent{}, err
+	}
+	req, err := store.GetAllKeysRange(nil, chunkSize)
+	if err != nil {
+		return TruncateEvent{}, err
+	}
+	keys, err := req.Await(ctx)
+	if err != nil || len(keys) == 0 {
+		return TruncateEvent{}, err
+	}
+	event := TruncateEvent{Phase: TruncateRemoved, Lower: keys[0], Upper: keys[len(keys)-1], Removed: uint(len(keys))}
+	keyRange, err := NewKeyRangeUpperBound(event.Upper, false)
+	if err != nil {
+		return TruncateEvent{}, err
+	}
+	if _, err := store.Delete(keyRange.jsKeyRange); err != nil {
+		return TruncateEvent{}, err
+	}
+	return event, txn.AwaitComplete(ctx)
+}
+
+// WatchTruncate calls fn with each event Truncate posts to the BroadcastChannel named channelName, like those from other tabs, until ctx is done.
+// Use it to pause writes to a store between its TruncateStarted and TruncateFinished events.
+func WatchTruncate(ctx context.Context, channelName string, fn func(TruncateEvent)) error {
+	jsBroadcastChannel, err := safejs.Global().Get("BroadcastChannel")
+	if err != nil {
+		return err
+	}
+	channel, err := jsBroadcastChannel.New(channelName)
+	if err != nil {
+		return tryAsDOMException(err)
+	}
+	onMessage, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		data, err := args[0].Get("data")
+		if err != nil {
+			return nil
+		}
+		event, err := parseTruncateEvent(data)
+		if err != nil {
+			return nil // not a truncate event
+		}
+		fn(event)
+		return nil
+	})
+	if err != nil {
+		_, _ = channel.Call("close")
+		return err
+	}
+	if _, err := channel.Call(addEventListener, "message", onMessage); err != nil {
+		_, _ = channel.Call("close")
+		onMessage.Release()
+		return tryAsDOMException(err)
+	}
+	go func() {
+		<-ctx.Done()
+		_, _ = channel.Call("close")
+		onMessage.Release()
+	}()
+	return nil
+}
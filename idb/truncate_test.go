@@ -0,0 +1,84 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for i := 0; i < 25; i++ {
+		_, err := store.PutKeyValue(i, "value")
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, txn.Await(ctx))
+
+	channelName := fmt.Sprintf("truncate-%d", time.Now().UnixNano())
+	watched := make(chan TruncatePhase, 10)
+	assert.NoError(t, WatchTruncate(ctx, channelName, func(event TruncateEvent) {
+		assert.Equal(t, "mystore", event.StoreName)
+		watched <- event.Phase
+	}))
+
+	var removed []uint
+	var bounds [][2]int
+	count, err := Truncate(ctx, db, "mystore", TruncateOptions{
+		ChunkSize:        10,
+		BroadcastChannel: channelName,
+		OnEvent: func(event TruncateEvent) error {
+			if event.Phase != TruncateRemoved {
+				return nil
+			}
+			// the store is frozen for writes from this Database until the truncation finishes
+			_, err := db.Transaction(TransactionReadWrite, "mystore")
+			assert.ErrorIs(t, err, ErrStoreFrozen)
+			lower, err := event.Lower.Int()
+			assert.NoError(t, err)
+			upper, err := event.Upper.Int()
+			assert.NoError(t, err)
+			removed = append(removed, event.Removed)
+			bounds = append(bounds, [2]int{lower, upper})
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(25), count)
+	assert.Equal(t, []uint{10, 10, 5}, removed)
+	assert.Equal(t, [][2]int{{0, 9}, {10, 19}, {20, 24}}, bounds)
+
+	var phases []TruncatePhase
+	for phase := range watched {
+		phases = append(phases, phase)
+		if phase == TruncateFinished {
+			break
+		}
+	}
+	assert.Equal(t, []TruncatePhase{TruncateStarted, TruncateRemoved, TruncateRemoved, TruncateRemoved, TruncateFinished}, phases)
+
+	txn, err = db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	countReq, err := store.Count()
+	assert.NoError(t, err)
+	remaining, err := countReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), remaining)
+}
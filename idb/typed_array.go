@@ -0,0 +1,57 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"fmt"
+
+	"github.com/hack-pad/safejs"
+)
+
+// Strings returns a request for the same results as strings, for when every element is known to be a string, like the keys of a store with string keys.
+// The results fail to convert if any element isn't a string.
+func (a *ArrayRequest) Strings() *TypedRequest[[]string] {
+	return RequestOf(a.Request, func(result safejs.Value) ([]string, error) {
+		return arrayOf(result, func(value safejs.Value) (string, error) {
+			if value.Type() != safejs.TypeString {
+				return "", fmt.Errorf("expected string, got %s", value.Type())
+			}
+			return value.String()
+		})
+	})
+}
+
+// Ints returns a request for the same results as ints, for when every element is known to be an integer number.
+// The results fail to convert if any element isn't a number.
+func (a *ArrayRequest) Ints() *TypedRequest[[]int] {
+	return RequestOf(a.Request, func(result safejs.Value) ([]int, error) {
+		return arrayOf(result, func(value safejs.Value) (int, error) {
+			if value.Type() != safejs.TypeNumber {
+				return 0, fmt.Errorf("expected number, got %s", value.Type())
+			}
+			return value.Int()
+		})
+	})
+}
+
+// Bytes returns a request for the same results as byte slices, for when every element is known to be binary, like the keys of a store with binary keys.
+// The results fail to convert if any element isn't an ArrayBuffer or a view of one.
+func (a *ArrayRequest) Bytes() *TypedRequest[[][]byte] {
+	return RequestOf(a.Request, func(result safejs.Value) ([][]byte, error) {
+		return arrayOf(result, BinaryKeyBytes)
+	})
+}
+
+func arrayOf[T any](array safejs.Value, convert func(safejs.Value) (T, error)) ([]T, error) {
+	var values []T
+	err := iterArray(array, func(i int, value safejs.Value) (bool, error) {
+		converted, err := convert(value)
+		if err != nil {
+			return false, fmt.Errorf("array element %d: %w", i, err)
+		}
+		values = append(values, converted)
+		return true, nil
+	})
+	return values, err
+}
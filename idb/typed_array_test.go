@@ -0,0 +1,60 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestArrayRequestTyped(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("strings", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("binary", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "strings", "binary")
+	assert.NoError(t, err)
+	strings, err := txn.ObjectStore("strings")
+	assert.NoError(t, err)
+	binary, err := txn.ObjectStore("binary")
+	assert.NoError(t, err)
+	for i, key := range []string{"b", "a", "c"} {
+		_, err := strings.PutKeyValue(key, i)
+		assert.NoError(t, err)
+		_, err = binary.PutBinaryKey([]byte(key), safejs.Safe(js.ValueOf(i)))
+		assert.NoError(t, err)
+	}
+
+	keysReq, err := strings.GetAllKeys()
+	assert.NoError(t, err)
+	keys, err := keysReq.Strings().Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+
+	valuesReq, err := strings.GetAll()
+	assert.NoError(t, err)
+	values, err := valuesReq.Ints().Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 0, 2}, values)
+
+	binaryReq, err := binary.GetAllKeys()
+	assert.NoError(t, err)
+	binaryKeys, err := binaryReq.Bytes().Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, binaryKeys)
+
+	valuesReq, err = strings.GetAll()
+	assert.NoError(t, err)
+	_, err = valuesReq.Strings().Await(ctx)
+	assert.Error(t, err)
+	assert.NoError(t, txn.Await(ctx))
+}
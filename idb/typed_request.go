@@ -0,0 +1,41 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+
+	"github.com/hack-pad/safejs"
+)
+
+// TypedRequest is a Request whose result is converted to a Go value of type T.
+type TypedRequest[T any] struct {
+	*Request
+	convert func(result safejs.Value) (T, error)
+}
+
+// RequestOf wraps req to convert its result with convert, so Result and Await return Go values.
+func RequestOf[T any](req *Request, convert func(result safejs.Value) (T, error)) *TypedRequest[T] {
+	return &TypedRequest[T]{Request: req, convert: convert}
+}
+
+// Result returns the converted result of the request. If the request failed and the result is not available, an error is returned.
+func (r *TypedRequest[T]) Result() (T, error) {
+	result, err := r.Request.Result()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return r.convert(result)
+}
+
+// Await waits for success or failure, then returns the converted results.
+func (r *TypedRequest[T]) Await(ctx context.Context) (T, error) {
+	result, err := r.Request.Await(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return r.convert(result)
+}
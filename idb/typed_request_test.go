@@ -0,0 +1,47 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestRequestOf(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue("greeting", "hello")
+	assert.NoError(t, err)
+
+	req, err := store.GetValue("greeting")
+	assert.NoError(t, err)
+	typed := RequestOf(req, func(result safejs.Value) (string, error) {
+		return result.String()
+	})
+	greeting, err := typed.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", greeting)
+	greeting, err = typed.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", greeting)
+
+	req, err = store.GetValue("greeting")
+	assert.NoError(t, err)
+	_, err = RequestOf(req, func(result safejs.Value) (int, error) {
+		return result.Int()
+	}).Await(ctx)
+	assert.Error(t, err)
+	assert.NoError(t, txn.Await(ctx))
+}
@@ -0,0 +1,176 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hack-pad/safejs"
+)
+
+// DuplicateError is returned by ObjectStore.CheckUnique and CheckUniqueValue when a key is already used in a unique index.
+// It matches a ConstraintError DOMException with errors.Is, the same as the error the write itself would fail with.
+type DuplicateError struct {
+	// Index is the name of the unique index.
+	Index string
+	// Key is the duplicated index key.
+	Key safejs.Value
+	// ExistingPrimaryKey is the primary key of the record already using Key.
+	ExistingPrimaryKey safejs.Value
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate key %s in unique index %q, already used by the record with primary key %s", keyString(e.Key), e.Index, keyString(e.ExistingPrimaryKey))
+}
+
+// Is returns true if target is a ConstraintError DOMException. Use 'errors.Is()' to call it.
+func (e *DuplicateError) Is(target error) bool {
+	return ErrConstraint.Is(target)
+}
+
+// keyString formats key for error messages.
+func keyString(key safejs.Value) string {
+	if keyType, ok := safeType(key); ok && keyType == safejs.TypeString {
+		s, err := key.String()
+		if err == nil {
+			return fmt.Sprintf("%q", s)
+		}
+	}
+	s, err := safejs.Global().Call("String", key)
+	if err != nil {
+		return "<invalid key>"
+	}
+	str, _ := s.String()
+	return str
+}
+
+// CheckUnique returns a *DuplicateError if a record in this store already uses key in the unique index named indexName.
+// Use it to report a friendly error, like for form validation, before a write fails with a bare ConstraintError.
+// Check and write within the same read-write transaction, so no other write can take the key in between.
+func (o *ObjectStore) CheckUnique(ctx context.Context, indexName string, key safejs.Value) error {
+	return o.checkUnique(ctx, indexName, key, safejs.Undefined())
+}
+
+// CheckUniqueValue checks value against every unique index of this store before it's put at primaryKey, and returns a *DuplicateError for the first index key already in use.
+// The record at primaryKey itself doesn't count as a duplicate, since putting value replaces it. For stores with in-line keys, pass an undefined primaryKey to read it from value.
+func (o *ObjectStore) CheckUniqueValue(ctx context.Context, primaryKey, value safejs.Value) error {
+	if primaryKey.IsUndefined() {
+		keyPath, err := o.TypedKeyPath()
+		if err != nil {
+			return err
+		}
+		if keyPath.Kind() != KeyPathNone {
+			primaryKey, _, err = valueKey(value, keyPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	indexNames, err := o.IndexNames()
+	if err != nil {
+		return err
+	}
+	for _, indexName := range indexNames {
+		index, err := o.Index(indexName)
+		if err != nil {
+			return err
+		}
+		unique, err := index.Unique()
+		if err != nil {
+			return err
+		}
+		if !unique {
+			continue
+		}
+		keyPath, err := index.TypedKeyPath()
+		if err != nil {
+			return err
+		}
+		multiEntry, err := index.MultiEntry()
+		if err != nil {
+			return err
+		}
+		key, ok, err := valueKey(value, keyPath)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue // value isn't in the index
+		}
+		keys := []safejs.Value{key}
+		if isArray, _ := key.InstanceOf(jsArray); multiEntry && isArray {
+			keys = nil
+			err := iterArray(key, func(_ int, entry safejs.Value) (bool, error) {
+				keys = append(keys, entry)
+				return true, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, key := range keys {
+			if !validKey(key) {
+				continue // invalid keys are left out of the index
+			}
+			if err := o.checkUnique(ctx, indexName, key, primaryKey); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkUnique returns a *DuplicateError if a record other than the one at primaryKey uses key in the index. primaryKey may be undefined.
+func (o *ObjectStore) checkUnique(ctx context.Context, indexName string, key, primaryKey safejs.Value) error {
+	index, err := o.Index(indexName)
+	if err != nil {
+		return err
+	}
+	req, err := index.base.GetKey(key)
+	if err != nil {
+		return err
+	}
+	existing, err := req.Await(ctx)
+	if err != nil || existing.IsUndefined() {
+		return err
+	}
+	if !primaryKey.IsUndefined() {
+		compare, err := compareKeys(existing, primaryKey)
+		if err != nil || compare == 0 {
+			return err
+		}
+	}
+	return &DuplicateError{Index: indexName, Key: key, ExistingPrimaryKey: existing}
+}
+
+// valueKey extracts the key at keyPath from value, like an object store or index does. Returns false if value has nothing at the key path.
+func valueKey(value safejs.Value, keyPath KeyPath) (safejs.Value, bool, error) {
+	if keyPath.Kind() == KeyPathSingle {
+		return valuePathKey(value, keyPath.Path())
+	}
+	var parts []interface{}
+	for _, path := range keyPath.Paths() {
+		part, ok, err := valuePathKey(value, path)
+		if err != nil || !ok {
+			return safejs.Undefined(), false, err
+		}
+		parts = append(parts, part)
+	}
+	key, err := safejs.ValueOf(parts)
+	return key, err == nil, err
+}
+
+func valuePathKey(value safejs.Value, path string) (safejs.Value, bool, error) {
+	if path == "" {
+		return value, !value.IsUndefined(), nil
+	}
+	return getValuePath(value, path)
+}
+
+// validKey returns true if key is a valid IndexedDB key.
+func validKey(key safejs.Value) bool {
+	_, err := compareKeys(key, key)
+	return err == nil
+}
@@ -0,0 +1,74 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestObjectStoreCheckUnique(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("users", ObjectStoreOptions{KeyPath: NewKeyPath("id")})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("email", NewKeyPath("email"), IndexOptions{Unique: true})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("name", NewKeyPath("name"), IndexOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("aliases", NewKeyPath("aliases"), IndexOptions{Unique: true, MultiEntry: true})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "users")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("users")
+	assert.NoError(t, err)
+	user, err := ValueOf(map[string]interface{}{"id": 1, "email": "a@example.com", "name": "A", "aliases": []interface{}{"a", "alpha"}})
+	assert.NoError(t, err)
+	_, err = store.Put(user)
+	assert.NoError(t, err)
+
+	email, err := ValueOf("a@example.com")
+	assert.NoError(t, err)
+	err = store.CheckUnique(ctx, "email", email)
+	var duplicate *DuplicateError
+	assert.Equal(t, true, errors.As(err, &duplicate))
+	assert.Equal(t, "email", duplicate.Index)
+	existing, err := duplicate.ExistingPrimaryKey.Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, existing)
+	assert.ErrorIs(t, duplicate, NewDOMException("ConstraintError"))
+
+	otherEmail, err := ValueOf("b@example.com")
+	assert.NoError(t, err)
+	assert.NoError(t, store.CheckUnique(ctx, "email", otherEmail))
+
+	for _, tc := range []struct {
+		description string
+		value       map[string]interface{}
+		index       string
+	}{
+		{"updating the same record", map[string]interface{}{"id": 1, "email": "a@example.com", "name": "A", "aliases": []interface{}{"a"}}, ""},
+		{"new record", map[string]interface{}{"id": 2, "email": "b@example.com", "name": "A"}, ""},
+		{"duplicate email", map[string]interface{}{"id": 2, "email": "a@example.com"}, "email"},
+		{"duplicate alias", map[string]interface{}{"id": 2, "aliases": []interface{}{"beta", "alpha"}}, "aliases"},
+	} {
+		value, err := ValueOf(tc.value)
+		assert.NoError(t, err)
+		err = store.CheckUniqueValue(ctx, safejs.Undefined(), value)
+		if tc.index == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		var duplicate *DuplicateError
+		assert.Equal(t, true, errors.As(err, &duplicate))
+		assert.Equal(t, tc.index, duplicate.Index)
+	}
+	assert.NoError(t, txn.Await(ctx))
+}
@@ -0,0 +1,67 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hack-pad/safejs"
+)
+
+// Upsert reads the record at key, calls merge with its value, then stores the merged value at key, all within this store's transaction. Returns the merged value.
+// merge is called with an undefined value if no record exists. It runs while the transaction is active, so it must not wait on anything other than this transaction.
+// If the store uses in-line keys, the merged value must contain key at the store's key path.
+func (o *ObjectStore) Upsert(ctx context.Context, key safejs.Value, merge func(existing safejs.Value) (safejs.Value, error)) (safejs.Value, error) {
+	keyPath, err := o.TypedKeyPath()
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	getReq, err := o.Get(key)
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	existing, err := getReq.Await(ctx)
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	merged, err := merge(existing)
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	var putReq *Request
+	if keyPath.Kind() == KeyPathNone {
+		putReq, err = o.PutKey(key, merged)
+	} else {
+		putReq, err = o.Put(merged)
+	}
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	_, err = putReq.Await(ctx)
+	return merged, err
+}
+
+// Increment adds delta to the number stored at key, within this store's transaction, and returns the new number. A missing record counts as 0.
+// Returns an error if the record isn't a number. The store must use out-of-line keys.
+func (o *ObjectStore) Increment(ctx context.Context, key safejs.Value, delta float64) (float64, error) {
+	merged, err := o.Upsert(ctx, key, func(existing safejs.Value) (safejs.Value, error) {
+		n := 0.0
+		if !existing.IsUndefined() {
+			if existing.Type() != safejs.TypeNumber {
+				return safejs.Undefined(), fmt.Errorf("cannot increment record of type %s", existing.Type())
+			}
+			var err error
+			n, err = existing.Float()
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+		}
+		return safejs.ValueOf(n + delta)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return merged.Float()
+}
@@ -0,0 +1,114 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestObjectStoreUpsert(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("counts", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("inline", ObjectStoreOptions{KeyPath: NewKeyPath("id")})
+		assert.NoError(t, err)
+	})
+	increment := func(existing safejs.Value) (safejs.Value, error) {
+		n := 0
+		if !existing.IsUndefined() {
+			var err error
+			n, err = existing.Int()
+			if err != nil {
+				return safejs.Undefined(), err
+			}
+		}
+		return ValueOf(n + 1)
+	}
+	key, err := ValueOf("visits")
+	assert.NoError(t, err)
+
+	txn, err := db.Transaction(TransactionReadWrite, "counts")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("counts")
+	assert.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		merged, err := store.Upsert(ctx, key, increment)
+		assert.NoError(t, err)
+		n, err := merged.Int()
+		assert.NoError(t, err)
+		assert.Equal(t, i, n)
+	}
+	mergeErr := errors.New("merge failed")
+	_, err = store.Upsert(ctx, key, func(safejs.Value) (safejs.Value, error) {
+		return safejs.Undefined(), mergeErr
+	})
+	assert.ErrorIs(t, err, mergeErr)
+	req, err := store.Get(key)
+	assert.NoError(t, err)
+	value, err := req.Await(ctx)
+	assert.NoError(t, err)
+	n, err := value.Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.NoError(t, txn.Await(ctx))
+
+	txn, err = db.Transaction(TransactionReadWrite, "inline")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("inline")
+	assert.NoError(t, err)
+	id, err := ValueOf(1)
+	assert.NoError(t, err)
+	_, err = store.Upsert(ctx, id, func(existing safejs.Value) (safejs.Value, error) {
+		assert.Equal(t, true, existing.IsUndefined())
+		return ValueOf(map[string]interface{}{"id": 1, "name": "first"})
+	})
+	assert.NoError(t, err)
+	req, err = store.Get(id)
+	assert.NoError(t, err)
+	value, err = req.Await(ctx)
+	assert.NoError(t, err)
+	name, err := value.Get("name")
+	assert.NoError(t, err)
+	nameString, err := name.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "first", nameString)
+	assert.NoError(t, txn.Await(ctx))
+}
+
+func TestObjectStoreIncrement(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("counts", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "counts")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("counts")
+	assert.NoError(t, err)
+
+	key, err := ValueOf("visits")
+	assert.NoError(t, err)
+	n, err := store.Increment(ctx, key, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, n)
+	n, err = store.Increment(ctx, key, -0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, n)
+
+	notNumber, err := ValueOf("name")
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue("name", "some name")
+	assert.NoError(t, err)
+	_, err = store.Increment(ctx, notNumber, 1)
+	assert.Error(t, err)
+	assert.NoError(t, txn.Await(ctx))
+}
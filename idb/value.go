@@ -0,0 +1,109 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"fmt"
+	"reflect"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	safeJSValueType = reflect.TypeOf(safejs.Value{})
+	jsValueType     = reflect.TypeOf(js.Value{})
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// ValueOf converts a native Go value into a JS value suitable for storing in an object store or using as a key.
+//
+// Supported values are nil, bools, strings, integers, floats, time.Time (as a Date), []byte (as a Uint8Array), slices and arrays (as Arrays), maps with string keys (as Objects), pointers to any of these, and existing safejs.Value or js.Value values.
+// Returns an error for any other type, like structs, channels, or functions.
+//
+// To convert other types, like maps with non-string keys or big.Int, use a Codec with TypeAdapters.
+func ValueOf(value interface{}) (safejs.Value, error) {
+	return (*Codec)(nil).ValueOf(value)
+}
+
+// plainValueOf converts value into a tree of types accepted by js.ValueOf
+func (c *Codec) plainValueOf(value reflect.Value) (interface{}, error) {
+	if !value.IsValid() {
+		return nil, nil
+	}
+	if adapter, ok := c.goAdapter(value.Type()); ok {
+		jsValue, err := adapter.ToJS(c, value.Interface())
+		return safejs.Unsafe(jsValue), err
+	}
+	switch value.Type() {
+	case safeJSValueType:
+		return safejs.Unsafe(value.Interface().(safejs.Value)), nil
+	case jsValueType:
+		return value.Interface().(js.Value), nil
+	case timeType:
+		date, err := TimeKey(value.Interface().(time.Time))
+		return safejs.Unsafe(date), err
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return value.Bool(), nil
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	case reflect.Interface, reflect.Pointer:
+		if value.IsNil() {
+			return nil, nil
+		}
+		return c.plainValueOf(value.Elem())
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil, nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			array, err := BytesValue(value.Bytes())
+			return safejs.Unsafe(array), err
+		}
+		return c.plainArrayOf(value)
+	case reflect.Array:
+		return c.plainArrayOf(value)
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s: keys must be strings", value.Type().Key())
+		}
+		if value.IsNil() {
+			return nil, nil
+		}
+		object := make(map[string]interface{}, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			elem, err := c.plainValueOf(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			object[iter.Key().String()] = elem
+		}
+		return object, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", value.Type())
+	}
+}
+
+func (c *Codec) plainArrayOf(value reflect.Value) (interface{}, error) {
+	array := make([]interface{}, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		elem, err := c.plainValueOf(value.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, elem)
+	}
+	return array, nil
+}
@@ -0,0 +1,100 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestValueOf(t *testing.T) {
+	t.Parallel()
+	type myInt int
+	str := "pointer"
+	for _, tc := range []struct {
+		name       string
+		value      interface{}
+		expectJSON string
+		expectErr  bool
+	}{
+		{name: "nil", value: nil, expectJSON: "null"},
+		{name: "bool", value: true, expectJSON: "true"},
+		{name: "string", value: "hello", expectJSON: `"hello"`},
+		{name: "int", value: 42, expectJSON: "42"},
+		{name: "named int", value: myInt(7), expectJSON: "7"},
+		{name: "float", value: 1.5, expectJSON: "1.5"},
+		{name: "pointer", value: &str, expectJSON: `"pointer"`},
+		{name: "string slice", value: []string{"a", "b"}, expectJSON: `["a","b"]`},
+		{name: "int array", value: [2]int{1, 2}, expectJSON: "[1,2]"},
+		{name: "nested map", value: map[string]interface{}{"n": map[string][]int{"a": {1}}}, expectJSON: `{"n":{"a":[1]}}`},
+		{name: "bytes", value: []byte{1, 2}, expectJSON: `{"0":1,"1":2}`},
+		{name: "safejs value", value: safejs.Safe(js.ValueOf("safe")), expectJSON: `"safe"`},
+		{name: "js value in slice", value: []interface{}{js.ValueOf(1)}, expectJSON: "[1]"},
+		{name: "struct", value: struct{ A int }{A: 1}, expectErr: true},
+		{name: "int map keys", value: map[int]string{1: "a"}, expectErr: true},
+		{name: "func in slice", value: []interface{}{func() {}}, expectErr: true},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			value, err := ValueOf(tc.value)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectJSON, jsonString(t, value))
+		})
+	}
+}
+
+func TestObjectStoreNativeValues(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("myindex", NewKeyPath("name"), IndexOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+
+	_, err = store.AddKeyValue("a", map[string]interface{}{"name": "Ada", "tags": []string{"math"}})
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue([]interface{}{"b", 2}, map[string]string{"name": "Bob"})
+	assert.NoError(t, err)
+	_, err = store.PutKeyValue("c", struct{}{})
+	assert.Error(t, err)
+
+	req, err := store.GetValue("a")
+	assert.NoError(t, err)
+	value, err := req.Await(ctx)
+	assert.NoError(t, err)
+	tags, err := value.Get("tags")
+	assert.NoError(t, err)
+	assert.Equal(t, `["math"]`, jsonString(t, tags))
+
+	index, err := store.Index("myindex")
+	assert.NoError(t, err)
+	req, err = index.GetValue("Bob")
+	assert.NoError(t, err)
+	value, err = req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Bob"}`, jsonString(t, value))
+
+	_, err = store.DeleteValue([]interface{}{"b", 2})
+	assert.NoError(t, err)
+	countReq, err := store.Count()
+	assert.NoError(t, err)
+	count, err := countReq.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), count)
+}
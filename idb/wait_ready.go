@@ -0,0 +1,75 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+// readyPollInterval is how often WaitReady nudges IndexedDB until it responds.
+const readyPollInterval = 100 * time.Millisecond
+
+// WaitReady waits until IndexedDB responds, working around a Safari bug where the first open after a page load can hang forever.
+// It lists the databases every 100 milliseconds until one of the listings completes, which wakes up Safari's IndexedDB. Other browsers respond to the first listing right away.
+// Returns nil right away if listing databases isn't supported. Set OpenOptions.WaitReady to call it before opening.
+func (f *Factory) WaitReady(ctx context.Context) error {
+	databases, err := f.jsFactory.Get("databases")
+	if err != nil {
+		return err
+	}
+	if databases.Type() != safejs.TypeFunction {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	settled := make(chan struct{}, 1)
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		promise, err := f.jsFactory.Call("databases")
+		if err != nil {
+			return tryAsDOMException(err)
+		}
+		go func() {
+			_, _ = awaitPromise(ctx, promise) // a rejected listing also shows IndexedDB responds
+			select {
+			case settled <- struct{}{}:
+			default:
+			}
+		}()
+		select {
+		case <-settled:
+			return ctx.Err()
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Ping checks the connection responds by counting the records of an object store in a short read-only transaction.
+// Use it with a ctx deadline to detect a hung connection, then close and reopen it. Returns nil if the database has no object stores.
+func (db *Database) Ping(ctx context.Context) error {
+	storeNames, err := db.ObjectStoreNames()
+	if err != nil || len(storeNames) == 0 {
+		return err
+	}
+	txn, err := db.Transaction(TransactionReadOnly, storeNames[0])
+	if err != nil {
+		return err
+	}
+	store, err := txn.ObjectStore(storeNames[0])
+	if err != nil {
+		return err
+	}
+	req, err := store.Count()
+	if err != nil {
+		return err
+	}
+	_, err = req.Await(ctx)
+	return err
+}
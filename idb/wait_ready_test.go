@@ -0,0 +1,37 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb/internal/assert"
+)
+
+func TestFactoryWaitReady(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, Global().WaitReady(ctx))
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	assert.ErrorIs(t, Global().WaitReady(canceled), context.Canceled)
+}
+
+func TestDatabasePing(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	assert.NoError(t, db.Ping(ctx))
+
+	empty := testDB(t, func(db *Database) {})
+	assert.NoError(t, empty.Ping(ctx))
+}
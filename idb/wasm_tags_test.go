@@ -60,7 +60,11 @@ func isJSWasm(expr constraint.Expr) bool {
 	switch expr := expr.(type) {
 	case *constraint.AndExpr:
 		x, y := expr.X.String(), expr.Y.String()
-		return (x == "js" && y == "wasm") || (x == "wasm" && y == "js")
+		if (x == "js" && y == "wasm") || (x == "wasm" && y == "js") {
+			return true
+		}
+		// allow further constraints, like a minimum Go version
+		return isJSWasm(expr.X) || isJSWasm(expr.Y)
 	default:
 		return false
 	}
@@ -0,0 +1,106 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package idbtest provides helpers for testing and benchmarking code which uses IndexedDB.
+package idbtest
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+const defaultBatchSize = 1000
+
+// KeyPattern is the order of the keys generated by Generate.
+type KeyPattern int
+
+const (
+	// KeySequential generates the number keys 0 through Count-1, in ascending order. Each write appends to the end of the store.
+	KeySequential KeyPattern = iota
+	// KeyReverse generates the number keys Count-1 through 0, in descending order. Each write lands before every existing key.
+	KeyReverse
+	// KeyRandom generates random 32 character hex string keys, so writes land all over the store.
+	KeyRandom
+)
+
+// GenSpec describes the synthetic records written by Generate.
+type GenSpec struct {
+	// Count is the number of records to write.
+	Count int
+	// ValueSize is the number of random bytes in each record's value, stored as a Uint8Array.
+	ValueSize int
+	// KeyPattern is the order of the generated keys.
+	KeyPattern KeyPattern
+	// Seed seeds the random keys and values, so runs with the same spec write the same records.
+	Seed int64
+	// BatchSize is the number of records written per transaction. Defaults to 1000.
+	BatchSize int
+}
+
+// Generate fills the object store named storeName with synthetic records, as fast as possible. Use it to benchmark stores and reproduce performance issues at scale.
+// The store must use out-of-line keys. Records are written with ObjectStore.PutMany in transactions of spec.BatchSize records, so existing records with the same keys are overwritten.
+func Generate(ctx context.Context, db *idb.Database, storeName string, spec GenSpec) error {
+	if spec.Count < 0 || spec.ValueSize < 0 || spec.BatchSize < 0 {
+		return errors.New("count, value size, and batch size must not be negative")
+	}
+	batchSize := spec.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+	random := rand.New(rand.NewSource(spec.Seed)) // nolint:gosec // Random keys and values only need to be reproducible, not secure.
+	for start := 0; start < spec.Count; start += batchSize {
+		end := start + batchSize
+		if end > spec.Count {
+			end = spec.Count
+		}
+		keys := make([]safejs.Value, 0, end-start)
+		values := make([]safejs.Value, 0, end-start)
+		for i := start; i < end; i++ {
+			key, err := generateKey(random, spec, i)
+			if err != nil {
+				return err
+			}
+			value := make([]byte, spec.ValueSize)
+			_, _ = random.Read(value)
+			jsValue, err := idb.BytesValue(value)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			values = append(values, jsValue)
+		}
+
+		txn, err := db.Transaction(idb.TransactionReadWrite, storeName)
+		if err != nil {
+			return err
+		}
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return err
+		}
+		if err := store.PutMany(ctx, keys, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generateKey(random *rand.Rand, spec GenSpec, i int) (safejs.Value, error) {
+	switch spec.KeyPattern {
+	case KeySequential:
+		return idb.ValueOf(i)
+	case KeyReverse:
+		return idb.ValueOf(spec.Count - 1 - i)
+	case KeyRandom:
+		b := make([]byte, 16)
+		_, _ = random.Read(b)
+		return idb.ValueOf(hex.EncodeToString(b))
+	default:
+		return safejs.Undefined(), errors.New("unknown key pattern")
+	}
+}
@@ -0,0 +1,98 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idbtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	name := fmt.Sprintf("idbtest-%s-%d", t.Name(), time.Now().UnixNano())
+	dbReq, err := idb.Global().Open(ctx, name, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("test_store", idb.ObjectStoreOptions{})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := dbReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+
+	for _, tc := range []struct {
+		name string
+		spec GenSpec
+	}{
+		{name: "sequential", spec: GenSpec{Count: 25, ValueSize: 8, BatchSize: 10}},
+		{name: "reverse", spec: GenSpec{Count: 3, KeyPattern: KeyReverse}},
+		{name: "random", spec: GenSpec{Count: 25, ValueSize: 8, KeyPattern: KeyRandom, Seed: 1}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Generate(ctx, db, "test_store", tc.spec); err != nil {
+				t.Fatal(err)
+			}
+			txn, err := db.Transaction(idb.TransactionReadWrite, "test_store")
+			if err != nil {
+				t.Fatal(err)
+			}
+			store, err := txn.ObjectStore("test_store")
+			if err != nil {
+				t.Fatal(err)
+			}
+			countReq, err := store.Count()
+			if err != nil {
+				t.Fatal(err)
+			}
+			count, err := countReq.Await(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != uint(tc.spec.Count) {
+				t.Errorf("unexpected count: %d", count)
+			}
+			cursorReq, err := store.OpenCursor(idb.CursorNext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cursor, err := cursorReq.Await(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := cursor.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := idb.BytesFromValue(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(b) != tc.spec.ValueSize {
+				t.Errorf("unexpected value size: %d", len(b))
+			}
+			clearReq, err := store.Clear()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := clearReq.Await(ctx); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
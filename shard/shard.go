@@ -0,0 +1,368 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package shard spreads one keyspace across several databases, to stay clear of the size and performance cliffs some browsers hit with a single large database.
+//
+// Every key is hashed to one of the shards with a jump consistent hash, which spreads keys evenly over the shards.
+// Shard database names include the number of shards, so Reshard copies every record into a new set of databases.
+// Records with the same key always live in the same shard, and each transaction covers a single shard. Scans fan out over every shard.
+// Sharded object stores must use out-of-line keys.
+package shard
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+const defaultBatchSize = 1000
+
+var jsArray safejs.Value
+
+func init() {
+	var err error
+	jsArray, err = safejs.Global().Get("Array")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Name returns the name of the database holding shard i of the n shards of baseName.
+func Name(baseName string, i, n int) string {
+	return fmt.Sprintf("%s.shard-%d-of-%d", baseName, i, n)
+}
+
+// DB is a keyspace sharded across several databases.
+type DB struct {
+	baseName string
+	dbs      []*idb.Database
+}
+
+// New opens the n shard databases of baseName with factory, upgrading each of them to version with upgrader.
+func New(ctx context.Context, factory *idb.Factory, baseName string, n int, version uint, upgrader idb.Upgrader) (*DB, error) {
+	if n < 1 {
+		return nil, errors.New("number of shards must be at least 1")
+	}
+	d := &DB{baseName: baseName}
+	for i := 0; i < n; i++ {
+		req, err := factory.Open(ctx, Name(baseName, i, n), version, upgrader)
+		if err != nil {
+			_ = d.Close()
+			return nil, err
+		}
+		db, err := req.Await(ctx)
+		if err != nil {
+			_ = d.Close()
+			return nil, err
+		}
+		d.dbs = append(d.dbs, db)
+	}
+	return d, nil
+}
+
+// Delete deletes the n shard databases of baseName. Close any open DB for them first.
+func Delete(ctx context.Context, factory *idb.Factory, baseName string, n int) error {
+	for i := 0; i < n; i++ {
+		req, err := factory.DeleteDatabase(Name(baseName, i, n))
+		if err != nil {
+			return err
+		}
+		if err := req.Await(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes every shard database.
+func (d *DB) Close() error {
+	var errs []error
+	for _, db := range d.dbs {
+		errs = append(errs, db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// N returns the number of shards.
+func (d *DB) N() int {
+	return len(d.dbs)
+}
+
+// Database returns the database of shard i.
+func (d *DB) Database(i int) *idb.Database {
+	return d.dbs[i]
+}
+
+// Shard returns the shard holding key.
+func (d *DB) Shard(key safejs.Value) (int, error) {
+	h := fnv.New64a()
+	if err := hashKey(h, key); err != nil {
+		return 0, err
+	}
+	return jumpHash(h.Sum64(), len(d.dbs)), nil
+}
+
+// Transaction starts a transaction on the shard holding key.
+func (d *DB) Transaction(key safejs.Value, mode idb.TransactionMode, objectStoreName string, objectStoreNames ...string) (*idb.Transaction, error) {
+	i, err := d.Shard(key)
+	if err != nil {
+		return nil, err
+	}
+	return d.dbs[i].Transaction(mode, objectStoreName, objectStoreNames...)
+}
+
+// Get returns the value at key in the object store named storeName, or undefined if there is none.
+func (d *DB) Get(ctx context.Context, storeName string, key safejs.Value) (safejs.Value, error) {
+	txn, err := d.Transaction(key, idb.TransactionReadOnly, storeName)
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	req, err := store.Get(key)
+	if err != nil {
+		return safejs.Undefined(), err
+	}
+	return req.Await(ctx)
+}
+
+// Put stores value at key in the object store named storeName.
+func (d *DB) Put(ctx context.Context, storeName string, key, value safejs.Value) error {
+	txn, err := d.Transaction(key, idb.TransactionReadWrite, storeName)
+	if err != nil {
+		return err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return err
+	}
+	if _, err := store.PutKey(key, value); err != nil {
+		return err
+	}
+	return txn.AwaitComplete(ctx)
+}
+
+// Delete deletes the record at key from the object store named storeName.
+func (d *DB) Delete(ctx context.Context, storeName string, key safejs.Value) error {
+	txn, err := d.Transaction(key, idb.TransactionReadWrite, storeName)
+	if err != nil {
+		return err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Delete(key); err != nil {
+		return err
+	}
+	return txn.AwaitComplete(ctx)
+}
+
+// Count returns the number of records in the object store named storeName, across every shard.
+func (d *DB) Count(ctx context.Context, storeName string) (uint, error) {
+	var total uint
+	for _, db := range d.dbs {
+		txn, err := db.Transaction(idb.TransactionReadOnly, storeName)
+		if err != nil {
+			return 0, err
+		}
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return 0, err
+		}
+		req, err := store.Count()
+		if err != nil {
+			return 0, err
+		}
+		count, err := req.Await(ctx)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// Scan calls fn with each record in the object store named storeName, one shard after another. Records are in key order within each shard, but not across shards.
+// Records are read in pages, each in its own transaction, so fn may make requests of its own and take as long as it needs.
+func (d *DB) Scan(ctx context.Context, storeName string, fn func(key, value safejs.Value) error) error {
+	for _, db := range d.dbs {
+		err := scanDB(ctx, db, storeName, func(keys, values []safejs.Value) error {
+			for i := range keys {
+				if err := fn(keys[i], values[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanDB(ctx context.Context, db *idb.Database, storeName string, fn func(keys, values []safejs.Value) error) error {
+	afterKey := safejs.Undefined()
+	for {
+		txn, err := db.Transaction(idb.TransactionReadOnly, storeName)
+		if err != nil {
+			return err
+		}
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return err
+		}
+		page, err := store.GetPage(ctx, afterKey, defaultBatchSize, idb.CursorNext)
+		if err != nil {
+			return err
+		}
+		if len(page.Keys) > 0 {
+			if err := fn(page.Keys, page.Values); err != nil {
+				return err
+			}
+		}
+		if page.NextKey.IsUndefined() {
+			return nil
+		}
+		afterKey = page.NextKey
+	}
+}
+
+// Reshard copies every record of the object stores named storeNames from src to the shards of dst, which usually has a different number of shards.
+// Writes to src during Reshard may be missed. Once it returns, switch to dst, then remove src with Delete.
+func Reshard(ctx context.Context, src, dst *DB, storeNames ...string) error {
+	for _, storeName := range storeNames {
+		for _, db := range src.dbs {
+			err := scanDB(ctx, db, storeName, func(keys, values []safejs.Value) error {
+				return dst.putMany(ctx, storeName, keys, values)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// putMany stores the records in their shards, with one transaction per shard.
+func (d *DB) putMany(ctx context.Context, storeName string, keys, values []safejs.Value) error {
+	shardKeys := make([][]safejs.Value, len(d.dbs))
+	shardValues := make([][]safejs.Value, len(d.dbs))
+	for i, key := range keys {
+		shard, err := d.Shard(key)
+		if err != nil {
+			return err
+		}
+		shardKeys[shard] = append(shardKeys[shard], key)
+		shardValues[shard] = append(shardValues[shard], values[i])
+	}
+	for shard, db := range d.dbs {
+		if len(shardKeys[shard]) == 0 {
+			continue
+		}
+		txn, err := db.Transaction(idb.TransactionReadWrite, storeName)
+		if err != nil {
+			return err
+		}
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return err
+		}
+		if err := store.PutMany(ctx, shardKeys[shard], shardValues[shard]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashKey writes a canonical encoding of key to h, so equal keys hash equally.
+func hashKey(h hash.Hash, key safejs.Value) error {
+	var b [8]byte
+	switch key.Type() {
+	case safejs.TypeNumber:
+		n, err := key.Float()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			n = 0 // -0 and 0 are the same key
+		}
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(n))
+		_, _ = h.Write([]byte{'n'})
+		_, _ = h.Write(b[:])
+		return nil
+	case safejs.TypeString:
+		s, err := key.String()
+		if err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint64(b[:], uint64(len(s)))
+		_, _ = h.Write([]byte{'s'})
+		_, _ = h.Write(b[:])
+		_, _ = h.Write([]byte(s))
+		return nil
+	case safejs.TypeObject:
+	default:
+		return fmt.Errorf("unsupported key type: %s", key.Type())
+	}
+
+	if t, err := idb.KeyTime(key); err == nil {
+		binary.BigEndian.PutUint64(b[:], uint64(t.UnixMilli()))
+		_, _ = h.Write([]byte{'d'})
+		_, _ = h.Write(b[:])
+		return nil
+	}
+	isArray, err := key.InstanceOf(jsArray)
+	if err != nil {
+		return err
+	}
+	if !isArray {
+		bytes, err := idb.BinaryKeyBytes(key)
+		if err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint64(b[:], uint64(len(bytes)))
+		_, _ = h.Write([]byte{'b'})
+		_, _ = h.Write(b[:])
+		_, _ = h.Write(bytes)
+		return nil
+	}
+	length, err := key.Length()
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint64(b[:], uint64(length))
+	_, _ = h.Write([]byte{'a'})
+	_, _ = h.Write(b[:])
+	for i := 0; i < length; i++ {
+		element, err := key.Index(i)
+		if err != nil {
+			return err
+		}
+		if err := hashKey(h, element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jumpHash maps key to one of n buckets, moving only 1/n of keys when n grows by one. See "A Fast, Minimal Memory, Consistent Hash Algorithm" by Lamping and Veach.
+func jumpHash(key uint64, n int) int {
+	var b, j int64 = -1, 0
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
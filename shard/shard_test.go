@@ -0,0 +1,152 @@
+//go:build js && wasm
+// +build js,wasm
+
+package shard
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+func testShards(t *testing.T, baseName string, n int) *DB {
+	t.Helper()
+	ctx := context.Background()
+	db, err := New(ctx, idb.Global(), baseName, n, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("records", idb.ObjectStoreOptions{})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		_ = Delete(ctx, idb.Global(), baseName, n)
+	})
+	return db
+}
+
+func TestJumpHash(t *testing.T) {
+	t.Parallel()
+	// growing from n to n+1 buckets only moves keys into the new bucket
+	for key := uint64(0); key < 1000; key++ {
+		for n := 1; n < 10; n++ {
+			before, after := jumpHash(key*7919, n), jumpHash(key*7919, n+1)
+			if before < 0 || before >= n {
+				t.Fatalf("bucket %d out of range for %d buckets", before, n)
+			}
+			if after != before && after != n {
+				t.Fatalf("key %d moved from %d to %d when growing to %d buckets", key, before, after, n+1)
+			}
+		}
+	}
+}
+
+func TestHashKeyNegativeZero(t *testing.T) {
+	t.Parallel()
+	sum := func(n float64) uint64 {
+		h := fnv.New64a()
+		if err := hashKey(h, safejs.Safe(js.ValueOf(n))); err != nil {
+			t.Fatal(err)
+		}
+		return h.Sum64()
+	}
+	if sum(math.Copysign(0, -1)) != sum(0) {
+		t.Error("expected -0 and 0 to hash the same, since they're the same key")
+	}
+}
+
+func TestDB(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	baseName := fmt.Sprintf("shard-test-%d", time.Now().UnixNano())
+	db := testShards(t, baseName, 2)
+	jsValue := func(value interface{}) safejs.Value {
+		return safejs.Safe(js.ValueOf(value))
+	}
+
+	const count = 40
+	for i := 0; i < count; i++ {
+		if err := db.Put(ctx, "records", jsValue(fmt.Sprint("key", i)), jsValue(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	value, err := db.Get(ctx, "records", jsValue("key7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := value.Int(); err != nil || n != 7 {
+		t.Errorf("expected 7, got %v, %v", n, err)
+	}
+	if err := db.Delete(ctx, "records", jsValue("key7")); err != nil {
+		t.Fatal(err)
+	}
+	total, err := db.Count(ctx, "records")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != count-1 {
+		t.Errorf("expected %d records, got %d", count-1, total)
+	}
+
+	// every shard holds some of the records
+	for i := 0; i < db.N(); i++ {
+		txn, err := db.Database(i).Transaction(idb.TransactionReadOnly, "records")
+		if err != nil {
+			t.Fatal(err)
+		}
+		store, err := txn.ObjectStore("records")
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := store.Count()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := req.Await(ctx); err != nil || n == 0 {
+			t.Errorf("expected records in shard %d, got %d, %v", i, n, err)
+		}
+	}
+
+	scanned := func(db *DB) []int {
+		t.Helper()
+		var values []int
+		err := db.Scan(ctx, "records", func(key, value safejs.Value) error {
+			n, err := value.Int()
+			values = append(values, n)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Ints(values)
+		return values
+	}
+	before := scanned(db)
+	if len(before) != count-1 {
+		t.Errorf("expected to scan %d records, got %d", count-1, len(before))
+	}
+
+	resharded := testShards(t, baseName, 3)
+	if err := Reshard(ctx, db, resharded, "records"); err != nil {
+		t.Fatal(err)
+	}
+	if after := scanned(resharded); fmt.Sprint(after) != fmt.Sprint(before) {
+		t.Errorf("expected resharded records %v, got %v", before, after)
+	}
+	value, err = resharded.Get(ctx, "records", jsValue("key8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := value.Int(); err != nil || n != 8 {
+		t.Errorf("expected 8, got %v, %v", n, err)
+	}
+}
@@ -0,0 +1,408 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package tasks schedules jobs in an object store and runs them once they're due, like a job queue persisted in the browser.
+//
+// Schedule stores a task with a kind, a payload, and the time it should run. A Scheduler runs due tasks with the Handler registered for their kind.
+// Only one Scheduler runs tasks at a time across every tab sharing the database: the leader, which holds a lease renewed on each poll and before each task.
+//
+// Tasks run at least once. A task is claimed before it runs by pushing back its run time, and it's deleted only after its handler succeeds.
+// If the handler fails or the tab closes mid-run, the task runs again once the retry delay passes, so handlers should be idempotent.
+package tasks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	// StoreName is the name of the object store holding scheduled tasks, keyed by ID.
+	StoreName = "tasks"
+	// LeaseStoreName is the name of the object store holding the lease of the leading Scheduler.
+	LeaseStoreName = "tasks.lease"
+
+	runAtIndexName = "runAt"
+	leaseKey       = "leader"
+)
+
+const (
+	defaultPollInterval  = time.Second
+	defaultLeaseDuration = 10 * time.Second
+	defaultRetryDelay    = 30 * time.Second
+	defaultBatchSize     = 100
+)
+
+// CreateSchema creates the task and lease object stores. Call it once during an upgrade.
+func CreateSchema(db *idb.Database) error {
+	store, err := db.CreateObjectStore(StoreName, idb.ObjectStoreOptions{AutoIncrement: true})
+	if err != nil {
+		return err
+	}
+	if _, err := store.CreateIndex(runAtIndexName, idb.NewKeyPath("runAt"), idb.IndexOptions{}); err != nil {
+		return err
+	}
+	_, err = db.CreateObjectStore(LeaseStoreName, idb.ObjectStoreOptions{})
+	return err
+}
+
+// Task is a scheduled job.
+type Task struct {
+	ID      int
+	Kind    string
+	Payload safejs.Value
+	// RunAt is when the task was due.
+	RunAt time.Time
+	// Attempts is the number of times the task has started running, including the current run.
+	Attempts int
+}
+
+// Schedule stores a task of the given kind to run at runAt, and returns its ID. The payload must be storable with the structured clone algorithm.
+func Schedule(ctx context.Context, db *idb.Database, kind string, payload safejs.Value, runAt time.Time) (int, error) {
+	txn, err := db.Transaction(idb.TransactionReadWrite, StoreName)
+	if err != nil {
+		return 0, err
+	}
+	store, err := txn.ObjectStore(StoreName)
+	if err != nil {
+		return 0, err
+	}
+	value, err := taskValue(kind, payload, runAt, 0)
+	if err != nil {
+		return 0, err
+	}
+	req, err := store.Add(value)
+	if err != nil {
+		return 0, err
+	}
+	if err := txn.AwaitComplete(ctx); err != nil {
+		return 0, err
+	}
+	key, err := req.Request.Result()
+	if err != nil {
+		return 0, err
+	}
+	return key.Int()
+}
+
+// Cancel deletes the task with the given ID, if it hasn't run yet.
+func Cancel(ctx context.Context, db *idb.Database, id int) error {
+	txn, err := db.Transaction(idb.TransactionReadWrite, StoreName)
+	if err != nil {
+		return err
+	}
+	store, err := txn.ObjectStore(StoreName)
+	if err != nil {
+		return err
+	}
+	if _, err := store.DeleteValue(id); err != nil {
+		return err
+	}
+	return txn.AwaitComplete(ctx)
+}
+
+func taskValue(kind string, payload safejs.Value, runAt time.Time, attempts int) (safejs.Value, error) {
+	return idb.ValueOf(map[string]interface{}{
+		"kind":     kind,
+		"payload":  payload,
+		"runAt":    runAt,
+		"attempts": attempts,
+	})
+}
+
+func parseTask(key, value safejs.Value) (Task, error) {
+	id, err := key.Int()
+	if err != nil {
+		return Task{}, err
+	}
+	kind, err := value.Get("kind")
+	if err != nil {
+		return Task{}, err
+	}
+	kindString, err := kind.String()
+	if err != nil {
+		return Task{}, err
+	}
+	payload, err := value.Get("payload")
+	if err != nil {
+		return Task{}, err
+	}
+	runAt, err := value.Get("runAt")
+	if err != nil {
+		return Task{}, err
+	}
+	runAtTime, err := idb.KeyTime(runAt)
+	if err != nil {
+		return Task{}, err
+	}
+	attempts, err := value.Get("attempts")
+	if err != nil {
+		return Task{}, err
+	}
+	attemptsInt, err := attempts.Int()
+	if err != nil {
+		return Task{}, err
+	}
+	return Task{ID: id, Kind: kindString, Payload: payload, RunAt: runAtTime, Attempts: attemptsInt}, nil
+}
+
+// Handler runs a task. Returning an error runs the task again after the retry delay.
+//
+// Tasks are delivered at least once, so a handler may run more than once for the same task: after it fails, if the tab closes before the task is deleted,
+// or if it runs longer than the retry delay. Handlers should be idempotent.
+type Handler func(ctx context.Context, task Task) error
+
+// Options contains options for a Scheduler. Zero values use defaults.
+type Options struct {
+	// PollInterval is how often to look for due tasks. Defaults to 1 second.
+	PollInterval time.Duration
+	// LeaseDuration is how long a Scheduler stays leader without polling or starting a task. Defaults to 10 seconds.
+	LeaseDuration time.Duration
+	// RetryDelay is how long to wait before running a task again, if it fails or doesn't finish. It must be longer than handlers take to run. Defaults to 30 seconds.
+	RetryDelay time.Duration
+	// OnError is called when a task's handler fails, or no handler is registered for its kind.
+	OnError func(task Task, err error)
+}
+
+// Scheduler runs due tasks with registered handlers.
+type Scheduler struct {
+	db       *idb.Database
+	options  Options
+	owner    string
+	handlers map[string]Handler
+}
+
+// New returns a Scheduler running the tasks in db. Register handlers with Handle before running it.
+func New(db *idb.Database, options Options) (*Scheduler, error) {
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultPollInterval
+	}
+	if options.LeaseDuration <= 0 {
+		options.LeaseDuration = defaultLeaseDuration
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = defaultRetryDelay
+	}
+	owner := make([]byte, 16)
+	if _, err := rand.Read(owner); err != nil {
+		return nil, err
+	}
+	return &Scheduler{
+		db:       db,
+		options:  options,
+		owner:    hex.EncodeToString(owner),
+		handlers: make(map[string]Handler),
+	}, nil
+}
+
+// Handle registers handler to run tasks of the given kind.
+func (s *Scheduler) Handle(kind string, handler Handler) {
+	s.handlers[kind] = handler
+}
+
+// Run polls for due tasks and runs them until ctx is done. Each poll waits for the browser to be idle, where supported.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := waitIdle(ctx, s.options.PollInterval); err != nil {
+			return err
+		}
+		if _, err := s.RunDue(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs the tasks which are due, if this Scheduler is the leader, and returns the number which succeeded.
+// The lease is renewed before each task. If another Scheduler takes it over, RunDue stops early, and the tasks it claimed but didn't run are retried after the retry delay.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	leader, err := s.acquireLease(ctx)
+	if err != nil || !leader {
+		return 0, err
+	}
+	tasks, err := s.claimDue(ctx)
+	if err != nil {
+		return 0, err
+	}
+	succeeded := 0
+	for i, task := range tasks {
+		if i > 0 {
+			// handlers may take a while, so keep the lease from expiring mid-batch
+			leader, err := s.acquireLease(ctx)
+			if err != nil || !leader {
+				return succeeded, err
+			}
+		}
+		handler, ok := s.handlers[task.Kind]
+		if !ok {
+			s.onError(task, fmt.Errorf("no handler for task kind %q", task.Kind))
+			continue
+		}
+		if err := handler(ctx, task); err != nil {
+			s.onError(task, err)
+			continue
+		}
+		if err := Cancel(ctx, s.db, task.ID); err != nil {
+			return succeeded, err
+		}
+		succeeded++
+	}
+	return succeeded, nil
+}
+
+func (s *Scheduler) onError(task Task, err error) {
+	if s.options.OnError != nil {
+		s.options.OnError(task, err)
+	}
+}
+
+// acquireLease takes or renews the leader lease, unless another Scheduler holds it. Returns true if this Scheduler is the leader.
+func (s *Scheduler) acquireLease(ctx context.Context) (bool, error) {
+	txn, err := s.db.Transaction(idb.TransactionReadWrite, LeaseStoreName)
+	if err != nil {
+		return false, err
+	}
+	store, err := txn.ObjectStore(LeaseStoreName)
+	if err != nil {
+		return false, err
+	}
+	req, err := store.GetValue(leaseKey)
+	if err != nil {
+		return false, err
+	}
+	lease, err := req.Await(ctx)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if !lease.IsUndefined() {
+		owner, err := lease.Get("owner")
+		if err != nil {
+			return false, err
+		}
+		ownerString, err := owner.String()
+		if err != nil {
+			return false, err
+		}
+		expires, err := lease.Get("expires")
+		if err != nil {
+			return false, err
+		}
+		expiresTime, err := idb.KeyTime(expires)
+		if err != nil {
+			return false, err
+		}
+		if ownerString != s.owner && now.Before(expiresTime) {
+			return false, nil
+		}
+	}
+	_, err = store.PutKeyValue(leaseKey, map[string]interface{}{
+		"owner":   s.owner,
+		"expires": now.Add(s.options.LeaseDuration),
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, txn.AwaitComplete(ctx)
+}
+
+// claimDue pushes back the run time of due tasks by the retry delay and counts an attempt, then returns them.
+func (s *Scheduler) claimDue(ctx context.Context) ([]Task, error) {
+	txn, err := s.db.Transaction(idb.TransactionReadWrite, StoreName)
+	if err != nil {
+		return nil, err
+	}
+	store, err := txn.ObjectStore(StoreName)
+	if err != nil {
+		return nil, err
+	}
+	index, err := store.Index(runAtIndexName)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	due, err := idb.NewKeyRangeUpperBoundOf(now, false)
+	if err != nil {
+		return nil, err
+	}
+	req, err := index.OpenCursorRange(due, idb.CursorNext)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	err = req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+		key, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		task, err := parseTask(key, value)
+		if err != nil {
+			return err
+		}
+		task.Attempts++
+		claimed, err := taskValue(task.Kind, task.Payload, now.Add(s.options.RetryDelay), task.Attempts)
+		if err != nil {
+			return err
+		}
+		if _, err := cursor.Update(claimed); err != nil {
+			return err
+		}
+		tasks = append(tasks, task)
+		if len(tasks) == defaultBatchSize {
+			return idb.ErrCursorStopIter
+		}
+		return cursor.Continue()
+	})
+	if err != nil {
+		_ = txn.Abort()
+		return nil, err
+	}
+	return tasks, txn.AwaitComplete(ctx)
+}
+
+// waitIdle waits until the browser is idle, or until timeout passes. Returns immediately if requestIdleCallback isn't supported.
+func waitIdle(ctx context.Context, timeout time.Duration) error {
+	requestIdleCallback, err := safejs.Global().Get("requestIdleCallback")
+	if err != nil || requestIdleCallback.Type() != safejs.TypeFunction {
+		return err
+	}
+	idle := make(chan struct{})
+	callback, err := safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		close(idle)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer callback.Release()
+	options, err := safejs.ValueOf(map[string]interface{}{"timeout": timeout.Milliseconds()})
+	if err != nil {
+		return err
+	}
+	handle, err := safejs.Global().Call("requestIdleCallback", callback, options)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		_, _ = safejs.Global().Call("cancelIdleCallback", handle)
+		return ctx.Err()
+	}
+}
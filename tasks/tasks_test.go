@@ -0,0 +1,184 @@
+//go:build js && wasm
+// +build js,wasm
+
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+func testDB(t *testing.T) *idb.Database {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("tasks-test-%s-%d", t.Name(), time.Now().UnixNano())
+	dbReq, err := idb.Global().Open(ctx, name, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		return CreateSchema(db)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := dbReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+	return db
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	now := time.Now()
+	schedule := func(kind string, payload interface{}, runAt time.Time) int {
+		t.Helper()
+		id, err := Schedule(ctx, db, kind, safejs.Safe(js.ValueOf(payload)), runAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	schedule("send", "hello", now.Add(-time.Minute))
+	schedule("send", "later", now.Add(time.Hour))
+	flakyID := schedule("flaky", nil, now)
+	cancelledID := schedule("send", "cancelled", now)
+	if err := Cancel(ctx, db, cancelledID); err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []string
+	var failures []Task
+	scheduler, err := New(db, Options{
+		RetryDelay: 10 * time.Millisecond,
+		OnError: func(task Task, err error) {
+			failures = append(failures, task)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler.Handle("send", func(ctx context.Context, task Task) error {
+		payload, err := task.Payload.String()
+		sent = append(sent, payload)
+		return err
+	})
+	scheduler.Handle("flaky", func(ctx context.Context, task Task) error {
+		if task.Attempts < 2 {
+			return errors.New("try again")
+		}
+		return nil
+	})
+
+	succeeded, err := scheduler.RunDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if succeeded != 1 || len(sent) != 1 || sent[0] != "hello" {
+		t.Errorf("expected only the due task to be sent, got %d succeeded: %v", succeeded, sent)
+	}
+	if len(failures) != 1 || failures[0].ID != flakyID || failures[0].Attempts != 1 {
+		t.Errorf("expected the flaky task to fail on its first attempt, got %+v", failures)
+	}
+
+	// another tab can't run tasks while the lease is held
+	follower, err := New(db, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	follower.Handle("flaky", func(ctx context.Context, task Task) error {
+		t.Error("follower must not run tasks")
+		return nil
+	})
+	time.Sleep(50 * time.Millisecond) // wait for the retry delay
+	if succeeded, err := follower.RunDue(ctx); err != nil || succeeded != 0 {
+		t.Errorf("expected the follower to run nothing, got %d, %v", succeeded, err)
+	}
+
+	succeeded, err = scheduler.RunDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if succeeded != 1 {
+		t.Errorf("expected the flaky task to succeed on retry, got %d succeeded", succeeded)
+	}
+}
+
+func TestSchedulerRunDueLosesLease(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	for i := 0; i < 2; i++ {
+		if _, err := Schedule(ctx, db, "slow", safejs.Safe(js.ValueOf(i)), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	other, err := New(db, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler, err := New(db, Options{LeaseDuration: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := 0
+	scheduler.Handle("slow", func(ctx context.Context, task Task) error {
+		ran++
+		// outlive the lease, so another tab takes it over
+		time.Sleep(50 * time.Millisecond)
+		_, err := other.RunDue(ctx)
+		return err
+	})
+	succeeded, err := scheduler.RunDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if succeeded != 1 || ran != 1 {
+		t.Errorf("expected the batch to stop once the lease was lost, got %d succeeded of %d run", succeeded, ran)
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := testDB(t)
+	scheduler, err := New(db, Options{PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := make(chan Task, 1)
+	scheduler.Handle("ping", func(ctx context.Context, task Task) error {
+		ran <- task
+		cancel()
+		return nil
+	})
+	if _, err := Schedule(ctx, db, "ping", safejs.Null(), time.Now().Add(30*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := scheduler.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Run to stop when cancelled, got %v", err)
+	}
+	select {
+	case task := <-ran:
+		if task.Kind != "ping" || task.Attempts != 1 {
+			t.Errorf("unexpected task %+v", task)
+		}
+	default:
+		t.Error("expected the task to run")
+	}
+}
@@ -0,0 +1,377 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package textindex maintains a full-text inverted index over string fields of an object store's records, for offline search without external JavaScript libraries.
+//
+// Each indexed record's fields are split into lowercase terms. The index store holds a posting for every term of every record, keyed by [term, primary key], with the term's frequency in the record as its value.
+// Write records through Index.Put and Index.Delete, so the postings change in the same transaction as the records.
+package textindex
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+var jsArray safejs.Value
+
+func init() {
+	var err error
+	jsArray, err = safejs.Global().Get("Array")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// IndexStoreName returns the name of the object store holding the postings of storeName's text index.
+func IndexStoreName(storeName string) string {
+	return storeName + ".textindex"
+}
+
+// CreateIndexStore creates the object store holding the postings of storeName's text index. Call it during an upgrade, alongside creating storeName.
+func CreateIndexStore(db *idb.Database, storeName string) (*idb.ObjectStore, error) {
+	return db.CreateObjectStore(IndexStoreName(storeName), idb.ObjectStoreOptions{})
+}
+
+// Index is a full-text index over the string fields of an object store's records.
+type Index struct {
+	db        *idb.Database
+	storeName string
+	fields    []string
+}
+
+// New returns the full-text index of the object store named storeName, indexing the given fields. Fields are period-separated paths into record values, like key paths.
+// The store must use out-of-line keys, and its index store must exist. See CreateIndexStore.
+func New(db *idb.Database, storeName string, fields ...string) *Index {
+	return &Index{db: db, storeName: storeName, fields: fields}
+}
+
+// Put stores value at key in the object store and updates its postings. txn must be read-write and include the object store and its index store.
+func (x *Index) Put(ctx context.Context, txn *idb.Transaction, key, value safejs.Value) error {
+	store, postings, err := x.objectStores(txn)
+	if err != nil {
+		return err
+	}
+	if err := x.deletePostings(ctx, store, postings, key); err != nil {
+		return err
+	}
+	if _, err := store.PutKey(key, value); err != nil {
+		return err
+	}
+	frequencies, err := x.termFrequencies(value)
+	if err != nil {
+		return err
+	}
+	for term, frequency := range frequencies {
+		postingKey, err := jsArray.Call("of", term, key)
+		if err != nil {
+			return err
+		}
+		jsFrequency, err := safejs.ValueOf(frequency)
+		if err != nil {
+			return err
+		}
+		if _, err := postings.PutKey(postingKey, jsFrequency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete deletes the record at key from the object store, along with its postings. txn must be read-write and include the object store and its index store.
+func (x *Index) Delete(ctx context.Context, txn *idb.Transaction, key safejs.Value) error {
+	store, postings, err := x.objectStores(txn)
+	if err != nil {
+		return err
+	}
+	if err := x.deletePostings(ctx, store, postings, key); err != nil {
+		return err
+	}
+	_, err = store.Delete(key)
+	return err
+}
+
+func (x *Index) objectStores(txn *idb.Transaction) (store, postings *idb.ObjectStore, err error) {
+	store, err = txn.ObjectStore(x.storeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	postings, err = txn.ObjectStore(IndexStoreName(x.storeName))
+	return store, postings, err
+}
+
+// deletePostings deletes the postings of the record currently stored at key, found by tokenizing its value again.
+func (x *Index) deletePostings(ctx context.Context, store, postings *idb.ObjectStore, key safejs.Value) error {
+	req, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+	existing, err := req.Await(ctx)
+	if err != nil || existing.IsUndefined() {
+		return err
+	}
+	frequencies, err := x.termFrequencies(existing)
+	if err != nil {
+		return err
+	}
+	for term := range frequencies {
+		postingKey, err := jsArray.Call("of", term, key)
+		if err != nil {
+			return err
+		}
+		if _, err := postings.Delete(postingKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *Index) termFrequencies(value safejs.Value) (map[string]int, error) {
+	frequencies := make(map[string]int)
+	for _, field := range x.fields {
+		fieldValue := value
+		for _, name := range strings.Split(field, ".") {
+			if fieldValue.Type() != safejs.TypeObject {
+				fieldValue = safejs.Undefined()
+				break
+			}
+			var err error
+			fieldValue, err = fieldValue.Get(name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if fieldValue.Type() != safejs.TypeString {
+			continue
+		}
+		text, err := fieldValue.String()
+		if err != nil {
+			return nil, err
+		}
+		for _, term := range Tokenize(text) {
+			frequencies[term]++
+		}
+	}
+	return frequencies, nil
+}
+
+// Tokenize splits text into lowercase terms at every character which isn't a letter or digit.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SearchOptions contains options for Index.Search.
+type SearchOptions struct {
+	// Prefix matches the last query term as a prefix of indexed terms, for search as you type.
+	Prefix bool
+	// Limit is the maximum number of hits to return. If 0, returns all hits.
+	Limit int
+}
+
+// Hit is a record matching a search.
+type Hit struct {
+	PrimaryKey safejs.Value
+	// Score ranks the hit against the others. Higher scores match better.
+	Score float64
+}
+
+// Search returns the records containing every term of query, best matches first.
+// Hits are scored by how often each query term appears in the record, weighted toward terms which appear in fewer records. Ties are ordered by primary key.
+func (x *Index) Search(ctx context.Context, query string, options SearchOptions) ([]Hit, error) {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	var hits []Hit
+	err := idb.RetryTxn(ctx, x.db, idb.TransactionReadOnly, func(txn *idb.Transaction) error {
+		var err error
+		hits, err = x.search(ctx, txn, terms, options)
+		return err
+	}, x.storeName, IndexStoreName(x.storeName))
+	return hits, err
+}
+
+func (x *Index) search(ctx context.Context, txn *idb.Transaction, terms []string, options SearchOptions) ([]Hit, error) {
+	store, postings, err := x.objectStores(txn)
+	if err != nil {
+		return nil, err
+	}
+	countReq, err := store.Count()
+	if err != nil {
+		return nil, err
+	}
+	type termRequests struct {
+		keys, frequencies *idb.ArrayRequest
+	}
+	requests := make([]termRequests, 0, len(terms))
+	for i, term := range terms {
+		keyRange, err := termRange(term, options.Prefix && i == len(terms)-1)
+		if err != nil {
+			return nil, err
+		}
+		keysReq, err := postings.GetAllKeysRange(keyRange, 0)
+		if err != nil {
+			return nil, err
+		}
+		frequenciesReq, err := postings.GetAllRange(keyRange, 0)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, termRequests{keys: keysReq, frequencies: frequenciesReq})
+	}
+	count, err := countReq.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		hit   Hit
+		terms int // number of query terms matched
+	}
+	var matches []*match
+	matchesByID := make(map[string]*match)
+	for i, req := range requests {
+		keys, err := req.keys.Await(ctx)
+		if err != nil {
+			return nil, err
+		}
+		frequencies, err := req.frequencies.Await(ctx)
+		if err != nil {
+			return nil, err
+		}
+		termFrequencies := make(map[string]float64, len(keys))
+		primaryKeys := make(map[string]safejs.Value, len(keys))
+		var ids []string
+		for j, key := range keys {
+			primaryKey, err := key.Index(1)
+			if err != nil {
+				return nil, err
+			}
+			id, err := keyID(primaryKey)
+			if err != nil {
+				return nil, err
+			}
+			frequency, err := frequencies[j].Float()
+			if err != nil {
+				return nil, err
+			}
+			if _, seen := termFrequencies[id]; !seen {
+				ids = append(ids, id)
+				primaryKeys[id] = primaryKey
+			}
+			termFrequencies[id] += frequency
+		}
+		// rarer terms say more about a record, so weigh them higher
+		weight := math.Log(1 + float64(count)/float64(len(ids)+1))
+		for _, id := range ids {
+			m := matchesByID[id]
+			if m == nil {
+				if i > 0 {
+					continue // missing an earlier term
+				}
+				m = &match{hit: Hit{PrimaryKey: primaryKeys[id]}}
+				matchesByID[id] = m
+				matches = append(matches, m)
+			}
+			if m.terms == i {
+				m.terms++
+				m.hit.Score += termFrequencies[id] * weight
+			}
+		}
+	}
+
+	var hits []Hit
+	for _, m := range matches {
+		if m.terms == len(terms) {
+			hits = append(hits, m.hit)
+		}
+	}
+	factory, err := idb.LoadGlobal()
+	if err != nil {
+		return nil, err
+	}
+	var compareErr error
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		order, err := factory.Compare(hits[i].PrimaryKey, hits[j].PrimaryKey)
+		if err != nil && compareErr == nil {
+			compareErr = err
+		}
+		return order == idb.Less
+	})
+	if compareErr != nil {
+		return nil, compareErr
+	}
+	if options.Limit > 0 && len(hits) > options.Limit {
+		hits = hits[:options.Limit]
+	}
+	return hits, nil
+}
+
+// termRange returns the range of posting keys for term, or for every term starting with term if prefix is true.
+func termRange(term string, prefix bool) (*idb.KeyRange, error) {
+	lower, err := jsArray.Call("of", term)
+	if err != nil {
+		return nil, err
+	}
+	end := term + "\x00" // sorts after every [term, primary key]
+	if prefix {
+		end = term + "\uffff"
+	}
+	upper, err := jsArray.Call("of", end)
+	if err != nil {
+		return nil, err
+	}
+	return idb.NewKeyRangeBound(lower, upper, false, true)
+}
+
+// keyID returns a string uniquely identifying key, for matching primary keys across terms.
+func keyID(key safejs.Value) (string, error) {
+	switch key.Type() {
+	case safejs.TypeNumber:
+		n, err := key.Float()
+		return "n" + strconv.FormatFloat(n, 'g', -1, 64), err
+	case safejs.TypeString:
+		s, err := key.String()
+		return "s" + strconv.Quote(s), err
+	}
+	if t, err := idb.KeyTime(key); err == nil {
+		return "d" + strconv.FormatInt(t.UnixMilli(), 10), nil
+	}
+	isArray, err := key.InstanceOf(jsArray)
+	if err != nil {
+		return "", err
+	}
+	if !isArray {
+		b, err := idb.BinaryKeyBytes(key)
+		return "b" + base64.StdEncoding.EncodeToString(b), err
+	}
+	length, err := key.Length()
+	if err != nil {
+		return "", err
+	}
+	ids := make([]string, 0, length)
+	for i := 0; i < length; i++ {
+		element, err := key.Index(i)
+		if err != nil {
+			return "", err
+		}
+		id, err := keyID(element)
+		if err != nil {
+			return "", err
+		}
+		ids = append(ids, id)
+	}
+	return "a[" + strings.Join(ids, ",") + "]", nil
+}
@@ -0,0 +1,133 @@
+//go:build js && wasm
+// +build js,wasm
+
+package textindex
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/aperturerobotics/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+	terms := Tokenize("Hello, World! It's 2024 — café")
+	expect := []string{"hello", "world", "it", "s", "2024", "café"}
+	if !reflect.DeepEqual(terms, expect) {
+		t.Errorf("expected %v, got %v", expect, terms)
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	name := fmt.Sprintf("textindex-test-%s-%d", t.Name(), time.Now().UnixNano())
+	dbReq, err := idb.Global().Open(ctx, name, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		if _, err := db.CreateObjectStore("notes", idb.ObjectStoreOptions{}); err != nil {
+			return err
+		}
+		_, err := CreateIndexStore(db, "notes")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := dbReq.Await(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		req, err := idb.Global().DeleteDatabase(name)
+		if err == nil {
+			_ = req.Await(ctx)
+		}
+	})
+
+	index := New(db, "notes", "title", "body.text")
+	write := func(fn func(txn *idb.Transaction) error) {
+		t.Helper()
+		txn, err := db.Transaction(idb.TransactionReadWrite, "notes", IndexStoreName("notes"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := fn(txn); err != nil {
+			t.Fatal(err)
+		}
+		if err := txn.Await(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	note := func(title, text string) safejs.Value {
+		return safejs.Safe(js.ValueOf(map[string]interface{}{
+			"title": title,
+			"body":  map[string]interface{}{"text": text},
+		}))
+	}
+	search := func(query string, options SearchOptions) []int {
+		t.Helper()
+		hits, err := index.Search(ctx, query, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := []int{}
+		for _, hit := range hits {
+			key, err := hit.PrimaryKey.Int()
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	write(func(txn *idb.Transaction) error {
+		for key, value := range []safejs.Value{
+			note("Grocery list", "apples, bread, more apples"),
+			note("Apple pie", "bake the apples with bread crumbs"),
+			note("Meeting", "discuss the bread budget"),
+		} {
+			if err := index.Put(ctx, txn, safejs.Safe(js.ValueOf(key)), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	for _, tc := range []struct {
+		query   string
+		options SearchOptions
+		expect  []int
+	}{
+		{query: "bread", expect: []int{0, 1, 2}},
+		{query: "apples", expect: []int{0, 1}}, // record 0 has "apples" twice
+		{query: "APPLES bread", expect: []int{0, 1}},
+		{query: "appl", expect: []int{}},
+		{query: "bread appl", options: SearchOptions{Prefix: true}, expect: []int{0, 1}},
+		{query: "bread", options: SearchOptions{Limit: 1}, expect: []int{0}},
+		{query: "", expect: []int{}},
+	} {
+		if keys := search(tc.query, tc.options); !reflect.DeepEqual(keys, tc.expect) {
+			t.Errorf("search %q: expected %v, got %v", tc.query, tc.expect, keys)
+		}
+	}
+
+	write(func(txn *idb.Transaction) error {
+		if err := index.Put(ctx, txn, safejs.Safe(js.ValueOf(0)), note("Grocery list", "milk")); err != nil {
+			return err
+		}
+		return index.Delete(ctx, txn, safejs.Safe(js.ValueOf(2)))
+	})
+	if keys := search("bread", SearchOptions{}); !reflect.DeepEqual(keys, []int{1}) {
+		t.Errorf("expected only record 1 to contain bread after updates, got %v", keys)
+	}
+	if keys := search("milk", SearchOptions{}); !reflect.DeepEqual(keys, []int{0}) {
+		t.Errorf("expected record 0 to contain milk, got %v", keys)
+	}
+}